@@ -0,0 +1,19 @@
+ALTER TABLE wallet_operations
+    ADD COLUMN destination_wallet_id UUID REFERENCES wallets(id);
+
+ALTER TABLE wallet_operations
+    DROP CONSTRAINT wallet_operations_operation_type_check;
+
+ALTER TABLE wallet_operations
+    ADD CONSTRAINT wallet_operations_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER'));
+
+ALTER TABLE wallet_operations
+    ADD CONSTRAINT wallet_operations_transfer_destination_check
+    CHECK (
+        (operation_type = 'TRANSFER' AND destination_wallet_id IS NOT NULL AND destination_wallet_id <> wallet_id)
+        OR (operation_type <> 'TRANSFER' AND destination_wallet_id IS NULL)
+    );
+
+CREATE INDEX idx_wallet_operations_destination_wallet_id ON wallet_operations(destination_wallet_id)
+    WHERE destination_wallet_id IS NOT NULL;
@@ -0,0 +1,32 @@
+-- Double-entry ledger: every balance change is recorded as a set of postings
+-- whose debits and credits are equal. wallet_id NULL is the external account
+-- (money entering or leaving the platform through DEPOSIT / WITHDRAW).
+CREATE TABLE ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    operation_id UUID REFERENCES wallet_operations(id), -- NULL for opening balances
+    wallet_id UUID REFERENCES wallets(id),
+    direction VARCHAR(6) NOT NULL CHECK (direction IN ('DEBIT', 'CREDIT')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    balance_after BIGINT, -- running balance of wallet_id after this posting
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK ((wallet_id IS NULL) = (balance_after IS NULL))
+);
+
+CREATE INDEX idx_ledger_entries_wallet_id_id ON ledger_entries(wallet_id, id);
+CREATE INDEX idx_ledger_entries_operation_id ON ledger_entries(operation_id);
+
+-- Carry over balances that existed before the ledger was introduced
+INSERT INTO ledger_entries (operation_id, wallet_id, direction, amount, balance_after)
+SELECT NULL, NULL, 'DEBIT', balance, NULL FROM wallets WHERE balance > 0;
+
+INSERT INTO ledger_entries (operation_id, wallet_id, direction, amount, balance_after)
+SELECT NULL, id, 'CREDIT', balance, balance FROM wallets WHERE balance > 0;
+
+-- Wallet balances rebuilt from the ledger; must always match wallets.balance
+CREATE VIEW wallet_ledger_balances AS
+SELECT
+    wallet_id,
+    SUM(CASE direction WHEN 'CREDIT' THEN amount ELSE -amount END) AS balance
+FROM ledger_entries
+WHERE wallet_id IS NOT NULL
+GROUP BY wallet_id;
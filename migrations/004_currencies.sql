@@ -0,0 +1,28 @@
+-- Currency codes are ISO 4217; the minor-unit registry lives in wallet-service (internal/currency).
+-- Wallets created before multi-currency support are USD.
+ALTER TABLE wallets
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE wallet_operations
+    ADD COLUMN currency CHAR(3);
+
+UPDATE wallet_operations o
+SET currency = w.currency
+FROM wallets w
+WHERE o.wallet_id = w.id;
+
+ALTER TABLE wallet_operations
+    ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE ledger_entries
+    ADD COLUMN currency CHAR(3);
+
+UPDATE ledger_entries e
+SET currency = COALESCE(
+    (SELECT w.currency FROM wallets w WHERE w.id = e.wallet_id),
+    (SELECT o.currency FROM wallet_operations o WHERE o.id = e.operation_id),
+    'USD'
+);
+
+ALTER TABLE ledger_entries
+    ALTER COLUMN currency SET NOT NULL;
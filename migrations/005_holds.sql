@@ -0,0 +1,40 @@
+-- Authorization holds: HOLD reserves funds (held_amount) without changing the balance,
+-- CAPTURE turns all or part of a hold into a withdrawal, RELEASE frees it.
+-- Available balance = balance - held_amount.
+ALTER TABLE wallets
+    ADD COLUMN held_amount BIGINT NOT NULL DEFAULT 0 CHECK (held_amount >= 0);
+
+ALTER TABLE wallet_operations
+    ALTER COLUMN operation_type TYPE VARCHAR(16);
+
+ALTER TABLE wallet_operations
+    DROP CONSTRAINT wallet_operations_operation_type_check;
+
+ALTER TABLE wallet_operations
+    ADD CONSTRAINT wallet_operations_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER', 'HOLD', 'CAPTURE', 'RELEASE'));
+
+-- CAPTURE and RELEASE reference the HOLD operation they apply to
+ALTER TABLE wallet_operations
+    ADD COLUMN reference_operation_id UUID REFERENCES wallet_operations(id);
+
+-- Expiry of a HOLD operation
+ALTER TABLE wallet_operations
+    ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_wallet_operations_reference_operation_id ON wallet_operations(reference_operation_id)
+    WHERE reference_operation_id IS NOT NULL;
+
+CREATE TABLE holds (
+    operation_id UUID PRIMARY KEY REFERENCES wallet_operations(id),
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    remaining_amount BIGINT NOT NULL CHECK (remaining_amount >= 0 AND remaining_amount <= amount),
+    status VARCHAR(10) NOT NULL CHECK (status IN ('ACTIVE', 'CAPTURED', 'RELEASED', 'EXPIRED')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_holds_wallet_id_status ON holds(wallet_id, status);
+CREATE INDEX idx_holds_active_expires_at ON holds(expires_at) WHERE status = 'ACTIVE';
@@ -0,0 +1,16 @@
+-- Reversals: a REVERSAL refunds all or part of a processed operation and
+-- references it through reference_operation_id. reversed_amount on the original
+-- tracks how much of it has already been reversed.
+ALTER TABLE wallet_operations
+    DROP CONSTRAINT wallet_operations_operation_type_check;
+
+ALTER TABLE wallet_operations
+    ADD CONSTRAINT wallet_operations_operation_type_check
+    CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER', 'HOLD', 'CAPTURE', 'RELEASE', 'REVERSAL'));
+
+ALTER TABLE wallet_operations
+    ADD COLUMN reversed_amount BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE wallet_operations
+    ADD CONSTRAINT wallet_operations_reversed_amount_check
+    CHECK (reversed_amount >= 0 AND reversed_amount <= amount);
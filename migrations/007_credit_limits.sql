@@ -0,0 +1,4 @@
+-- Credit limit: how far below zero a wallet may go.
+-- A debit is allowed while balance - held_amount - amount >= -credit_limit.
+ALTER TABLE wallets
+    ADD COLUMN credit_limit BIGINT NOT NULL DEFAULT 0 CHECK (credit_limit >= 0);
@@ -0,0 +1,10 @@
+-- Velocity limits: caps on withdrawals, captures and outgoing transfers within
+-- a rolling day (24h) and month (30 days). NULL uses the global default
+-- (LIMIT_DAILY_WITHDRAW / LIMIT_MONTHLY_WITHDRAW), 0 disables the limit.
+ALTER TABLE wallets
+    ADD COLUMN daily_withdraw_limit BIGINT CHECK (daily_withdraw_limit >= 0),
+    ADD COLUMN monthly_withdraw_limit BIGINT CHECK (monthly_withdraw_limit >= 0);
+
+-- Usage of a limit is the sum of the wallet's outflows processed within the window
+CREATE INDEX idx_wallet_operations_wallet_id_processed_at ON wallet_operations(wallet_id, processed_at)
+    WHERE status = 'PROCESSED';
@@ -0,0 +1,4 @@
+-- Fee charged by the worker on top of the operation amount and credited
+-- to the house wallet of the operation currency
+ALTER TABLE wallet_operations
+    ADD COLUMN fee BIGINT NOT NULL DEFAULT 0 CHECK (fee >= 0);
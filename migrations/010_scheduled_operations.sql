@@ -0,0 +1,14 @@
+-- Scheduled operations are stored as SCHEDULED with execute_at and published
+-- to Kafka by the scheduler when due (becoming PENDING), or CANCELLED before that.
+ALTER TABLE wallet_operations
+    DROP CONSTRAINT wallet_operations_status_check;
+
+ALTER TABLE wallet_operations
+    ADD CONSTRAINT wallet_operations_status_check
+    CHECK (status IN ('SCHEDULED', 'PENDING', 'PROCESSED', 'FAILED', 'CANCELLED'));
+
+ALTER TABLE wallet_operations
+    ADD COLUMN execute_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_wallet_operations_scheduled_execute_at ON wallet_operations(execute_at)
+    WHERE status = 'SCHEDULED';
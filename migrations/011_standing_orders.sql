@@ -0,0 +1,30 @@
+-- Standing orders: a DEPOSIT, WITHDRAW or TRANSFER repeated by a recurrence rule.
+-- The scheduler creates a regular operation for every occurrence, linked back
+-- through wallet_operations.standing_order_id, and moves next_run_at forward.
+CREATE TABLE standing_orders (
+    id UUID PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    destination_wallet_id UUID REFERENCES wallets(id),
+    operation_type VARCHAR(16) NOT NULL CHECK (operation_type IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    schedule VARCHAR(100) NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('ACTIVE', 'PAUSED', 'COMPLETED', 'DELETED')),
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_at TIMESTAMP WITH TIME ZONE,
+    max_occurrences INTEGER CHECK (max_occurrences > 0),
+    occurrences INTEGER NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (destination_wallet_id IS NOT NULL OR operation_type <> 'TRANSFER')
+);
+
+CREATE INDEX idx_standing_orders_wallet_id ON standing_orders(wallet_id);
+CREATE INDEX idx_standing_orders_active_next_run_at ON standing_orders(next_run_at) WHERE status = 'ACTIVE';
+
+ALTER TABLE wallet_operations
+    ADD COLUMN standing_order_id UUID REFERENCES standing_orders(id);
+
+CREATE INDEX idx_wallet_operations_standing_order_id ON wallet_operations(standing_order_id, created_at)
+    WHERE standing_order_id IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Wallet lifecycle: a FROZEN wallet rejects new operations until it is unfrozen,
+-- a CLOSED wallet is final. Operations already queued for a non-active wallet
+-- are failed by the worker.
+ALTER TABLE wallets
+    ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED')),
+    ADD COLUMN status_reason TEXT,
+    ADD COLUMN status_changed_at TIMESTAMP WITH TIME ZONE;
+
+-- A closed wallet holds no funds: its balance is swept to another wallet before closing
+ALTER TABLE wallets
+    ADD CONSTRAINT wallets_closed_empty_check
+    CHECK (status <> 'CLOSED' OR (balance = 0 AND held_amount = 0));
@@ -0,0 +1,13 @@
+-- Wallet ownership: the owner in the calling system, the client's own reference
+-- for the wallet and free-form labels. NULLs are distinct in the unique index,
+-- so only wallets created with both an owner and an external reference are deduplicated.
+ALTER TABLE wallets
+    ADD COLUMN owner_id VARCHAR(100),
+    ADD COLUMN external_ref VARCHAR(100),
+    ADD COLUMN display_name VARCHAR(200),
+    ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+
+CREATE UNIQUE INDEX idx_wallets_owner_external_ref ON wallets(owner_id, external_ref);
+CREATE INDEX idx_wallets_owner_created ON wallets(owner_id, created_at, id);
+CREATE INDEX idx_wallets_created ON wallets(created_at, id);
+CREATE INDEX idx_wallets_labels ON wallets USING GIN (labels);
@@ -0,0 +1,9 @@
+-- Client-supplied idempotency keys: a retried request with the same key returns
+-- the operation created by the first one. request_hash detects a key reused for
+-- a different request. Keys are cleared once the retention period has passed.
+ALTER TABLE wallet_operations
+    ADD COLUMN idempotency_key VARCHAR(255),
+    ADD COLUMN request_hash CHAR(64);
+
+CREATE UNIQUE INDEX idx_wallet_operations_idempotency_key ON wallet_operations(wallet_id, idempotency_key);
+CREATE INDEX idx_wallet_operations_idempotency_created ON wallet_operations(created_at) WHERE idempotency_key IS NOT NULL;
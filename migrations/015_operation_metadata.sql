@@ -0,0 +1,9 @@
+-- Operation metadata supplied by the client: a free-text description, a reference
+-- to the client's own record (e.g. an order ID) and a JSON object whose size is
+-- bounded by the API. Operations are searched by external reference.
+ALTER TABLE wallet_operations
+    ADD COLUMN description VARCHAR(500),
+    ADD COLUMN external_ref VARCHAR(100),
+    ADD COLUMN metadata JSONB CHECK (jsonb_typeof(metadata) = 'object');
+
+CREATE INDEX idx_wallet_operations_external_ref ON wallet_operations(external_ref, created_at DESC, id DESC) WHERE external_ref IS NOT NULL;
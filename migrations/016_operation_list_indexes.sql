@@ -0,0 +1,8 @@
+-- Keyset pagination of the operations of a wallet, newest first: outgoing operations
+-- by wallet_id and incoming transfers by destination_wallet_id. The destination index
+-- supersedes the single-column one from 002_transfers.
+CREATE INDEX idx_wallet_operations_wallet_created ON wallet_operations(wallet_id, created_at DESC, id DESC);
+CREATE INDEX idx_wallet_operations_destination_created ON wallet_operations(destination_wallet_id, created_at DESC, id DESC)
+    WHERE destination_wallet_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_wallet_operations_destination_wallet_id;
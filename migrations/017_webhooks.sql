@@ -0,0 +1,37 @@
+-- Webhook subscriptions: operations settling and balance changes committed by the
+-- worker are POSTed to url, signed with the secret (kept as is, as it signs every
+-- delivery). event_types lists the event types to deliver, wallet_id NULL
+-- subscribes to every wallet.
+CREATE TABLE webhook_subscriptions (
+    id UUID PRIMARY KEY,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL CHECK (cardinality(event_types) > 0),
+    wallet_id UUID REFERENCES wallets(id),
+    secret VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_subscriptions_wallet_id ON webhook_subscriptions(wallet_id);
+
+-- Webhook deliveries: written by the worker in the transaction that commits the change,
+-- one per matching subscription, and sent by the wallet-service scheduler. A failed
+-- delivery is retried with exponential backoff; after the last attempt it becomes DEAD
+-- (the dead-letter list) until an admin replays it.
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    wallet_id UUID NOT NULL,
+    data JSONB NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_status_code INTEGER,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_webhook_deliveries_pending_next_attempt_at ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX idx_webhook_deliveries_dead ON webhook_deliveries(subscription_id, created_at DESC, id DESC) WHERE status = 'DEAD';
+CREATE INDEX idx_webhook_deliveries_dead_created_at ON webhook_deliveries(created_at DESC, id DESC) WHERE status = 'DEAD';
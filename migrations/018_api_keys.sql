@@ -0,0 +1,27 @@
+-- API keys: only the SHA-256 of a key is stored, the key itself is returned once
+-- when it is created or rotated. scopes grant access to groups of routes; a key
+-- with wallet_ids or owner_ids only reaches those wallets, or the wallets of those
+-- owners. A rotated key keeps accepting the previous key until
+-- previous_key_expires_at, so clients can switch without downtime.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    previous_key_hash CHAR(64),
+    previous_key_expires_at TIMESTAMP WITH TIME ZONE,
+    scopes TEXT[] NOT NULL CHECK (cardinality(scopes) > 0),
+    wallet_ids UUID[] NOT NULL DEFAULT '{}',
+    owner_ids TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX idx_api_keys_previous_key_hash ON api_keys(previous_key_hash) WHERE previous_key_hash IS NOT NULL;
+
+-- The caller that created an operation or standing order: the ID of its API key.
+-- Operations fired by a standing order inherit the creator of the order.
+ALTER TABLE wallet_operations ADD COLUMN created_by VARCHAR(100);
+ALTER TABLE standing_orders ADD COLUMN created_by VARCHAR(100);
@@ -0,0 +1,9 @@
+-- claimed_at is set when the scheduler claims a due operation and cleared once the
+-- operation is published to Kafka. A PENDING operation still claimed after the lease
+-- was never published, e.g. because the replica crashed in between, and is claimed
+-- again.
+ALTER TABLE wallet_operations
+    ADD COLUMN claimed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_wallet_operations_pending_claimed_at ON wallet_operations(claimed_at)
+    WHERE status = 'PENDING' AND claimed_at IS NOT NULL;
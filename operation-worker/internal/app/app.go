@@ -7,6 +7,8 @@ import (
 	"operation-worker/internal/cache"
 	"operation-worker/internal/config"
 	"operation-worker/internal/database"
+	"operation-worker/internal/fees"
+	"operation-worker/internal/limits"
 	"operation-worker/internal/repositories/postgresrepo"
 	"operation-worker/internal/repositories/redisrepo"
 	"operation-worker/internal/services"
@@ -20,6 +22,7 @@ type App struct {
 	cfg              *config.Config
 	walletService    *services.WalletService
 	partitionManager *worker.PartitionManager
+	holdExpirer      *worker.HoldExpirer
 }
 
 func New() (*App, error) {
@@ -44,12 +47,21 @@ func New() (*App, error) {
 	postgresRepo := postgresrepo.NewdWalletRepo(db)
 	redisRepo := redisrepo.NewWalletRepository(redis)
 
+	// Initialize fee engine
+	feeEngine, err := fees.NewEngine(a.cfg.Fees)
+	if err != nil {
+		return nil, fmt.Errorf("fee configuration error: %w", err)
+	}
+
 	// Initialize services
-	a.walletService = services.NewWalletService(postgresRepo, redisRepo)
+	a.walletService = services.NewWalletService(postgresRepo, redisRepo, limits.NewPolicy(a.cfg.Limits), feeEngine)
 
 	// Partition Manager
 	a.partitionManager = worker.NewPartitionManager(a.cfg, a.walletService)
 
+	// Hold Expirer
+	a.holdExpirer = worker.NewHoldExpirer(a.cfg, a.walletService)
+
 	return a, nil
 }
 
@@ -66,5 +78,7 @@ func (a *App) Run() {
 		cancel()
 	}()
 
+	go a.holdExpirer.Start(ctx)
+
 	a.partitionManager.Start(ctx)
 }
@@ -14,6 +14,8 @@ type Config struct {
 	Kafka    KafkaConfig
 	Redis    RedisConfig
 	Worker   WorkerConfig
+	Limits   LimitsConfig
+	Fees     FeesConfig
 }
 
 type PostgresConfig struct {
@@ -38,6 +40,20 @@ type RedisConfig struct {
 
 type WorkerConfig struct {
 	ProcessingInterval time.Duration
+	HoldExpiryInterval time.Duration
+}
+
+// LimitsConfig holds the global default withdraw limits in minor units, 0 means no limit
+type LimitsConfig struct {
+	DailyWithdraw   int64
+	MonthlyWithdraw int64
+}
+
+// FeesConfig holds the fee schedules as JSON keyed by operation type and the
+// house wallets collecting fees as comma-separated CURRENCY=walletId pairs
+type FeesConfig struct {
+	Schedules string
+	Wallets   string
 }
 
 func New() *Config {
@@ -72,6 +88,24 @@ func New() *Config {
 				processingInterval, _ := strconv.Atoi(pi)
 				return time.Duration(processingInterval) * time.Millisecond
 			}(os.Getenv("WORKER_PROCESSING_INTERVAL")),
+			HoldExpiryInterval: func(hi string) time.Duration {
+				holdExpiryInterval, _ := strconv.Atoi(hi)
+				return time.Duration(holdExpiryInterval) * time.Millisecond
+			}(os.Getenv("WORKER_HOLD_EXPIRY_INTERVAL")),
+		},
+		Limits: LimitsConfig{
+			DailyWithdraw: func(dw string) int64 {
+				dailyWithdraw, _ := strconv.ParseInt(dw, 10, 64)
+				return dailyWithdraw
+			}(os.Getenv("LIMIT_DAILY_WITHDRAW")),
+			MonthlyWithdraw: func(mw string) int64 {
+				monthlyWithdraw, _ := strconv.ParseInt(mw, 10, 64)
+				return monthlyWithdraw
+			}(os.Getenv("LIMIT_MONTHLY_WITHDRAW")),
+		},
+		Fees: FeesConfig{
+			Schedules: os.Getenv("FEE_SCHEDULES"),
+			Wallets:   os.Getenv("FEE_WALLETS"),
 		},
 	}
 }
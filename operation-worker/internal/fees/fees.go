@@ -0,0 +1,120 @@
+package fees
+
+import (
+	"encoding/json"
+	"fmt"
+	"operation-worker/internal/config"
+	"operation-worker/internal/models"
+	"strings"
+)
+
+// chargeableTypes are the operation types a fee can be charged on.
+// HOLD and RELEASE move no money, a REVERSAL never charges a fee.
+var chargeableTypes = map[string]bool{
+	models.OperationTypeDeposit:  true,
+	models.OperationTypeWithdraw: true,
+	models.OperationTypeTransfer: true,
+	models.OperationTypeCapture:  true,
+}
+
+// Schedule describes how the fee of an operation is computed from its amount.
+// Percentages are in basis points (1% = 100). With tiers, the first tier whose
+// UpTo covers the amount replaces Flat and PercentBps. The result is clamped to
+// [Min, Max]; Max of 0 means no maximum.
+type Schedule struct {
+	Flat       int64  `json:"flat"`
+	PercentBps int64  `json:"percentBps"`
+	Tiers      []Tier `json:"tiers"`
+	Min        int64  `json:"min"`
+	Max        int64  `json:"max"`
+}
+
+// Tier is a fee band for amounts up to UpTo inclusive; UpTo of 0 means no upper bound
+type Tier struct {
+	UpTo       int64 `json:"upTo"`
+	Flat       int64 `json:"flat"`
+	PercentBps int64 `json:"percentBps"`
+}
+
+// Fee computes the fee for an amount in minor units, rounding percentages half up
+func (s Schedule) Fee(amount int64) int64 {
+	flat, percentBps := s.Flat, s.PercentBps
+	for _, tier := range s.Tiers {
+		if tier.UpTo == 0 || amount <= tier.UpTo {
+			flat, percentBps = tier.Flat, tier.PercentBps
+			break
+		}
+	}
+
+	fee := flat + (amount*percentBps+5000)/10000
+	if fee < s.Min {
+		fee = s.Min
+	}
+	if s.Max > 0 && fee > s.Max {
+		fee = s.Max
+	}
+
+	return fee
+}
+
+// Engine holds the fee schedules by operation type and the house wallets
+// that collect fees, one per currency
+type Engine struct {
+	schedules map[string]Schedule
+	wallets   map[string]string
+}
+
+// NewEngine parses the fee configuration:
+// schedules as JSON keyed by operation type, e.g. {"WITHDRAW": {"flat": 100, "max": 5000}},
+// wallets as comma-separated CURRENCY=walletId pairs.
+func NewEngine(cfg config.FeesConfig) (*Engine, error) {
+	e := &Engine{
+		schedules: make(map[string]Schedule),
+		wallets:   make(map[string]string),
+	}
+
+	if cfg.Schedules != "" {
+		if err := json.Unmarshal([]byte(cfg.Schedules), &e.schedules); err != nil {
+			return nil, fmt.Errorf("invalid fee schedules: %w", err)
+		}
+	}
+	for operationType := range e.schedules {
+		if !chargeableTypes[operationType] {
+			return nil, fmt.Errorf("invalid fee schedules: fees cannot be charged on %s", operationType)
+		}
+	}
+
+	for _, pair := range strings.Split(cfg.Wallets, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		code, walletID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || code == "" || walletID == "" {
+			return nil, fmt.Errorf("invalid fee wallet %q: expected CURRENCY=walletId", pair)
+		}
+		e.wallets[code] = walletID
+	}
+
+	return e, nil
+}
+
+// Fee returns the fee for an operation, 0 if its type has no schedule
+func (e *Engine) Fee(operationType string, amount int64) int64 {
+	if e == nil {
+		return 0
+	}
+	schedule, ok := e.schedules[operationType]
+	if !ok {
+		return 0
+	}
+	return schedule.Fee(amount)
+}
+
+// Wallet returns the house wallet that collects fees in the given currency
+func (e *Engine) Wallet(currency string) (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	walletID, ok := e.wallets[currency]
+	return walletID, ok
+}
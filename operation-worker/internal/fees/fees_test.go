@@ -0,0 +1,76 @@
+package fees
+
+import (
+	"testing"
+
+	"operation-worker/internal/config"
+)
+
+func TestSchedule_Fee(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		amount   int64
+		want     int64
+	}{
+		{"flat", Schedule{Flat: 100}, 12345, 100},
+		{"percentage rounds half up", Schedule{PercentBps: 150}, 1010, 15},
+		{"flat plus percentage", Schedule{Flat: 30, PercentBps: 290}, 10000, 320},
+		{"minimum", Schedule{PercentBps: 100, Min: 50}, 1000, 50},
+		{"maximum", Schedule{PercentBps: 100, Max: 500}, 100000, 500},
+		{
+			name: "first matching tier",
+			schedule: Schedule{Tiers: []Tier{
+				{UpTo: 10000, Flat: 50},
+				{UpTo: 100000, PercentBps: 100},
+				{PercentBps: 50},
+			}},
+			amount: 50000,
+			want:   500,
+		},
+		{
+			name: "open-ended last tier",
+			schedule: Schedule{Max: 1000, Tiers: []Tier{
+				{UpTo: 10000, Flat: 50},
+				{PercentBps: 50},
+			}},
+			amount: 1000000,
+			want:   1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Fee(tt.amount); got != tt.want {
+				t.Fatalf("fee of %d: got %d, want %d", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEngine(t *testing.T) {
+	e, err := NewEngine(config.FeesConfig{
+		Schedules: `{"WITHDRAW": {"flat": 100}}`,
+		Wallets:   "USD=11111111-1111-4111-8111-111111111111, EUR=22222222-2222-4222-8222-222222222222",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fee := e.Fee("WITHDRAW", 5000); fee != 100 {
+		t.Fatalf("withdraw fee: got %d, want 100", fee)
+	}
+	if fee := e.Fee("DEPOSIT", 5000); fee != 0 {
+		t.Fatalf("deposit without schedule: got %d, want 0", fee)
+	}
+	if walletID, ok := e.Wallet("EUR"); !ok || walletID != "22222222-2222-4222-8222-222222222222" {
+		t.Fatalf("EUR wallet: got %q, %v", walletID, ok)
+	}
+
+	if _, err := NewEngine(config.FeesConfig{Schedules: `{"HOLD": {"flat": 1}}`}); err == nil {
+		t.Fatalf("expected error for a schedule on HOLD")
+	}
+	if _, err := NewEngine(config.FeesConfig{Wallets: "USD"}); err == nil {
+		t.Fatalf("expected error for a malformed fee wallet")
+	}
+}
@@ -0,0 +1,98 @@
+package limits
+
+import (
+	"operation-worker/internal/config"
+	"operation-worker/internal/models"
+	"time"
+)
+
+// Velocity limits cap the outflows of a wallet within a rolling window.
+// Outflows are processed withdrawals, captures and outgoing transfers.
+const (
+	NameDailyWithdraw   = "daily withdraw"
+	NameMonthlyWithdraw = "monthly withdraw"
+
+	DailyWindow   = 24 * time.Hour
+	MonthlyWindow = 30 * 24 * time.Hour
+)
+
+// countedTypes are the operation types that count against withdraw limits
+var countedTypes = []string{
+	models.OperationTypeWithdraw,
+	models.OperationTypeTransfer,
+	models.OperationTypeCapture,
+}
+
+// Limit is a cap on outflows within a rolling window and the amount already used
+type Limit struct {
+	Name   string
+	Window time.Duration
+	Max    int64
+	Used   int64
+}
+
+// Policy resolves the limits of a wallet from its own settings and the global defaults
+type Policy struct {
+	defaults config.LimitsConfig
+}
+
+func NewPolicy(defaults config.LimitsConfig) *Policy {
+	return &Policy{defaults: defaults}
+}
+
+// For returns the limits that apply to the wallet. A wallet setting overrides
+// the global default; a limit of 0 means no limit and is omitted.
+func (p *Policy) For(wallet models.Wallet) []Limit {
+	candidates := []Limit{
+		{Name: NameDailyWithdraw, Window: DailyWindow, Max: resolve(wallet.DailyWithdrawLimit, p.defaults.DailyWithdraw)},
+		{Name: NameMonthlyWithdraw, Window: MonthlyWindow, Max: resolve(wallet.MonthlyWithdrawLimit, p.defaults.MonthlyWithdraw)},
+	}
+
+	limits := make([]Limit, 0, len(candidates))
+	for _, limit := range candidates {
+		if limit.Max > 0 {
+			limits = append(limits, limit)
+		}
+	}
+
+	return limits
+}
+
+// CountedTypes returns the operation types that count against withdraw limits
+func CountedTypes() []string {
+	return countedTypes
+}
+
+// Counts reports whether an operation of the given type counts against withdraw limits
+func Counts(operationType string) bool {
+	for _, t := range countedTypes {
+		if t == operationType {
+			return true
+		}
+	}
+	return false
+}
+
+// Exceeded returns the first limit that the amount would exceed, or nil
+func Exceeded(limits []Limit, amount int64) *Limit {
+	for i := range limits {
+		if limits[i].Used+amount > limits[i].Max {
+			return &limits[i]
+		}
+	}
+	return nil
+}
+
+// Record adds a processed outflow to the usage of every limit
+func Record(limits []Limit, amount int64) {
+	for i := range limits {
+		limits[i].Used += amount
+	}
+}
+
+func resolve(walletLimit *int64, defaultLimit int64) int64 {
+	if walletLimit != nil {
+		return *walletLimit
+	}
+	return defaultLimit
+}
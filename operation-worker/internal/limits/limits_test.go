@@ -0,0 +1,51 @@
+package limits
+
+import (
+	"testing"
+
+	"operation-worker/internal/config"
+	"operation-worker/internal/models"
+)
+
+func TestPolicy_For(t *testing.T) {
+	p := NewPolicy(config.LimitsConfig{DailyWithdraw: 1000, MonthlyWithdraw: 5000})
+
+	limits := p.For(models.Wallet{ID: "w-1"})
+	if len(limits) != 2 || limits[0].Max != 1000 || limits[1].Max != 5000 {
+		t.Fatalf("defaults: got %+v", limits)
+	}
+
+	// Wallet settings override the defaults, 0 disables a limit
+	daily, monthly := int64(300), int64(0)
+	limits = p.For(models.Wallet{ID: "w-1", DailyWithdrawLimit: &daily, MonthlyWithdrawLimit: &monthly})
+	if len(limits) != 1 || limits[0].Name != NameDailyWithdraw || limits[0].Max != 300 {
+		t.Fatalf("overrides: got %+v", limits)
+	}
+}
+
+func TestExceeded(t *testing.T) {
+	limits := []Limit{
+		{Name: NameDailyWithdraw, Window: DailyWindow, Max: 1000, Used: 600},
+		{Name: NameMonthlyWithdraw, Window: MonthlyWindow, Max: 1500, Used: 1300},
+	}
+
+	if l := Exceeded(limits, 300); l == nil || l.Name != NameMonthlyWithdraw {
+		t.Fatalf("expected monthly limit to be exceeded, got %+v", l)
+	}
+	if l := Exceeded(limits, 500); l == nil || l.Name != NameDailyWithdraw {
+		t.Fatalf("expected daily limit to be exceeded first, got %+v", l)
+	}
+
+	limits[1].Max = 2000
+	if l := Exceeded(limits, 400); l != nil {
+		t.Fatalf("expected no limit to be exceeded, got %+v", l)
+	}
+
+	Record(limits, 400)
+	if limits[0].Used != 1000 || limits[1].Used != 1700 {
+		t.Fatalf("record: got %+v", limits)
+	}
+	if l := Exceeded(limits, 1); l == nil || l.Name != NameDailyWithdraw {
+		t.Fatalf("expected daily limit to be exhausted, got %+v", l)
+	}
+}
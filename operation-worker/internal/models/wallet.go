@@ -1,31 +1,128 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Database model
 type Wallet struct {
-	ID        string    `db:"id"`
-	Balance   int64     `db:"balance"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID          string `db:"id"`
+	Balance     int64  `db:"balance"`
+	HeldAmount  int64  `db:"held_amount"`  // sum of active holds
+	CreditLimit int64  `db:"credit_limit"` // how far below zero the wallet may go
+	// Withdraw limits of the wallet, nil means the global default
+	DailyWithdrawLimit   *int64    `db:"daily_withdraw_limit"`
+	MonthlyWithdrawLimit *int64    `db:"monthly_withdraw_limit"`
+	Currency             string    `db:"currency"`
+	Status               string    `db:"status"` // ACTIVE, FROZEN, CLOSED
+	CreatedAt            time.Time `db:"created_at"`
+	UpdatedAt            time.Time `db:"updated_at"`
+}
+
+// AvailableBalance is the part of the balance not reserved by holds
+func (w Wallet) AvailableBalance() int64 {
+	return w.Balance - w.HeldAmount
+}
+
+// Headroom is the amount that can still be debited or held:
+// the available balance plus the credit limit
+func (w Wallet) Headroom() int64 {
+	return w.AvailableBalance() + w.CreditLimit
 }
 
 type WalletOperation struct {
-	ID            string     `db:"id"`
-	WalletID      string     `db:"wallet_id"`
-	OperationType string     `db:"operation_type"`
-	Amount        int64      `db:"amount"`
-	Status        string     `db:"status"` // PENDING, PROCESSED, FAILED
-	CreatedAt     time.Time  `db:"created_at"`
-	ProcessedAt   *time.Time `db:"processed_at"`
-	Error         *string    `db:"error"`
+	ID                   string     `db:"id"`
+	WalletID             string     `db:"wallet_id"`
+	DestinationWalletID  *string    `db:"destination_wallet_id"`  // only for TRANSFER
+	ReferenceOperationID *string    `db:"reference_operation_id"` // HOLD referenced by CAPTURE / RELEASE, operation undone by REVERSAL
+	OperationType        string     `db:"operation_type"`
+	Amount               int64      `db:"amount"`
+	ReversedAmount       int64      `db:"reversed_amount"` // part of the amount already undone by reversals
+	Currency             string     `db:"currency"`
+	Fee                  int64      `db:"fee"`        // charged on top of the amount and credited to the fee wallet
+	Status               string     `db:"status"`     // PENDING, PROCESSED, FAILED
+	ExpiresAt            *time.Time `db:"expires_at"` // only for HOLD
+	CreatedAt            time.Time  `db:"created_at"`
+	ProcessedAt          *time.Time `db:"processed_at"`
+	Error                *string    `db:"error"`
+}
+
+// Hold is the funds reserved by a processed HOLD operation
+type Hold struct {
+	OperationID     string    `db:"operation_id"`
+	WalletID        string    `db:"wallet_id"`
+	Amount          int64     `db:"amount"`
+	RemainingAmount int64     `db:"remaining_amount"`
+	Status          string    `db:"status"` // ACTIVE, CAPTURED, RELEASED, EXPIRED
+	ExpiresAt       time.Time `db:"expires_at"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
+}
+
+// LedgerEntry is a single debit or credit posting; WalletID nil is the external account
+type LedgerEntry struct {
+	ID           int64     `db:"id"`
+	OperationID  string    `db:"operation_id"`
+	WalletID     *string   `db:"wallet_id"`
+	Direction    string    `db:"direction"` // DEBIT, CREDIT
+	Amount       int64     `db:"amount"`
+	Currency     string    `db:"currency"`
+	BalanceAfter *int64    `db:"balance_after"`
+	CreatedAt    time.Time `db:"created_at"`
 }
 
 type KafkaMessage struct {
-	OperationID   string `json:"operation_id"`
-	WalletID      string `json:"wallet_id"`
-	OperationType string `json:"operation_type"`
-	Amount        int64  `json:"amount"`
+	OperationID          string     `json:"operation_id"`
+	WalletID             string     `json:"wallet_id"`
+	DestinationWalletID  string     `json:"destination_wallet_id,omitempty"`
+	ReferenceOperationID string     `json:"reference_operation_id,omitempty"`
+	OperationType        string     `json:"operation_type"`
+	Amount               int64      `json:"amount"`
+	Currency             string     `json:"currency"`
+	ExpiresAt            *time.Time `json:"expires_at,omitempty"`
+	// CloseWallet отмечает перевод, которым выводится баланс закрываемого кошелька:
+	// он списывается с замороженного кошелька без комиссии и лимитов, а после
+	// проведения кошелек закрывается
+	CloseWallet bool `json:"close_wallet,omitempty"`
+	// Client metadata, carried for consumers of the topic; the worker does not use it
+	Description string          `json:"description,omitempty"`
+	ExternalRef string          `json:"external_ref,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+}
+
+// WalletEvent is a committed change of a wallet, streamed to API clients
+type WalletEvent struct {
+	Type     string      `json:"type"` // operation, balance
+	WalletID string      `json:"walletId"`
+	Data     interface{} `json:"data"` // OperationEvent or BalanceEvent
+}
+
+// OperationEvent is the new status of an operation of the wallet
+type OperationEvent struct {
+	OperationID   string     `json:"operationId"`
+	OperationType string     `json:"operationType"`
+	Status        string     `json:"status"`
+	Amount        int64      `json:"amount"`
+	Fee           int64      `json:"fee"`
+	Currency      string     `json:"currency"`
+	Error         *string    `json:"error,omitempty"`
+	ProcessedAt   *time.Time `json:"processedAt,omitempty"`
+}
+
+// WebhookEvent is a wallet event delivered to the webhook subscriptions of its type
+type WebhookEvent struct {
+	Type     string // operation.processed, operation.failed, balance.updated
+	WalletID string
+	Data     interface{}
+}
+
+// BalanceEvent is the balance of the wallet after a commit
+type BalanceEvent struct {
+	Balance          int64  `json:"balance"`
+	HeldAmount       int64  `json:"heldAmount"`
+	AvailableBalance int64  `json:"availableBalance"`
+	Currency         string `json:"currency"`
 }
 
 // Status constants
@@ -35,6 +132,19 @@ const (
 	OperationStatusFailed    = "FAILED"
 )
 
+// Wallet event type constants
+const (
+	EventTypeOperation = "operation"
+	EventTypeBalance   = "balance"
+)
+
+// Webhook event type constants
+const (
+	WebhookEventOperationProcessed = "operation.processed"
+	WebhookEventOperationFailed    = "operation.failed"
+	WebhookEventBalanceUpdated     = "balance.updated"
+)
+
 // Message constants
 const (
 	MessageOperationQueued = "Operation queued for processing"
@@ -45,4 +155,30 @@ const (
 const (
 	OperationTypeDeposit  = "DEPOSIT"
 	OperationTypeWithdraw = "WITHDRAW"
+	OperationTypeTransfer = "TRANSFER"
+	OperationTypeHold     = "HOLD"
+	OperationTypeCapture  = "CAPTURE"
+	OperationTypeRelease  = "RELEASE"
+	OperationTypeReversal = "REVERSAL"
+)
+
+// Wallet status constants
+const (
+	WalletStatusActive = "ACTIVE"
+	WalletStatusFrozen = "FROZEN"
+	WalletStatusClosed = "CLOSED"
+)
+
+// Hold status constants
+const (
+	HoldStatusActive   = "ACTIVE"
+	HoldStatusCaptured = "CAPTURED"
+	HoldStatusReleased = "RELEASED"
+	HoldStatusExpired  = "EXPIRED"
+)
+
+// Ledger entry direction constants
+const (
+	EntryDirectionDebit  = "DEBIT"
+	EntryDirectionCredit = "CREDIT"
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -26,3 +27,23 @@ func (r *WalletRepo) BeginTx(ctx context.Context) (*TxWalletRepo, error) {
 	}
 	return NewTxWalletRepo(tx), nil
 }
+
+// GetWalletsWithExpiredHolds returns, in ID order, up to limit wallets after afterWalletID
+// (from the first one when empty) that have active holds expired before now
+func (r *WalletRepo) GetWalletsWithExpiredHolds(ctx context.Context, now time.Time, afterWalletID string, limit int) ([]string, error) {
+	query := `
+		SELECT DISTINCT wallet_id
+		FROM holds
+		WHERE status = 'ACTIVE' AND expires_at <= $1
+			AND wallet_id > COALESCE(NULLIF($2, ''), '00000000-0000-0000-0000-000000000000')::uuid
+		ORDER BY wallet_id
+		LIMIT $3
+	`
+
+	var walletIDs []string
+	if err := r.db.SelectContext(ctx, &walletIDs, query, now, afterWalletID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get wallets with expired holds: %w", err)
+	}
+
+	return walletIDs, nil
+}
@@ -2,11 +2,11 @@ package postgresrepo
 
 import (
 	"context"
-	"database/sql"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"operation-worker/internal/models"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -27,22 +27,38 @@ func (r *TxWalletRepo) Rollback() error {
 	return r.tx.Rollback()
 }
 
-func (r *TxWalletRepo) LockWalletForUpdate(ctx context.Context, walletID string) (*models.Wallet, error) {
-	var wallet models.Wallet
-	query := `SELECT id, balance FROM wallets WHERE id = $1 FOR UPDATE`
-	err := r.tx.GetContext(ctx, &wallet, query, walletID)
+// LockWalletsForUpdate locks the given wallets in ascending ID order so that
+// concurrent transactions touching overlapping sets of wallets cannot deadlock.
+// Wallets that do not exist are simply absent from the result.
+func (r *TxWalletRepo) LockWalletsForUpdate(ctx context.Context, walletIDs []string) ([]models.Wallet, error) {
+	if len(walletIDs) == 0 {
+		return []models.Wallet{}, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT id, balance, held_amount, credit_limit, daily_withdraw_limit, monthly_withdraw_limit, currency, status
+		FROM wallets
+		WHERE id IN (?)
+		ORDER BY id
+		FOR UPDATE
+	`, walletIDs)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("wallet not found: %s", walletID)
-		}
-		return nil, fmt.Errorf("failed to lock wallet: %w", err)
+		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
-	return &wallet, nil
+
+	query = r.tx.Rebind(query)
+	var wallets []models.Wallet
+	if err := r.tx.SelectContext(ctx, &wallets, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to lock wallets: %w", err)
+	}
+
+	return wallets, nil
 }
 
-func (r *TxWalletRepo) UpdateBalance(ctx context.Context, walletID string, balance int64) error {
-	query := `UPDATE wallets SET balance = $1, updated_at = NOW() WHERE id = $2`
-	result, err := r.tx.ExecContext(ctx, query, balance, walletID)
+// UpdateBalance writes the balance and the held amount of a locked wallet
+func (r *TxWalletRepo) UpdateBalance(ctx context.Context, wallet models.Wallet) error {
+	query := `UPDATE wallets SET balance = $1, held_amount = $2, updated_at = NOW() WHERE id = $3`
+	result, err := r.tx.ExecContext(ctx, query, wallet.Balance, wallet.HeldAmount, wallet.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update balance: %w", err)
 	}
@@ -52,7 +68,28 @@ func (r *TxWalletRepo) UpdateBalance(ctx context.Context, walletID string, balan
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 	if rows == 0 {
-		return fmt.Errorf("wallet not found: %s", walletID)
+		return fmt.Errorf("wallet not found: %s", wallet.ID)
+	}
+
+	return nil
+}
+
+// CloseWallets closes frozen wallets whose balance has been swept out
+func (r *TxWalletRepo) CloseWallets(ctx context.Context, walletIDs []string) error {
+	if len(walletIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(`
+		UPDATE wallets SET status = 'CLOSED', status_changed_at = NOW(), updated_at = NOW()
+		WHERE id IN (?) AND status = 'FROZEN'
+	`, walletIDs)
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err := r.tx.ExecContext(ctx, r.tx.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to close wallets: %w", err)
 	}
 
 	return nil
@@ -64,7 +101,8 @@ func (r *TxWalletRepo) GetOperationsByIDs(ctx context.Context, walletID string,
 	}
 
 	query, args, err := sqlx.In(`
-		SELECT id, wallet_id, operation_type, amount, status, created_at, processed_at, error
+		SELECT id, wallet_id, destination_wallet_id, reference_operation_id, operation_type, amount, reversed_amount,
+			fee, currency, status, expires_at, created_at, processed_at, error
 		FROM wallet_operations 
 		WHERE wallet_id = ? AND id IN (?)
 		ORDER BY created_at ASC
@@ -110,14 +148,14 @@ func (r *TxWalletRepo) updateBatch(ctx context.Context, ops []models.WalletOpera
 		return nil
 	}
 
-	args := make([]interface{}, 0, 5*len(ops))
+	args := make([]interface{}, 0, 6*len(ops))
 	values := make([]string, 0, len(ops))
 
 	for i, op := range ops {
-		base := i*5 + 1
+		base := i*6 + 1
 		values = append(values,
-			fmt.Sprintf("($%d::uuid,$%d::uuid,$%d::text,$%d::timestamptz,$%d::text)",
-				base, base+1, base+2, base+3, base+4,
+			fmt.Sprintf("($%d::uuid,$%d::uuid,$%d::text,$%d::timestamptz,$%d::text,$%d::bigint)",
+				base, base+1, base+2, base+3, base+4, base+5,
 			),
 		)
 
@@ -127,6 +165,7 @@ func (r *TxWalletRepo) updateBatch(ctx context.Context, ops []models.WalletOpera
 			op.Status,
 			op.ProcessedAt,
 			op.Error,
+			op.Fee,
 		)
 	}
 
@@ -135,10 +174,11 @@ func (r *TxWalletRepo) updateBatch(ctx context.Context, ops []models.WalletOpera
 		SET
 			status = v.status,
 			processed_at = v.processed_at,
-			error = v.error
+			error = v.error,
+			fee = v.fee
 		FROM (VALUES
 			%s
-		) AS v(id, wallet_id, status, processed_at, error)
+		) AS v(id, wallet_id, status, processed_at, error, fee)
 		WHERE w.id = v.id AND w.wallet_id = v.wallet_id
 	`, strings.Join(values, ","))
 
@@ -147,3 +187,243 @@ func (r *TxWalletRepo) updateBatch(ctx context.Context, ops []models.WalletOpera
 	}
 	return nil
 }
+
+// SumOperationsSince returns the total amount of the wallet's processed operations
+// of the given types processed at or after since, less the part already reversed:
+// a reversal restores the limit headroom used by its operation
+func (r *TxWalletRepo) SumOperationsSince(ctx context.Context, walletID string, operationTypes []string, since time.Time) (int64, error) {
+	query, args, err := sqlx.In(`
+		SELECT COALESCE(SUM(amount - reversed_amount), 0) FROM wallet_operations
+		WHERE wallet_id = ? AND status = 'PROCESSED' AND operation_type IN (?) AND processed_at >= ?
+	`, walletID, operationTypes, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var total int64
+	if err := r.tx.GetContext(ctx, &total, r.tx.Rebind(query), args...); err != nil {
+		return 0, fmt.Errorf("failed to sum operations: %w", err)
+	}
+
+	return total, nil
+}
+
+// UpdateReversedAmounts writes how much of each operation has been reversed
+func (r *TxWalletRepo) UpdateReversedAmounts(ctx context.Context, operations []models.WalletOperation) error {
+	if len(operations) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, 3*len(operations))
+	values := make([]string, 0, len(operations))
+
+	for i, op := range operations {
+		base := i*3 + 1
+		values = append(values, fmt.Sprintf("($%d::uuid,$%d::uuid,$%d::bigint)", base, base+1, base+2))
+		args = append(args, op.ID, op.WalletID, op.ReversedAmount)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE wallet_operations AS w
+		SET reversed_amount = v.reversed_amount
+		FROM (VALUES
+			%s
+		) AS v(id, wallet_id, reversed_amount)
+		WHERE w.id = v.id AND w.wallet_id = v.wallet_id
+	`, strings.Join(values, ","))
+
+	if _, err := r.tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update reversed amounts: %w", err)
+	}
+	return nil
+}
+
+func (r *TxWalletRepo) InsertLedgerEntries(ctx context.Context, entries []models.LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	batchSize := 100 // Batch size to prevent too large requests
+	for i := 0; i < len(entries); i += batchSize {
+		end := i + batchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		if err := r.insertLedgerBatch(ctx, entries[i:end]); err != nil {
+			return fmt.Errorf("failed to insert ledger batch [%d:%d]: %w", i, end, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *TxWalletRepo) insertLedgerBatch(ctx context.Context, entries []models.LedgerEntry) error {
+	args := make([]interface{}, 0, 7*len(entries))
+	values := make([]string, 0, len(entries))
+
+	for i, e := range entries {
+		base := i*7 + 1
+		values = append(values,
+			fmt.Sprintf("($%d::uuid,$%d::uuid,$%d::text,$%d::bigint,$%d::text,$%d::bigint,$%d::timestamptz)",
+				base, base+1, base+2, base+3, base+4, base+5, base+6,
+			),
+		)
+
+		args = append(args,
+			e.OperationID,
+			e.WalletID,
+			e.Direction,
+			e.Amount,
+			e.Currency,
+			e.BalanceAfter,
+			e.CreatedAt,
+		)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO ledger_entries
+		(operation_id, wallet_id, direction, amount, currency, balance_after, created_at)
+		VALUES %s
+	`, strings.Join(values, ","))
+
+	if _, err := r.tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("bulk INSERT ledger entries failed: %w", err)
+	}
+	return nil
+}
+
+// GetHolds returns holds of the wallet by their HOLD operation IDs
+func (r *TxWalletRepo) GetHolds(ctx context.Context, walletID string, holdIDs []string) ([]models.Hold, error) {
+	if len(holdIDs) == 0 {
+		return []models.Hold{}, nil
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT operation_id, wallet_id, amount, remaining_amount, status, expires_at, created_at, updated_at
+		FROM holds
+		WHERE wallet_id = ? AND operation_id IN (?)
+	`, walletID, holdIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	query = r.tx.Rebind(query)
+	var holds []models.Hold
+	if err := r.tx.SelectContext(ctx, &holds, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get holds: %w", err)
+	}
+
+	return holds, nil
+}
+
+// GetExpiredHolds returns active holds of the wallet that expired before now
+func (r *TxWalletRepo) GetExpiredHolds(ctx context.Context, walletID string, now time.Time) ([]models.Hold, error) {
+	query := `
+		SELECT operation_id, wallet_id, amount, remaining_amount, status, expires_at, created_at, updated_at
+		FROM holds
+		WHERE wallet_id = $1 AND status = 'ACTIVE' AND expires_at <= $2
+	`
+
+	var holds []models.Hold
+	if err := r.tx.SelectContext(ctx, &holds, query, walletID, now); err != nil {
+		return nil, fmt.Errorf("failed to get expired holds: %w", err)
+	}
+
+	return holds, nil
+}
+
+// SaveHolds inserts new holds and updates the remaining amount and status of existing ones
+func (r *TxWalletRepo) SaveHolds(ctx context.Context, holds []models.Hold) error {
+	if len(holds) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, 6*len(holds))
+	values := make([]string, 0, len(holds))
+
+	for i, h := range holds {
+		base := i*6 + 1
+		values = append(values,
+			fmt.Sprintf("($%d::uuid,$%d::uuid,$%d::bigint,$%d::bigint,$%d::text,$%d::timestamptz)",
+				base, base+1, base+2, base+3, base+4, base+5,
+			),
+		)
+
+		args = append(args,
+			h.OperationID,
+			h.WalletID,
+			h.Amount,
+			h.RemainingAmount,
+			h.Status,
+			h.ExpiresAt,
+		)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO holds (operation_id, wallet_id, amount, remaining_amount, status, expires_at)
+		VALUES %s
+		ON CONFLICT (operation_id) DO UPDATE SET
+			remaining_amount = EXCLUDED.remaining_amount,
+			status = EXCLUDED.status,
+			updated_at = NOW()
+	`, strings.Join(values, ","))
+
+	if _, err := r.tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save holds: %w", err)
+	}
+	return nil
+}
+
+// InsertWebhookDeliveries queues a delivery of every event to each webhook
+// subscription of its type and wallet, in the transaction that commits the events
+func (r *TxWalletRepo) InsertWebhookDeliveries(ctx context.Context, events []models.WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	batchSize := 100 // Batch size to prevent too large requests
+	for i := 0; i < len(events); i += batchSize {
+		end := i + batchSize
+		if end > len(events) {
+			end = len(events)
+		}
+
+		if err := r.insertWebhookDeliveryBatch(ctx, events[i:end]); err != nil {
+			return fmt.Errorf("failed to insert webhook delivery batch [%d:%d]: %w", i, end, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *TxWalletRepo) insertWebhookDeliveryBatch(ctx context.Context, events []models.WebhookEvent) error {
+	args := make([]interface{}, 0, 3*len(events))
+	values := make([]string, 0, len(events))
+
+	for i, e := range events {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook event: %w", err)
+		}
+
+		base := i*3 + 1
+		values = append(values, fmt.Sprintf("($%d::text,$%d::uuid,$%d::jsonb)", base, base+1, base+2))
+		args = append(args, e.Type, e.WalletID, string(data))
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO webhook_deliveries (subscription_id, event_type, wallet_id, data)
+		SELECT s.id, e.event_type, e.wallet_id, e.data
+		FROM (VALUES
+			%s
+		) AS e(event_type, wallet_id, data)
+		JOIN webhook_subscriptions s
+			ON e.event_type = ANY(s.event_types) AND (s.wallet_id IS NULL OR s.wallet_id = e.wallet_id)
+	`, strings.Join(values, ","))
+
+	if _, err := r.tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("bulk INSERT webhook deliveries failed: %w", err)
+	}
+	return nil
+}
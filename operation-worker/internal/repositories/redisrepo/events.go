@@ -0,0 +1,57 @@
+package redisrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"operation-worker/internal/models"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// EventsChannel is the channel every wallet-service replica subscribes to
+	EventsChannel = "wallet-events"
+	// Events kept per wallet for clients resuming with Last-Event-ID
+	eventStreamLength = 1000
+	eventStreamTTL    = 24 * time.Hour
+)
+
+// publishEventScript appends the event to the stream of the wallet and publishes it
+// prefixed by its stream ID, so live and replayed events share the same IDs
+var publishEventScript = redis.NewScript(`
+local id = redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], '*', 'event', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('PUBLISH', ARGV[4], id .. '\n' .. ARGV[2])
+return id
+`)
+
+// PublishEvents appends the events to the streams of their wallets and publishes
+// them to the events channel in one round trip
+func (r *WalletRepository) PublishEvents(ctx context.Context, events []models.WalletEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	pipe := r.client.Pipeline()
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		publishEventScript.Eval(ctx, pipe, []string{r.getEventsKey(event.WalletID)},
+			eventStreamLength, string(payload), eventStreamTTL.Milliseconds(), EventsChannel)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish events to redis: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WalletRepository) getEventsKey(walletID string) string {
+	return r.prefix + walletID + ":events"
+}
@@ -30,13 +30,13 @@ func NewWalletRepository(client *redis.Client) *WalletRepository {
 	}
 }
 
-func (r *WalletRepository) SetBalance(ctx context.Context, walletID string, balance int64) error {
-	key := r.getBalanceKey(walletID)
-
-	balanceStr := strconv.FormatInt(balance, 10)
+// SetBalance caches the balance together with the amount reserved by holds
+func (r *WalletRepository) SetBalance(ctx context.Context, walletID string, balance, heldAmount int64) error {
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, r.getBalanceKey(walletID), strconv.FormatInt(balance, 10), expiration)
+	pipe.Set(ctx, r.getHeldKey(walletID), strconv.FormatInt(heldAmount, 10), expiration)
 
-	err := r.client.Set(ctx, key, balanceStr, expiration).Err()
-	if err != nil {
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("failed to set balance in redis: %w", err)
 	}
 
@@ -63,9 +63,7 @@ func (r *WalletRepository) GetBalance(ctx context.Context, walletID string) (int
 }
 
 func (r *WalletRepository) DeleteBalance(ctx context.Context, walletID string) error {
-	key := r.getBalanceKey(walletID)
-
-	err := r.client.Del(ctx, key).Err()
+	err := r.client.Del(ctx, r.getBalanceKey(walletID), r.getHeldKey(walletID)).Err()
 	if err != nil {
 		return fmt.Errorf("failed to delete balance from redis: %w", err)
 	}
@@ -73,6 +71,24 @@ func (r *WalletRepository) DeleteBalance(ctx context.Context, walletID string) e
 	return nil
 }
 
+// DeleteStatus drops the cached status of a wallet, the wallet service caches it again
+// on the next read
+func (r *WalletRepository) DeleteStatus(ctx context.Context, walletID string) error {
+	if err := r.client.Del(ctx, r.getStatusKey(walletID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete status from redis: %w", err)
+	}
+
+	return nil
+}
+
 func (r *WalletRepository) getBalanceKey(walletID string) string {
 	return r.prefix + walletID + ":balance"
 }
+
+func (r *WalletRepository) getHeldKey(walletID string) string {
+	return r.prefix + walletID + ":held"
+}
+
+func (r *WalletRepository) getStatusKey(walletID string) string {
+	return r.prefix + walletID + ":status"
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"operation-worker/internal/models"
+	"sort"
+)
+
+// walletEvents собирает события закоммиченного батча: новый статус каждой операции
+// для кошелька операции и получателя перевода, затем итоговые балансы кошельков
+func walletEvents(operations []models.WalletOperation, wallets map[string]*models.Wallet) []models.WalletEvent {
+	events := make([]models.WalletEvent, 0, len(operations)+len(wallets))
+
+	for _, operation := range operations {
+		data := models.OperationEvent{
+			OperationID:   operation.ID,
+			OperationType: operation.OperationType,
+			Status:        operation.Status,
+			Amount:        operation.Amount,
+			Fee:           operation.Fee,
+			Currency:      operation.Currency,
+			Error:         operation.Error,
+			ProcessedAt:   operation.ProcessedAt,
+		}
+		events = append(events, models.WalletEvent{Type: models.EventTypeOperation, WalletID: operation.WalletID, Data: data})
+		if operation.DestinationWalletID != nil && *operation.DestinationWalletID != operation.WalletID {
+			events = append(events, models.WalletEvent{Type: models.EventTypeOperation, WalletID: *operation.DestinationWalletID, Data: data})
+		}
+	}
+
+	// Сортируем, чтобы порядок событий не зависел от обхода map
+	walletIDs := make([]string, 0, len(wallets))
+	for id := range wallets {
+		walletIDs = append(walletIDs, id)
+	}
+	sort.Strings(walletIDs)
+
+	for _, id := range walletIDs {
+		wallet := wallets[id]
+		events = append(events, models.WalletEvent{
+			Type:     models.EventTypeBalance,
+			WalletID: wallet.ID,
+			Data: models.BalanceEvent{
+				Balance:          wallet.Balance,
+				HeldAmount:       wallet.HeldAmount,
+				AvailableBalance: wallet.AvailableBalance(),
+				Currency:         wallet.Currency,
+			},
+		})
+	}
+
+	return events
+}
+
+// webhookEvents выбирает события для вебхуков: завершение операции (успешное или нет)
+// и новый баланс кошелька
+func webhookEvents(events []models.WalletEvent) []models.WebhookEvent {
+	webhooks := make([]models.WebhookEvent, 0, len(events))
+
+	for _, event := range events {
+		var eventType string
+		switch data := event.Data.(type) {
+		case models.OperationEvent:
+			switch data.Status {
+			case models.OperationStatusProcessed:
+				eventType = models.WebhookEventOperationProcessed
+			case models.OperationStatusFailed:
+				eventType = models.WebhookEventOperationFailed
+			default:
+				continue
+			}
+		case models.BalanceEvent:
+			eventType = models.WebhookEventBalanceUpdated
+		default:
+			continue
+		}
+
+		webhooks = append(webhooks, models.WebhookEvent{Type: eventType, WalletID: event.WalletID, Data: event.Data})
+	}
+
+	return webhooks
+}
+
+// publishEvents отправляет события клиентам после коммита (вне транзакции).
+// Ошибка публикации, как и ошибка кэша, не откатывает обработку.
+func (s *WalletService) publishEvents(ctx context.Context, events []models.WalletEvent) {
+	if err := s.cacheRepo.PublishEvents(ctx, events); err != nil {
+		fmt.Printf("Warning: failed to publish wallet events: %v\n", err)
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+
+	"operation-worker/internal/models"
+)
+
+func TestWalletEvents(t *testing.T) {
+	destination := "w-2"
+	operations := []models.WalletOperation{
+		{ID: "op-1", WalletID: "w-1", OperationType: models.OperationTypeDeposit, Amount: 100, Currency: "USD", Status: models.OperationStatusProcessed},
+		{ID: "op-2", WalletID: "w-1", DestinationWalletID: &destination, OperationType: models.OperationTypeTransfer, Amount: 30, Fee: 1, Currency: "USD", Status: models.OperationStatusProcessed},
+	}
+	wallets := map[string]*models.Wallet{
+		"w-2": {ID: "w-2", Balance: 30, Currency: "USD"},
+		"w-1": {ID: "w-1", Balance: 69, HeldAmount: 9, Currency: "USD"},
+	}
+
+	got := walletEvents(operations, wallets)
+
+	// Перевод попадает в поток обоих кошельков, балансы идут после операций
+	want := []struct {
+		eventType string
+		walletID  string
+	}{
+		{models.EventTypeOperation, "w-1"},
+		{models.EventTypeOperation, "w-1"},
+		{models.EventTypeOperation, "w-2"},
+		{models.EventTypeBalance, "w-1"},
+		{models.EventTypeBalance, "w-2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events: got %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Type != want[i].eventType || got[i].WalletID != want[i].walletID {
+			t.Errorf("event %d: got %s for %s, want %s for %s", i, got[i].Type, got[i].WalletID, want[i].eventType, want[i].walletID)
+		}
+	}
+
+	balance, ok := got[3].Data.(models.BalanceEvent)
+	if !ok {
+		t.Fatalf("balance event data: got %T", got[3].Data)
+	}
+	if balance.AvailableBalance != 60 {
+		t.Errorf("available balance: got %d, want 60", balance.AvailableBalance)
+	}
+
+	transfer, ok := got[2].Data.(models.OperationEvent)
+	if !ok {
+		t.Fatalf("operation event data: got %T", got[2].Data)
+	}
+	if transfer.OperationID != "op-2" || transfer.Fee != 1 {
+		t.Errorf("transfer event: got %+v", transfer)
+	}
+}
+
+func TestWebhookEvents(t *testing.T) {
+	events := []models.WalletEvent{
+		{Type: models.EventTypeOperation, WalletID: "w-1", Data: models.OperationEvent{OperationID: "op-1", Status: models.OperationStatusProcessed}},
+		{Type: models.EventTypeOperation, WalletID: "w-1", Data: models.OperationEvent{OperationID: "op-2", Status: models.OperationStatusFailed}},
+		// Промежуточные статусы не отправляются
+		{Type: models.EventTypeOperation, WalletID: "w-1", Data: models.OperationEvent{OperationID: "op-3", Status: models.OperationStatusPending}},
+		{Type: models.EventTypeBalance, WalletID: "w-1", Data: models.BalanceEvent{Balance: 100}},
+	}
+
+	got := webhookEvents(events)
+	want := []string{
+		models.WebhookEventOperationProcessed,
+		models.WebhookEventOperationFailed,
+		models.WebhookEventBalanceUpdated,
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("webhook events: got %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Type != want[i] || got[i].WalletID != "w-1" {
+			t.Errorf("webhook event %d: got %s for %s, want %s for w-1", i, got[i].Type, got[i].WalletID, want[i])
+		}
+	}
+}
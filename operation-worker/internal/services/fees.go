@@ -0,0 +1,56 @@
+package services
+
+import (
+	"operation-worker/internal/models"
+)
+
+// feeFor возвращает комиссию операции по расписанию ее типа.
+// Кошелек комиссий не платит комиссию сам себе, вывод баланса закрываемого
+// кошелька проводится без комиссии.
+func (s *WalletService) feeFor(walletID string, operation models.KafkaMessage) int64 {
+	if operation.CloseWallet {
+		return 0
+	}
+	if feeWalletID, ok := s.fees.Wallet(operation.Currency); ok && feeWalletID == walletID {
+		return 0
+	}
+	return s.fees.Fee(operation.OperationType, operation.Amount)
+}
+
+// feeWallets возвращает кошельки комиссий, которые нужно заблокировать для батча
+func (s *WalletService) feeWallets(walletID string, operations []models.KafkaMessage) []string {
+	walletIDs := make([]string, 0)
+	for _, op := range operations {
+		if s.feeFor(walletID, op) == 0 {
+			continue
+		}
+		if feeWalletID, ok := s.fees.Wallet(op.Currency); ok {
+			walletIDs = append(walletIDs, feeWalletID)
+		}
+	}
+	return walletIDs
+}
+
+// lockedFeeWallet возвращает заблокированный кошелек комиссий для валюты операции
+func (s *WalletService) lockedFeeWallet(operation models.KafkaMessage, wallets map[string]*models.Wallet) *models.Wallet {
+	feeWalletID, ok := s.fees.Wallet(operation.Currency)
+	if !ok {
+		return nil
+	}
+	return wallets[feeWalletID]
+}
+
+// checkFee возвращает причину отказа, если комиссию нельзя списать
+// с кошелька, к которому уже применена операция
+func checkFee(wallet models.Wallet, feeWallet *models.Wallet, fee int64) string {
+	if feeWallet == nil {
+		return "fee wallet not configured"
+	}
+	if feeWallet.Currency != wallet.Currency {
+		return "fee wallet currency mismatch"
+	}
+	if wallet.Headroom() < fee {
+		return "insufficient funds for fee"
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"operation-worker/internal/config"
+	"operation-worker/internal/fees"
+	"operation-worker/internal/models"
+)
+
+func TestCheckFee(t *testing.T) {
+	feeWallet := &models.Wallet{ID: "house", Currency: "USD"}
+
+	// Кошелек после вывода 900 из 1000: на комиссию 100 хватает, на 101 — нет
+	wallet := models.Wallet{ID: "w-1", Balance: 100, Currency: "USD"}
+	if reason := checkFee(wallet, feeWallet, 100); reason != "" {
+		t.Fatalf("unexpected reason: %q", reason)
+	}
+	if reason := checkFee(wallet, feeWallet, 101); reason != "insufficient funds for fee" {
+		t.Fatalf("got %q, want insufficient funds for fee", reason)
+	}
+	if reason := checkFee(wallet, nil, 10); reason != "fee wallet not configured" {
+		t.Fatalf("got %q, want fee wallet not configured", reason)
+	}
+	if reason := checkFee(wallet, &models.Wallet{ID: "house", Currency: "EUR"}, 10); reason != "fee wallet currency mismatch" {
+		t.Fatalf("got %q, want fee wallet currency mismatch", reason)
+	}
+}
+
+func TestWalletService_feeFor(t *testing.T) {
+	engine, err := fees.NewEngine(config.FeesConfig{
+		Schedules: `{"WITHDRAW": {"percentBps": 100, "min": 10}}`,
+		Wallets:   "USD=house",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := NewWalletService(nil, nil, nil, engine)
+
+	withdraw := models.KafkaMessage{WalletID: "w-1", OperationType: models.OperationTypeWithdraw, Amount: 5000, Currency: "USD"}
+	if fee := s.feeFor("w-1", withdraw); fee != 50 {
+		t.Fatalf("fee: got %d, want 50", fee)
+	}
+	if fee := s.feeFor("house", withdraw); fee != 0 {
+		t.Fatalf("fee wallet must not pay itself: got %d", fee)
+	}
+	sweep := models.KafkaMessage{WalletID: "w-1", OperationType: models.OperationTypeWithdraw, Amount: 5000, Currency: "USD", CloseWallet: true}
+	if fee := s.feeFor("w-1", sweep); fee != 0 {
+		t.Fatalf("the sweep of a closed wallet must be free: got %d", fee)
+	}
+	if walletIDs := s.feeWallets("w-1", []models.KafkaMessage{withdraw}); len(walletIDs) != 1 || walletIDs[0] != "house" {
+		t.Fatalf("fee wallets: got %v", walletIDs)
+	}
+
+	// Проводки комиссии балансируются отдельно от проводок операции
+	operation := models.WalletOperation{ID: "op-1", WalletID: "w-1", OperationType: models.OperationTypeWithdraw, Amount: 5000, Fee: 50, Currency: "USD"}
+	wallets := map[string]*models.Wallet{"w-1": {ID: "w-1", Balance: 950}, "house": {ID: "house", Balance: 50}}
+	entries := feeEntriesFor(operation, "house", wallets, time.Now())
+	if len(entries) != 2 || entries[0].Amount != 50 || *entries[0].BalanceAfter != 950 || *entries[1].WalletID != "house" {
+		t.Fatalf("fee entries: got %+v", entries)
+	}
+	if err := validateLedger(map[string]int64{"w-1": 1000, "house": 0}, wallets, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
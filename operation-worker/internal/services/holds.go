@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"operation-worker/internal/models"
+	"operation-worker/internal/repositories/postgresrepo"
+	"time"
+)
+
+// processHoldOperation обрабатывает HOLD, CAPTURE и RELEASE.
+// HOLD резервирует средства (уменьшает доступный баланс, не меняя общий),
+// CAPTURE списывает всю или часть зарезервированной суммы, RELEASE освобождает ее.
+// hold — холд, на который ссылается CAPTURE/RELEASE (nil для HOLD или если холд не найден).
+func (s *WalletService) processHoldOperation(
+	operation models.KafkaMessage,
+	existingOperation models.WalletOperation,
+	wallet models.Wallet,
+	hold *models.Hold,
+	now time.Time,
+) (models.Wallet, *models.Hold, models.WalletOperation, error) {
+
+	if operation.OperationType == models.OperationTypeHold {
+		if wallet.Headroom() < operation.Amount {
+			return wallet, nil, failOperation(existingOperation, "insufficient funds"), nil
+		}
+
+		expiresAt := now
+		if existingOperation.ExpiresAt != nil {
+			expiresAt = *existingOperation.ExpiresAt
+		}
+		if !expiresAt.After(now) {
+			return wallet, nil, failOperation(existingOperation, "hold expired"), nil
+		}
+
+		wallet.HeldAmount += operation.Amount
+		newHold := &models.Hold{
+			OperationID:     existingOperation.ID,
+			WalletID:        wallet.ID,
+			Amount:          operation.Amount,
+			RemainingAmount: operation.Amount,
+			Status:          models.HoldStatusActive,
+			ExpiresAt:       expiresAt,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		return wallet, newHold, processOperation(existingOperation, now), nil
+	}
+
+	// CAPTURE и RELEASE применяются только к активному непросроченному холду
+	if hold == nil {
+		return wallet, nil, failOperation(existingOperation, "hold not found"), nil
+	}
+	if hold.Status != models.HoldStatusActive {
+		return wallet, nil, failOperation(existingOperation, fmt.Sprintf("hold is %s", hold.Status)), nil
+	}
+	if !hold.ExpiresAt.After(now) {
+		return wallet, nil, failOperation(existingOperation, "hold expired"), nil
+	}
+	if operation.Amount > hold.RemainingAmount {
+		return wallet, nil, failOperation(existingOperation, "amount exceeds held amount"), nil
+	}
+
+	updatedHold := *hold
+	updatedHold.RemainingAmount -= operation.Amount
+	updatedHold.UpdatedAt = now
+	wallet.HeldAmount -= operation.Amount
+
+	switch operation.OperationType {
+	case models.OperationTypeCapture:
+		// Зарезервированные средства уже проверены при холде, поэтому доступный баланс не проверяем
+		wallet.Balance -= operation.Amount
+		if updatedHold.RemainingAmount == 0 {
+			updatedHold.Status = models.HoldStatusCaptured
+		}
+	case models.OperationTypeRelease:
+		if updatedHold.RemainingAmount == 0 {
+			updatedHold.Status = models.HoldStatusReleased
+		}
+	}
+
+	return wallet, &updatedHold, processOperation(existingOperation, now), nil
+}
+
+// ExpireHolds освобождает средства просроченных холдов не более чем у limit кошельков
+// после afterWalletID (в порядке ID) и возвращает выбранные кошельки и количество
+// кошельков, у которых холды сняты. Ошибка одного кошелька не останавливает остальные:
+// ошибки кошельков логируются и возвращаются вместе
+func (s *WalletService) ExpireHolds(ctx context.Context, now time.Time, afterWalletID string, limit int) ([]string, int, error) {
+	walletIDs, err := s.walletRepo.GetWalletsWithExpiredHolds(ctx, now, afterWalletID, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get wallets with expired holds: %w", err)
+	}
+
+	released := 0
+	var errs []error
+	for _, walletID := range walletIDs {
+		if err := s.expireWalletHolds(ctx, walletID, now); err != nil {
+			fmt.Printf("Failed to expire holds of wallet %s: %v\n", walletID, err)
+			errs = append(errs, fmt.Errorf("wallet %s: %w", walletID, err))
+			continue
+		}
+		released++
+	}
+
+	if len(errs) > 0 {
+		return walletIDs, released, fmt.Errorf("failed to expire holds of %d wallets: %w", len(errs), errors.Join(errs...))
+	}
+	return walletIDs, released, nil
+}
+
+// expireWalletHolds снимает просроченные холды кошелька под той же блокировкой,
+// что и обработка операций, поэтому не конкурирует с CAPTURE/RELEASE
+func (s *WalletService) expireWalletHolds(ctx context.Context, walletID string, now time.Time) error {
+	txRepo, err := s.walletRepo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	wallet, holds, err := s.expireHoldsInTx(ctx, txRepo, walletID, now)
+	if err != nil {
+		if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("expire error: %w, rollback error: %v", err, rollbackErr)
+		}
+		return err
+	}
+
+	var events []models.WalletEvent
+	if len(holds) > 0 {
+		events = walletEvents(nil, map[string]*models.Wallet{walletID: wallet})
+	}
+
+	// Вебхуки ставятся в очередь в той же транзакции, что и изменение баланса
+	if err := txRepo.InsertWebhookDeliveries(ctx, webhookEvents(events)); err != nil {
+		if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("webhook deliveries error: %w, rollback error: %v", err, rollbackErr)
+		}
+		return fmt.Errorf("failed to insert webhook deliveries: %w", err)
+	}
+
+	if err := txRepo.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if len(holds) > 0 {
+		if err := s.updateCache(ctx, *wallet); err != nil {
+			fmt.Printf("Warning: failed to update cache for wallet %s: %v\n", walletID, err)
+		}
+		s.publishEvents(ctx, events)
+	}
+
+	return nil
+}
+
+func (s *WalletService) expireHoldsInTx(
+	ctx context.Context,
+	txRepo *postgresrepo.TxWalletRepo,
+	walletID string,
+	now time.Time,
+) (*models.Wallet, []models.Hold, error) {
+	wallets, err := txRepo.LockWalletsForUpdate(ctx, []string{walletID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock wallet: %w", err)
+	}
+	if len(wallets) == 0 {
+		return nil, nil, fmt.Errorf("wallet not found: %s", walletID)
+	}
+	wallet := wallets[0]
+
+	// Повторно читаем холды под блокировкой: их могли списать или освободить
+	holds, err := txRepo.GetExpiredHolds(ctx, walletID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(holds) == 0 {
+		return &wallet, nil, nil
+	}
+
+	for i := range holds {
+		wallet.HeldAmount -= holds[i].RemainingAmount
+		holds[i].RemainingAmount = 0
+		holds[i].Status = models.HoldStatusExpired
+		holds[i].UpdatedAt = now
+	}
+
+	if err := txRepo.SaveHolds(ctx, holds); err != nil {
+		return nil, nil, err
+	}
+	if err := txRepo.UpdateBalance(ctx, wallet); err != nil {
+		return nil, nil, err
+	}
+
+	return &wallet, holds, nil
+}
+
+// referencedHolds возвращает холды, на которые ссылаются CAPTURE и RELEASE батча
+func referencedHolds(operations []models.KafkaMessage) []string {
+	holdIDs := make([]string, 0)
+	for _, op := range operations {
+		if (op.OperationType == models.OperationTypeCapture || op.OperationType == models.OperationTypeRelease) &&
+			op.ReferenceOperationID != "" {
+			holdIDs = append(holdIDs, op.ReferenceOperationID)
+		}
+	}
+	return holdIDs
+}
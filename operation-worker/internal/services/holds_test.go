@@ -0,0 +1,130 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"operation-worker/internal/models"
+)
+
+func TestWalletService_processHoldOperation(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+	s := NewWalletService(nil, nil, nil, nil)
+
+	pending := func(id, opType string, amount int64) (models.KafkaMessage, models.WalletOperation) {
+		return models.KafkaMessage{
+			OperationID:          id,
+			WalletID:             "w-1",
+			ReferenceOperationID: "hold-1",
+			OperationType:        opType,
+			Amount:               amount,
+		}, models.WalletOperation{
+			ID:            id,
+			WalletID:      "w-1",
+			OperationType: opType,
+			Amount:        amount,
+			Status:        models.OperationStatusPending,
+			ExpiresAt:     &expiresAt,
+		}
+	}
+	activeHold := &models.Hold{
+		OperationID:     "hold-1",
+		WalletID:        "w-1",
+		Amount:          400,
+		RemainingAmount: 400,
+		Status:          models.HoldStatusActive,
+		ExpiresAt:       expiresAt,
+	}
+
+	t.Run("hold: reserves funds without changing balance", func(t *testing.T) {
+		msg, op := pending("hold-1", models.OperationTypeHold, 400)
+		wallet, hold, updated, err := s.processHoldOperation(msg, op, models.Wallet{ID: "w-1", Balance: 1000}, nil, now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if updated.Status != models.OperationStatusProcessed {
+			t.Fatalf("status: got %q, want PROCESSED", updated.Status)
+		}
+		if wallet.Balance != 1000 || wallet.HeldAmount != 400 || wallet.AvailableBalance() != 600 {
+			t.Fatalf("wallet: got %+v", wallet)
+		}
+		if hold == nil || hold.RemainingAmount != 400 || hold.Status != models.HoldStatusActive || !hold.ExpiresAt.Equal(expiresAt) {
+			t.Fatalf("hold: got %+v", hold)
+		}
+	})
+
+	t.Run("hold: insufficient available funds", func(t *testing.T) {
+		msg, op := pending("hold-2", models.OperationTypeHold, 400)
+		wallet, hold, updated, _ := s.processHoldOperation(msg, op, models.Wallet{ID: "w-1", Balance: 1000, HeldAmount: 700}, nil, now)
+		if updated.Status != models.OperationStatusFailed || *updated.Error != "insufficient funds" {
+			t.Fatalf("operation: got %+v", updated)
+		}
+		if hold != nil || wallet.HeldAmount != 700 {
+			t.Fatalf("nothing must be reserved: wallet %+v, hold %+v", wallet, hold)
+		}
+	})
+
+	t.Run("capture: partial capture debits balance and keeps the rest held", func(t *testing.T) {
+		msg, op := pending("cap-1", models.OperationTypeCapture, 150)
+		wallet, hold, updated, _ := s.processHoldOperation(msg, op, models.Wallet{ID: "w-1", Balance: 1000, HeldAmount: 400}, activeHold, now)
+		if updated.Status != models.OperationStatusProcessed {
+			t.Fatalf("status: got %q, want PROCESSED", updated.Status)
+		}
+		if wallet.Balance != 850 || wallet.HeldAmount != 250 {
+			t.Fatalf("wallet: got %+v", wallet)
+		}
+		if hold.RemainingAmount != 250 || hold.Status != models.HoldStatusActive {
+			t.Fatalf("hold: got %+v", hold)
+		}
+		if activeHold.RemainingAmount != 400 {
+			t.Fatalf("original hold must not be modified")
+		}
+	})
+
+	t.Run("capture: full capture closes the hold", func(t *testing.T) {
+		msg, op := pending("cap-2", models.OperationTypeCapture, 400)
+		_, hold, _, _ := s.processHoldOperation(msg, op, models.Wallet{ID: "w-1", Balance: 1000, HeldAmount: 400}, activeHold, now)
+		if hold.RemainingAmount != 0 || hold.Status != models.HoldStatusCaptured {
+			t.Fatalf("hold: got %+v", hold)
+		}
+	})
+
+	t.Run("capture: more than held -> failed", func(t *testing.T) {
+		msg, op := pending("cap-3", models.OperationTypeCapture, 500)
+		wallet, hold, updated, _ := s.processHoldOperation(msg, op, models.Wallet{ID: "w-1", Balance: 1000, HeldAmount: 400}, activeHold, now)
+		if updated.Status != models.OperationStatusFailed || *updated.Error != "amount exceeds held amount" {
+			t.Fatalf("operation: got %+v", updated)
+		}
+		if hold != nil || wallet.Balance != 1000 || wallet.HeldAmount != 400 {
+			t.Fatalf("nothing must change: wallet %+v, hold %+v", wallet, hold)
+		}
+	})
+
+	t.Run("release: frees held funds", func(t *testing.T) {
+		msg, op := pending("rel-1", models.OperationTypeRelease, 400)
+		wallet, hold, _, _ := s.processHoldOperation(msg, op, models.Wallet{ID: "w-1", Balance: 1000, HeldAmount: 400}, activeHold, now)
+		if wallet.Balance != 1000 || wallet.HeldAmount != 0 {
+			t.Fatalf("wallet: got %+v", wallet)
+		}
+		if hold.Status != models.HoldStatusReleased {
+			t.Fatalf("hold: got %+v", hold)
+		}
+	})
+
+	t.Run("release: expired hold -> failed", func(t *testing.T) {
+		msg, op := pending("rel-2", models.OperationTypeRelease, 400)
+		_, _, updated, _ := s.processHoldOperation(msg, op, models.Wallet{ID: "w-1", Balance: 1000, HeldAmount: 400}, activeHold, expiresAt)
+		if updated.Status != models.OperationStatusFailed || *updated.Error != "hold expired" {
+			t.Fatalf("operation: got %+v", updated)
+		}
+	})
+
+	t.Run("capture: unknown hold -> failed", func(t *testing.T) {
+		msg, op := pending("cap-4", models.OperationTypeCapture, 100)
+		_, _, updated, _ := s.processHoldOperation(msg, op, models.Wallet{ID: "w-1", Balance: 1000}, nil, now)
+		if updated.Status != models.OperationStatusFailed || *updated.Error != "hold not found" {
+			t.Fatalf("operation: got %+v", updated)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+	"operation-worker/internal/models"
+	"time"
+)
+
+// ledgerEntriesFor возвращает проводки обработанной операции.
+// wallets должен содержать балансы кошельков уже после применения операции.
+// Внешний счет (WalletID == nil) — источник пополнений и получатель выводов.
+// HOLD и RELEASE не меняют баланс и проводок не порождают.
+func ledgerEntriesFor(operation models.WalletOperation, wallets map[string]*models.Wallet, now time.Time) []models.LedgerEntry {
+	walletEntry := func(walletID, direction string) models.LedgerEntry {
+		balanceAfter := wallets[walletID].Balance
+		return models.LedgerEntry{
+			OperationID:  operation.ID,
+			WalletID:     &walletID,
+			Direction:    direction,
+			Amount:       operation.Amount,
+			Currency:     operation.Currency,
+			BalanceAfter: &balanceAfter,
+			CreatedAt:    now,
+		}
+	}
+	externalEntry := func(direction string) models.LedgerEntry {
+		return models.LedgerEntry{
+			OperationID: operation.ID,
+			Direction:   direction,
+			Amount:      operation.Amount,
+			Currency:    operation.Currency,
+			CreatedAt:   now,
+		}
+	}
+
+	switch operation.OperationType {
+	case models.OperationTypeDeposit:
+		return []models.LedgerEntry{
+			externalEntry(models.EntryDirectionDebit),
+			walletEntry(operation.WalletID, models.EntryDirectionCredit),
+		}
+	case models.OperationTypeWithdraw, models.OperationTypeCapture:
+		return []models.LedgerEntry{
+			walletEntry(operation.WalletID, models.EntryDirectionDebit),
+			externalEntry(models.EntryDirectionCredit),
+		}
+	case models.OperationTypeTransfer:
+		return []models.LedgerEntry{
+			walletEntry(operation.WalletID, models.EntryDirectionDebit),
+			walletEntry(*operation.DestinationWalletID, models.EntryDirectionCredit),
+		}
+	}
+
+	return nil
+}
+
+// feeEntriesFor возвращает проводки комиссии операции: списание с кошелька операции
+// и зачисление на кошелек комиссий. wallets должен содержать балансы после списания комиссии.
+func feeEntriesFor(operation models.WalletOperation, feeWalletID string, wallets map[string]*models.Wallet, now time.Time) []models.LedgerEntry {
+	entry := func(walletID, direction string) models.LedgerEntry {
+		balanceAfter := wallets[walletID].Balance
+		return models.LedgerEntry{
+			OperationID:  operation.ID,
+			WalletID:     &walletID,
+			Direction:    direction,
+			Amount:       operation.Fee,
+			Currency:     operation.Currency,
+			BalanceAfter: &balanceAfter,
+			CreatedAt:    now,
+		}
+	}
+
+	return []models.LedgerEntry{
+		entry(operation.WalletID, models.EntryDirectionDebit),
+		entry(feeWalletID, models.EntryDirectionCredit),
+	}
+}
+
+// validateLedger проверяет, что проводки батча сбалансированы по каждой операции
+// и в сумме дают ровно изменение баланса каждого кошелька
+func validateLedger(initialBalances map[string]int64, wallets map[string]*models.Wallet, entries []models.LedgerEntry) error {
+	operationTotals := make(map[string]int64)
+	walletDeltas := make(map[string]int64)
+
+	for _, entry := range entries {
+		signed := entry.Amount
+		if entry.Direction == models.EntryDirectionDebit {
+			signed = -entry.Amount
+		}
+
+		operationTotals[entry.OperationID] += signed
+		if entry.WalletID != nil {
+			walletDeltas[*entry.WalletID] += signed
+		}
+	}
+
+	for operationID, total := range operationTotals {
+		if total != 0 {
+			return fmt.Errorf("ledger is unbalanced for operation %s: credits minus debits is %d", operationID, total)
+		}
+	}
+
+	for walletID, wallet := range wallets {
+		if delta := wallet.Balance - initialBalances[walletID]; walletDeltas[walletID] != delta {
+			return fmt.Errorf("ledger mismatch for wallet %s: entries sum to %d, balance changed by %d",
+				walletID, walletDeltas[walletID], delta)
+		}
+	}
+
+	return nil
+}
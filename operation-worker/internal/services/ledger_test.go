@@ -0,0 +1,84 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"operation-worker/internal/models"
+)
+
+func TestLedgerEntriesFor(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	transfer := models.WalletOperation{
+		ID:                  "op-1",
+		WalletID:            "w-1",
+		DestinationWalletID: strptr("w-2"),
+		OperationType:       models.OperationTypeTransfer,
+		Amount:              300,
+		Status:              models.OperationStatusProcessed,
+	}
+	wallets := map[string]*models.Wallet{"w-1": {ID: "w-1", Balance: 700}, "w-2": {ID: "w-2", Balance: 1300}}
+
+	entries := ledgerEntriesFor(transfer, wallets, now)
+	if len(entries) != 2 {
+		t.Fatalf("entries: got %d, want 2", len(entries))
+	}
+
+	// Списание с отправителя и зачисление получателю с балансами после операции
+	if *entries[0].WalletID != "w-1" || entries[0].Direction != models.EntryDirectionDebit || *entries[0].BalanceAfter != 700 {
+		t.Fatalf("debit entry: got %+v", entries[0])
+	}
+	if *entries[1].WalletID != "w-2" || entries[1].Direction != models.EntryDirectionCredit || *entries[1].BalanceAfter != 1300 {
+		t.Fatalf("credit entry: got %+v", entries[1])
+	}
+
+	deposit := models.WalletOperation{ID: "op-2", WalletID: "w-1", OperationType: models.OperationTypeDeposit, Amount: 50}
+	entries = ledgerEntriesFor(deposit, wallets, now)
+	if len(entries) != 2 || entries[0].WalletID != nil || entries[0].Direction != models.EntryDirectionDebit {
+		t.Fatalf("deposit must be funded by the external account: got %+v", entries)
+	}
+
+	// Холд не двигает деньги и не порождает проводок
+	hold := models.WalletOperation{ID: "op-3", WalletID: "w-1", OperationType: models.OperationTypeHold, Amount: 50}
+	if entries = ledgerEntriesFor(hold, wallets, now); len(entries) != 0 {
+		t.Fatalf("hold must not produce entries: got %+v", entries)
+	}
+}
+
+func TestValidateLedger(t *testing.T) {
+	now := time.Now()
+	initial := map[string]int64{"w-1": 1000, "w-2": 0}
+
+	withdraw := models.WalletOperation{ID: "op-1", WalletID: "w-1", OperationType: models.OperationTypeWithdraw, Amount: 200}
+	transfer := models.WalletOperation{
+		ID:                  "op-2",
+		WalletID:            "w-1",
+		DestinationWalletID: strptr("w-2"),
+		OperationType:       models.OperationTypeTransfer,
+		Amount:              300,
+	}
+
+	wallets := map[string]*models.Wallet{"w-1": {ID: "w-1", Balance: 800}, "w-2": {ID: "w-2", Balance: 0}}
+	entries := ledgerEntriesFor(withdraw, wallets, now)
+	wallets["w-1"].Balance, wallets["w-2"].Balance = 500, 300
+	entries = append(entries, ledgerEntriesFor(transfer, wallets, now)...)
+
+	if err := validateLedger(initial, wallets, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Баланс изменился, а проводок на это изменение нет
+	drifted := map[string]*models.Wallet{"w-1": {ID: "w-1", Balance: 400}, "w-2": {ID: "w-2", Balance: 300}}
+	err := validateLedger(initial, drifted, entries)
+	if err == nil || !strings.Contains(err.Error(), "ledger mismatch for wallet w-1") {
+		t.Fatalf("expected wallet mismatch, got %v", err)
+	}
+
+	// Операция без парной проводки
+	err = validateLedger(initial, map[string]*models.Wallet{"w-1": {ID: "w-1", Balance: 800}, "w-2": {ID: "w-2"}}, entries[:1])
+	if err == nil || !strings.Contains(err.Error(), "unbalanced for operation op-1") {
+		t.Fatalf("expected unbalanced operation, got %v", err)
+	}
+}
@@ -3,27 +3,46 @@ package services
 import (
 	"context"
 	"fmt"
+	"operation-worker/internal/fees"
+	"operation-worker/internal/limits"
 	"operation-worker/internal/models"
 	"operation-worker/internal/repositories/postgresrepo"
 	"operation-worker/internal/repositories/redisrepo"
+	"strings"
 	"time"
 )
 
 type WalletService struct {
 	walletRepo *postgresrepo.WalletRepo
 	cacheRepo  *redisrepo.WalletRepository
+	limits     *limits.Policy
+	fees       *fees.Engine
 }
 
 func NewWalletService(
 	walletRepo *postgresrepo.WalletRepo,
 	cacheRepo *redisrepo.WalletRepository,
+	limitsPolicy *limits.Policy,
+	feeEngine *fees.Engine,
 ) *WalletService {
 	return &WalletService{
 		walletRepo: walletRepo,
 		cacheRepo:  cacheRepo,
+		limits:     limitsPolicy,
+		fees:       feeEngine,
 	}
 }
 
+// batchResult — результат обработки батча, который записывается в той же транзакции
+type batchResult struct {
+	wallets    map[string]*models.Wallet // заблокированные кошельки с итоговыми балансами
+	operations []models.WalletOperation
+	entries    []models.LedgerEntry
+	holds      []models.Hold
+	reversed   []models.WalletOperation // исходные операции с новой возвращенной суммой
+	closed     []string                 // кошельки, баланс которых выведен при закрытии
+}
+
 // ProcessWalletOperations обрабатывает батч операций для одного кошелька
 func (s *WalletService) ProcessWalletOperations(walletID string, operations []models.KafkaMessage) error {
 	ctx := context.Background()
@@ -35,7 +54,7 @@ func (s *WalletService) ProcessWalletOperations(walletID string, operations []mo
 	}
 
 	// Обрабатываем операции в транзакции
-	processedBalance, operationsToUpdate, err := s.processOperationsInTx(ctx, txRepo, walletID, operations)
+	result, err := s.processOperationsInTx(ctx, txRepo, walletID, operations)
 	if err != nil {
 		if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
 			return fmt.Errorf("process error: %w, rollback error: %v", err, rollbackErr)
@@ -44,8 +63,8 @@ func (s *WalletService) ProcessWalletOperations(walletID string, operations []mo
 	}
 
 	// Массово обновляем статусы операций в БД
-	if len(operationsToUpdate) > 0 {
-		if err := txRepo.BulkUpdateOperations(ctx, operationsToUpdate); err != nil {
+	if len(result.operations) > 0 {
+		if err := txRepo.BulkUpdateOperations(ctx, result.operations); err != nil {
 			if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
 				return fmt.Errorf("bulk update error: %w, rollback error: %v", err, rollbackErr)
 			}
@@ -53,12 +72,56 @@ func (s *WalletService) ProcessWalletOperations(walletID string, operations []mo
 		}
 	}
 
-	// Обновляем баланс кошелька
-	if err := txRepo.UpdateBalance(ctx, walletID, processedBalance); err != nil {
+	// Сохраняем созданные и измененные холды
+	if err := txRepo.SaveHolds(ctx, result.holds); err != nil {
+		if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("save holds error: %w, rollback error: %v", err, rollbackErr)
+		}
+		return fmt.Errorf("failed to save holds: %w", err)
+	}
+
+	// Сохраняем возвращенные суммы исходных операций
+	if err := txRepo.UpdateReversedAmounts(ctx, result.reversed); err != nil {
+		if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("update reversed amounts error: %w, rollback error: %v", err, rollbackErr)
+		}
+		return fmt.Errorf("failed to update reversed amounts: %w", err)
+	}
+
+	// Записываем проводки в журнал в той же транзакции
+	if err := txRepo.InsertLedgerEntries(ctx, result.entries); err != nil {
+		if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("ledger insert error: %w, rollback error: %v", err, rollbackErr)
+		}
+		return fmt.Errorf("failed to insert ledger entries: %w", err)
+	}
+
+	// Обновляем балансы всех заблокированных кошельков (основного и получателей переводов)
+	for _, wallet := range result.wallets {
+		if err := txRepo.UpdateBalance(ctx, *wallet); err != nil {
+			if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("update balance error: %w, rollback error: %v", err, rollbackErr)
+			}
+			return fmt.Errorf("failed to update wallet balance: %w", err)
+		}
+	}
+
+	// Закрываем кошельки, баланс которых выведен переводом при закрытии
+	if err := txRepo.CloseWallets(ctx, result.closed); err != nil {
 		if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
-			return fmt.Errorf("update balance error: %w, rollback error: %v", err, rollbackErr)
+			return fmt.Errorf("close wallets error: %w, rollback error: %v", err, rollbackErr)
 		}
-		return fmt.Errorf("failed to update wallet balance: %w", err)
+		return fmt.Errorf("failed to close wallets: %w", err)
+	}
+
+	// Ставим в очередь вебхуки в той же транзакции, чтобы они не терялись и не
+	// отправлялись для откатившихся изменений
+	events := walletEvents(result.operations, result.wallets)
+	if err := txRepo.InsertWebhookDeliveries(ctx, webhookEvents(events)); err != nil {
+		if rollbackErr := txRepo.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("webhook deliveries error: %w, rollback error: %v", err, rollbackErr)
+		}
+		return fmt.Errorf("failed to insert webhook deliveries: %w", err)
 	}
 
 	// Коммитим транзакцию
@@ -67,30 +130,58 @@ func (s *WalletService) ProcessWalletOperations(walletID string, operations []mo
 	}
 
 	// Обновляем кэш (вне транзакции)
-	if err := s.updateCache(ctx, walletID, processedBalance); err != nil {
-		fmt.Printf("Warning: failed to update cache for wallet %s: %v\n", walletID, err)
+	for _, wallet := range result.wallets {
+		if err := s.updateCache(ctx, *wallet); err != nil {
+			fmt.Printf("Warning: failed to update cache for wallet %s: %v\n", wallet.ID, err)
+		}
+	}
+	for _, walletID := range result.closed {
+		if err := s.cacheRepo.DeleteStatus(ctx, walletID); err != nil {
+			fmt.Printf("Warning: failed to invalidate status cache for wallet %s: %v\n", walletID, err)
+		}
 	}
 
+	// Отправляем клиентам новые статусы операций и балансы
+	s.publishEvents(ctx, events)
+
 	return nil
 }
 
-// processOperationsInTx обрабатывает операции внутри транзакции и возвращает операции для обновления
+// processOperationsInTx обрабатывает операции внутри транзакции и возвращает
+// итоговые балансы заблокированных кошельков, операции для обновления, проводки и холды
 func (s *WalletService) processOperationsInTx(
 	ctx context.Context,
 	txRepo *postgresrepo.TxWalletRepo,
 	walletID string,
 	operations []models.KafkaMessage,
-) (int64, []models.WalletOperation, error) {
-
-	// Блокируем кошелек для обновления
-	wallet, err := txRepo.LockWalletForUpdate(ctx, walletID)
+) (*batchResult, error) {
+
+	// Блокируем кошелек вместе с кошельками-получателями переводов
+	// (и получателями переводов, которые отменяются возвратами) и кошельками комиссий.
+	// Порядок блокировки единый для всех воркеров, поэтому переводы
+	// между кошельками из разных партиций не приводят к дедлоку.
+	lockIDs := append(walletsToLock(walletID, operations), s.feeWallets(walletID, operations)...)
+	lockedWallets, err := txRepo.LockWalletsForUpdate(ctx, lockIDs)
 	if err != nil {
-		return 0, nil, fmt.Errorf("failed to lock wallet: %w", err)
+		return nil, fmt.Errorf("failed to lock wallet: %w", err)
+	}
+
+	wallets := make(map[string]*models.Wallet, len(lockedWallets))
+	initialBalances := make(map[string]int64, len(lockedWallets))
+	for i := range lockedWallets {
+		wallets[lockedWallets[i].ID] = &lockedWallets[i]
+		initialBalances[lockedWallets[i].ID] = lockedWallets[i].Balance
+	}
+	if _, ok := wallets[walletID]; !ok {
+		return nil, fmt.Errorf("failed to lock wallet: wallet not found: %s", walletID)
 	}
 
-	currentBalance := wallet.Balance
 	now := time.Now()
-	operationsToUpdate := make([]models.WalletOperation, 0)
+	result := &batchResult{
+		wallets:    wallets,
+		operations: make([]models.WalletOperation, 0),
+		entries:    make([]models.LedgerEntry, 0),
+	}
 
 	// Получаем текущие операции из БД для проверки статусов
 	operationIDs := make([]string, len(operations))
@@ -100,7 +191,7 @@ func (s *WalletService) processOperationsInTx(
 
 	existingOperations, err := txRepo.GetOperationsByIDs(ctx, walletID, operationIDs)
 	if err != nil {
-		return 0, nil, fmt.Errorf("failed to get operations: %w", err)
+		return nil, fmt.Errorf("failed to get operations: %w", err)
 	}
 
 	// Создаем мапу для быстрого доступа к существующим операциям
@@ -109,7 +200,40 @@ func (s *WalletService) processOperationsInTx(
 		existingOpsMap[op.ID] = op
 	}
 
+	// Загружаем холды, на которые ссылаются CAPTURE и RELEASE батча
+	existingHolds, err := txRepo.GetHolds(ctx, walletID, referencedHolds(operations))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holds: %w", err)
+	}
+
+	holds := make(map[string]*models.Hold, len(existingHolds))
+	for i := range existingHolds {
+		holds[existingHolds[i].OperationID] = &existingHolds[i]
+	}
+	changedHolds := make(map[string]bool)
+
+	// Загружаем исходные операции, которые отменяются возвратами батча.
+	// Они принадлежат тому же кошельку, поэтому защищены его блокировкой.
+	originalOperations, err := txRepo.GetOperationsByIDs(ctx, walletID, reversedOperations(operations))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reversed operations: %w", err)
+	}
+
+	originals := make(map[string]*models.WalletOperation, len(originalOperations))
+	for i := range originalOperations {
+		originals[originalOperations[i].ID] = &originalOperations[i]
+	}
+	changedOriginals := make(map[string]bool)
+
+	// Лимиты на вывод считаются внутри транзакции под блокировкой кошелька,
+	// поэтому параллельные батчи не могут превысить их вместе
+	withdrawLimits, err := s.loadWithdrawLimits(ctx, txRepo, *wallets[walletID], operations, now)
+	if err != nil {
+		return nil, err
+	}
+
 	// Обрабатываем операции в порядке их поступления
+	handled := make(map[string]bool, len(operations))
 	for _, operation := range operations {
 		// Проверяем, существует ли операция и имеет ли статус PENDING
 		existingOp, exists := existingOpsMap[operation.OperationID]
@@ -124,52 +248,176 @@ func (s *WalletService) processOperationsInTx(
 			// Операция уже обработана (PROCESSED или FAILED) - пропускаем
 			continue
 		}
+		// Сообщение может быть доставлено повторно (например, планировщик публикует
+		// операцию заново после сбоя), поэтому вторая копия в том же батче пропускается
+		if handled[operation.OperationID] {
+			continue
+		}
+		handled[operation.OperationID] = true
+
+		// Для перевода кошелек-получатель должен быть заблокирован вместе с отправителем
+		if operation.OperationType == models.OperationTypeTransfer {
+			if _, ok := wallets[operation.DestinationWalletID]; !ok || operation.DestinationWalletID == walletID {
+				result.operations = append(result.operations, failOperation(existingOp, "destination wallet not found"))
+				continue
+			}
+		}
+
+		// Операции замороженных и закрытых кошельков, уже стоящие в очереди, отклоняются
+		if reason := checkStatus(operation, wallets); reason != "" {
+			result.operations = append(result.operations, failOperation(existingOp, reason))
+			continue
+		}
+
+		// Валюта операции должна совпадать с валютой всех затронутых кошельков
+		if reason := checkCurrency(operation, wallets); reason != "" {
+			result.operations = append(result.operations, failOperation(existingOp, reason))
+			continue
+		}
+
+		// Баланс закрываемого кошелька выводится целиком
+		if reason := checkSweep(operation, wallets); reason != "" {
+			result.operations = append(result.operations, failOperation(existingOp, reason))
+			continue
+		}
+
+		// Превышение лимита на вывод — отдельная причина отказа
+		if countsToLimits(operation) {
+			if exceeded := limits.Exceeded(withdrawLimits, operation.Amount); exceeded != nil {
+				result.operations = append(result.operations, failOperation(existingOp, "limit exceeded: "+exceeded.Name))
+				continue
+			}
+		}
 
 		// Обрабатываем операцию и получаем обновленную версию
-		newBalance, updatedOperation, err := s.processSingleOperation(
-			operation, existingOp, currentBalance, now,
+		var (
+			updatedWallet    models.Wallet
+			updatedOperation models.WalletOperation
+			updatedHold      *models.Hold
+			updatedOriginal  *models.WalletOperation
 		)
+		switch operation.OperationType {
+		case models.OperationTypeHold, models.OperationTypeCapture, models.OperationTypeRelease:
+			updatedWallet, updatedHold, updatedOperation, err = s.processHoldOperation(
+				operation, existingOp, *wallets[walletID], holds[operation.ReferenceOperationID], now,
+			)
+		case models.OperationTypeReversal:
+			updatedWallet, updatedOperation, updatedOriginal, err = s.processReversal(
+				operation, existingOp, *wallets[walletID], wallets[operation.DestinationWalletID],
+				originals[operation.ReferenceOperationID], now,
+			)
+		default:
+			updatedWallet, updatedOperation, err = s.processSingleOperation(
+				operation, existingOp, *wallets[walletID], now,
+			)
+		}
 		if err != nil {
-			return 0, nil, fmt.Errorf("failed to process operation %s: %w", operation.OperationID, err)
+			return nil, fmt.Errorf("failed to process operation %s: %w", operation.OperationID, err)
+		}
+
+		// Комиссия списывается сверх суммы операции: если ее нечем покрыть,
+		// операция отклоняется целиком
+		var fee int64
+		feeWallet := s.lockedFeeWallet(operation, wallets)
+		if updatedOperation.Status == models.OperationStatusProcessed {
+			fee = s.feeFor(walletID, operation)
+		}
+		if fee > 0 {
+			if reason := checkFee(updatedWallet, feeWallet, fee); reason != "" {
+				updatedOperation = failOperation(existingOp, reason)
+				updatedHold, updatedOriginal = nil, nil
+			} else {
+				updatedOperation.Fee = fee
+			}
 		}
 
 		// Добавляем операцию в список для массового обновления
-		operationsToUpdate = append(operationsToUpdate, updatedOperation)
+		result.operations = append(result.operations, updatedOperation)
 
-		// Обновляем баланс для следующих операций
-		if updatedOperation.Status == models.OperationStatusProcessed {
-			currentBalance = newBalance
+		if updatedOperation.Status != models.OperationStatusProcessed {
+			continue
+		}
+
+		// Обновляем балансы и использование лимитов для следующих операций
+		*wallets[walletID] = updatedWallet
+		if countsToLimits(operation) {
+			limits.Record(withdrawLimits, operation.Amount)
+		}
+		if operation.OperationType == models.OperationTypeTransfer {
+			wallets[operation.DestinationWalletID].Balance += operation.Amount
 		}
+		if operation.CloseWallet {
+			// Следующие операции батча закрытого кошелька отклоняются
+			wallets[walletID].Status = models.WalletStatusClosed
+			result.closed = append(result.closed, walletID)
+		}
+		if updatedHold != nil {
+			holds[updatedHold.OperationID] = updatedHold
+			changedHolds[updatedHold.OperationID] = true
+		}
+		if updatedOriginal != nil {
+			// Возврат перевода списывает средства с получателя исходного перевода
+			if updatedOriginal.OperationType == models.OperationTypeTransfer {
+				wallets[*updatedOriginal.DestinationWalletID].Balance -= operation.Amount
+			}
+			originals[updatedOriginal.ID] = updatedOriginal
+			changedOriginals[updatedOriginal.ID] = true
+			result.entries = append(result.entries, reversalEntriesFor(updatedOperation, *updatedOriginal, wallets, now)...)
+			continue
+		}
+		result.entries = append(result.entries, ledgerEntriesFor(updatedOperation, wallets, now)...)
+
+		if updatedOperation.Fee > 0 {
+			wallets[walletID].Balance -= updatedOperation.Fee
+			feeWallet.Balance += updatedOperation.Fee
+			result.entries = append(result.entries, feeEntriesFor(updatedOperation, feeWallet.ID, wallets, now)...)
+		}
+	}
+
+	for holdID := range changedHolds {
+		result.holds = append(result.holds, *holds[holdID])
+	}
+	for operationID := range changedOriginals {
+		result.reversed = append(result.reversed, *originals[operationID])
+	}
+
+	// Не даем закоммитить батч, если журнал расходится с балансами
+	if err := validateLedger(initialBalances, wallets, result.entries); err != nil {
+		return nil, err
 	}
 
-	return currentBalance, operationsToUpdate, nil
+	return result, nil
 }
 
 // processSingleOperation обрабатывает одну операцию на основе существующей записи из БД
+// и возвращает состояние кошелька после нее
 func (s *WalletService) processSingleOperation(
 	operation models.KafkaMessage,
 	existingOperation models.WalletOperation,
-	currentBalance int64,
+	wallet models.Wallet,
 	now time.Time,
-) (int64, models.WalletOperation, error) {
+) (models.Wallet, models.WalletOperation, error) {
 
 	// Используем существующую операцию как основу
 	updatedOperation := existingOperation
 
-	var newBalance int64
 	var status string
 	var errorMsg *string
 
 	switch operation.OperationType {
 	case models.OperationTypeDeposit:
-		newBalance = currentBalance + operation.Amount
+		wallet.Balance += operation.Amount
 		status = models.OperationStatusProcessed
 		processedAt := now
 		updatedOperation.ProcessedAt = &processedAt
 
-	case models.OperationTypeWithdraw:
-		if currentBalance >= operation.Amount {
-			newBalance = currentBalance - operation.Amount
+	case models.OperationTypeWithdraw, models.OperationTypeTransfer:
+		// Для перевода здесь списывается только сторона отправителя,
+		// зачисление получателю выполняет processOperationsInTx.
+		// Средства под холдами недоступны для списания, кредитный лимит
+		// позволяет уйти в минус: balance - held - amount >= -creditLimit.
+		if wallet.Headroom() >= operation.Amount {
+			wallet.Balance -= operation.Amount
 			status = models.OperationStatusProcessed
 			processedAt := now
 			updatedOperation.ProcessedAt = &processedAt
@@ -177,24 +425,156 @@ func (s *WalletService) processSingleOperation(
 			status = models.OperationStatusFailed
 			msg := "insufficient funds"
 			errorMsg = &msg
-			newBalance = currentBalance
 		}
 
 	default:
 		status = models.OperationStatusFailed
 		msg := fmt.Sprintf("unknown operation type: %s", operation.OperationType)
 		errorMsg = &msg
-		newBalance = currentBalance
 	}
 
 	updatedOperation.Status = status
 	updatedOperation.Error = errorMsg
 
-	return newBalance, updatedOperation, nil
+	return wallet, updatedOperation, nil
+}
+
+// loadWithdrawLimits возвращает лимиты на вывод кошелька с уже использованными суммами.
+// Если лимитов нет или в батче нет списаний, обращения к БД не происходит.
+func (s *WalletService) loadWithdrawLimits(
+	ctx context.Context,
+	txRepo *postgresrepo.TxWalletRepo,
+	wallet models.Wallet,
+	operations []models.KafkaMessage,
+	now time.Time,
+) ([]limits.Limit, error) {
+	withdrawLimits := s.limits.For(wallet)
+	if len(withdrawLimits) == 0 {
+		return nil, nil
+	}
+
+	counted := false
+	for _, op := range operations {
+		if countsToLimits(op) {
+			counted = true
+			break
+		}
+	}
+	if !counted {
+		return nil, nil
+	}
+
+	for i := range withdrawLimits {
+		used, err := txRepo.SumOperationsSince(ctx, wallet.ID, limits.CountedTypes(), now.Add(-withdrawLimits[i].Window))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s usage: %w", withdrawLimits[i].Name, err)
+		}
+		withdrawLimits[i].Used = used
+	}
+
+	return withdrawLimits, nil
+}
+
+// walletsToLock возвращает кошелек батча и всех получателей переводов без повторов.
+// Возврат перевода несет получателя исходного перевода, с которого списываются средства.
+func walletsToLock(walletID string, operations []models.KafkaMessage) []string {
+	seen := map[string]bool{walletID: true}
+	walletIDs := []string{walletID}
+
+	for _, op := range operations {
+		if op.OperationType != models.OperationTypeTransfer && op.OperationType != models.OperationTypeReversal ||
+			op.DestinationWalletID == "" {
+			continue
+		}
+		if !seen[op.DestinationWalletID] {
+			seen[op.DestinationWalletID] = true
+			walletIDs = append(walletIDs, op.DestinationWalletID)
+		}
+	}
+
+	return walletIDs
+}
+
+// checkCurrency возвращает причину отказа, если валюта операции не совпадает
+// с валютой кошелька или кошелька-получателя перевода
+func checkCurrency(operation models.KafkaMessage, wallets map[string]*models.Wallet) string {
+	// Сообщения, отправленные до появления валют, не содержат код и относятся к валюте кошелька
+	if operation.Currency == "" {
+		return ""
+	}
+
+	if wallet := wallets[operation.WalletID]; wallet != nil && wallet.Currency != operation.Currency {
+		return fmt.Sprintf("currency mismatch: operation in %s, wallet in %s", operation.Currency, wallet.Currency)
+	}
+
+	// Отсутствие кошелька-получателя проверяется при обработке самой операции
+	if operation.OperationType == models.OperationTypeTransfer || operation.OperationType == models.OperationTypeReversal {
+		if destination := wallets[operation.DestinationWalletID]; destination != nil && destination.Currency != operation.Currency {
+			return fmt.Sprintf("currency mismatch: operation in %s, destination wallet in %s", operation.Currency, destination.Currency)
+		}
+	}
+
+	return ""
+}
+
+// checkStatus возвращает причину отказа, если кошелек операции или кошелек,
+// который она затрагивает как получателя, заморожен или закрыт.
+// Баланс закрываемого кошелька выводится, пока он заморожен.
+func checkStatus(operation models.KafkaMessage, wallets map[string]*models.Wallet) string {
+	if wallet := wallets[operation.WalletID]; wallet != nil && wallet.Status != models.WalletStatusActive &&
+		!(operation.CloseWallet && wallet.Status == models.WalletStatusFrozen) {
+		return "wallet is " + strings.ToLower(wallet.Status)
+	}
+
+	// Отсутствие кошелька-получателя проверяется при обработке самой операции
+	if operation.OperationType == models.OperationTypeTransfer || operation.OperationType == models.OperationTypeReversal {
+		if destination := wallets[operation.DestinationWalletID]; destination != nil && destination.Status != models.WalletStatusActive {
+			return "destination wallet is " + strings.ToLower(destination.Status)
+		}
+	}
+
+	return ""
+}
+
+// checkSweep возвращает причину отказа, если перевод при закрытии кошелька выводит
+// не весь его баланс. Сервис кошельков замораживает кошелек вместе с созданием
+// перевода, так что расхождение — страховка, а не ожидаемый случай.
+func checkSweep(operation models.KafkaMessage, wallets map[string]*models.Wallet) string {
+	if !operation.CloseWallet {
+		return ""
+	}
+	if operation.OperationType != models.OperationTypeTransfer {
+		return "wallet can only be closed by a transfer"
+	}
+	if wallet := wallets[operation.WalletID]; wallet != nil && wallet.Balance != operation.Amount {
+		return "wallet balance changed before it was closed"
+	}
+	return ""
+}
+
+// countsToLimits сообщает, расходует ли операция лимиты на вывод.
+// Вывод баланса закрываемого кошелька лимитами не ограничен.
+func countsToLimits(operation models.KafkaMessage) bool {
+	return limits.Counts(operation.OperationType) && !operation.CloseWallet
+}
+
+// processOperation помечает операцию как PROCESSED
+func processOperation(operation models.WalletOperation, now time.Time) models.WalletOperation {
+	operation.Status = models.OperationStatusProcessed
+	operation.ProcessedAt = &now
+	operation.Error = nil
+	return operation
+}
+
+// failOperation помечает операцию как FAILED с указанной причиной
+func failOperation(operation models.WalletOperation, reason string) models.WalletOperation {
+	operation.Status = models.OperationStatusFailed
+	operation.Error = &reason
+	return operation
 }
 
-func (s *WalletService) updateCache(ctx context.Context, walletID string, balance int64) error {
-	if err := s.cacheRepo.SetBalance(ctx, walletID, balance); err != nil {
+func (s *WalletService) updateCache(ctx context.Context, wallet models.Wallet) error {
+	if err := s.cacheRepo.SetBalance(ctx, wallet.ID, wallet.Balance, wallet.HeldAmount); err != nil {
 		return fmt.Errorf("failed to update cache: %w", err)
 	}
 	return nil
@@ -28,7 +28,7 @@ func TestWalletService_processSingleOperation(t *testing.T) {
 		cacheRepo         *redisrepo.WalletRepository
 		operation         models.KafkaMessage
 		existingOperation models.WalletOperation
-		currentBalance    int64
+		wallet            models.Wallet
 		want              want
 	}{
 		{
@@ -49,7 +49,7 @@ func TestWalletService_processSingleOperation(t *testing.T) {
 				ProcessedAt:   nil,
 				Error:         nil,
 			},
-			currentBalance: 1000,
+			wallet: models.Wallet{Balance: 1000},
 			want: want{
 				newBalance:       1150,
 				status:           models.OperationStatusProcessed,
@@ -74,7 +74,7 @@ func TestWalletService_processSingleOperation(t *testing.T) {
 				Status:        models.OperationStatusPending,
 				CreatedAt:     now.Add(-time.Minute),
 			},
-			currentBalance: 1000,
+			wallet: models.Wallet{Balance: 1000},
 			want: want{
 				newBalance:       800,
 				status:           models.OperationStatusProcessed,
@@ -99,7 +99,7 @@ func TestWalletService_processSingleOperation(t *testing.T) {
 				Status:        models.OperationStatusPending,
 				CreatedAt:     now.Add(-time.Minute),
 			},
-			currentBalance: 1000,
+			wallet: models.Wallet{Balance: 1000},
 			want: want{
 				newBalance:       1000,
 				status:           models.OperationStatusFailed,
@@ -108,6 +108,135 @@ func TestWalletService_processSingleOperation(t *testing.T) {
 				preserveBaseData: true,
 			},
 		},
+		{
+			name: "transfer: debits source balance, marks processed, sets ProcessedAt",
+			operation: models.KafkaMessage{
+				OperationID:         "op-5",
+				WalletID:            "w-1",
+				DestinationWalletID: "w-2",
+				OperationType:       models.OperationTypeTransfer,
+				Amount:              300,
+			},
+			existingOperation: models.WalletOperation{
+				ID:                  "op-5",
+				WalletID:            "w-1",
+				DestinationWalletID: strptr("w-2"),
+				OperationType:       models.OperationTypeTransfer,
+				Amount:              300,
+				Status:              models.OperationStatusPending,
+				CreatedAt:           now.Add(-time.Minute),
+			},
+			wallet: models.Wallet{Balance: 1000},
+			want: want{
+				newBalance:       700,
+				status:           models.OperationStatusProcessed,
+				processedAtSet:   true,
+				errorMsg:         nil,
+				preserveBaseData: true,
+			},
+		},
+		{
+			name: "transfer: insufficient funds -> failed, keeps balance, no ProcessedAt, sets error",
+			operation: models.KafkaMessage{
+				OperationID:         "op-6",
+				WalletID:            "w-1",
+				DestinationWalletID: "w-2",
+				OperationType:       models.OperationTypeTransfer,
+				Amount:              1500,
+			},
+			existingOperation: models.WalletOperation{
+				ID:                  "op-6",
+				WalletID:            "w-1",
+				DestinationWalletID: strptr("w-2"),
+				OperationType:       models.OperationTypeTransfer,
+				Amount:              1500,
+				Status:              models.OperationStatusPending,
+				CreatedAt:           now.Add(-time.Minute),
+			},
+			wallet: models.Wallet{Balance: 1000},
+			want: want{
+				newBalance:       1000,
+				status:           models.OperationStatusFailed,
+				processedAtSet:   false,
+				errorMsg:         strptr("insufficient funds"),
+				preserveBaseData: true,
+			},
+		},
+		{
+			name: "withdraw: funds reserved by holds are not available -> failed, keeps balance",
+			operation: models.KafkaMessage{
+				OperationID:   "op-7",
+				WalletID:      "w-1",
+				OperationType: models.OperationTypeWithdraw,
+				Amount:        200,
+			},
+			existingOperation: models.WalletOperation{
+				ID:            "op-7",
+				WalletID:      "w-1",
+				OperationType: models.OperationTypeWithdraw,
+				Amount:        200,
+				Status:        models.OperationStatusPending,
+				CreatedAt:     now.Add(-time.Minute),
+			},
+			wallet: models.Wallet{ID: "w-1", Balance: 1000, HeldAmount: 900},
+			want: want{
+				newBalance:       1000,
+				status:           models.OperationStatusFailed,
+				processedAtSet:   false,
+				errorMsg:         strptr("insufficient funds"),
+				preserveBaseData: true,
+			},
+		},
+		{
+			name: "withdraw: credit limit allows a negative balance",
+			operation: models.KafkaMessage{
+				OperationID:   "op-8",
+				WalletID:      "w-1",
+				OperationType: models.OperationTypeWithdraw,
+				Amount:        1500,
+			},
+			existingOperation: models.WalletOperation{
+				ID:            "op-8",
+				WalletID:      "w-1",
+				OperationType: models.OperationTypeWithdraw,
+				Amount:        1500,
+				Status:        models.OperationStatusPending,
+				CreatedAt:     now.Add(-time.Minute),
+			},
+			wallet: models.Wallet{ID: "w-1", Balance: 1000, CreditLimit: 500},
+			want: want{
+				newBalance:       -500,
+				status:           models.OperationStatusProcessed,
+				processedAtSet:   true,
+				errorMsg:         nil,
+				preserveBaseData: true,
+			},
+		},
+		{
+			name: "withdraw: beyond the credit limit -> failed, keeps balance",
+			operation: models.KafkaMessage{
+				OperationID:   "op-9",
+				WalletID:      "w-1",
+				OperationType: models.OperationTypeWithdraw,
+				Amount:        600,
+			},
+			existingOperation: models.WalletOperation{
+				ID:            "op-9",
+				WalletID:      "w-1",
+				OperationType: models.OperationTypeWithdraw,
+				Amount:        600,
+				Status:        models.OperationStatusPending,
+				CreatedAt:     now.Add(-time.Minute),
+			},
+			wallet: models.Wallet{ID: "w-1", Balance: 300, HeldAmount: 200, CreditLimit: 400},
+			want: want{
+				newBalance:       300,
+				status:           models.OperationStatusFailed,
+				processedAtSet:   false,
+				errorMsg:         strptr("insufficient funds"),
+				preserveBaseData: true,
+			},
+		},
 		{
 			name: "unknown operation type -> failed, keeps balance, no ProcessedAt, sets error",
 			operation: models.KafkaMessage{
@@ -124,7 +253,7 @@ func TestWalletService_processSingleOperation(t *testing.T) {
 				Status:        models.OperationStatusPending,
 				CreatedAt:     now.Add(-time.Minute),
 			},
-			currentBalance: 3000,
+			wallet: models.Wallet{Balance: 3000},
 			want: want{
 				newBalance:       3000,
 				status:           models.OperationStatusFailed,
@@ -138,16 +267,16 @@ func TestWalletService_processSingleOperation(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			s := NewWalletService(tt.walletRepo, tt.cacheRepo)
+			s := NewWalletService(tt.walletRepo, tt.cacheRepo, nil, nil)
 
-			newBal, updated, err := s.processSingleOperation(tt.operation, tt.existingOperation, tt.currentBalance, now)
+			updatedWallet, updated, err := s.processSingleOperation(tt.operation, tt.existingOperation, tt.wallet, now)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
 			// 1) Проверяем баланс.
-			if newBal != tt.want.newBalance {
-				t.Fatalf("new balance: got %d, want %d", newBal, tt.want.newBalance)
+			if updatedWallet.Balance != tt.want.newBalance {
+				t.Fatalf("new balance: got %d, want %d", updatedWallet.Balance, tt.want.newBalance)
 			}
 
 			// 2) Проверяем статус.
@@ -201,3 +330,189 @@ func TestWalletService_processSingleOperation(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletsToLock(t *testing.T) {
+	operations := []models.KafkaMessage{
+		{OperationID: "op-1", WalletID: "w-1", OperationType: models.OperationTypeDeposit, Amount: 100},
+		{OperationID: "op-2", WalletID: "w-1", DestinationWalletID: "w-3", OperationType: models.OperationTypeTransfer, Amount: 50},
+		{OperationID: "op-3", WalletID: "w-1", DestinationWalletID: "w-2", OperationType: models.OperationTypeTransfer, Amount: 10},
+		{OperationID: "op-4", WalletID: "w-1", DestinationWalletID: "w-3", OperationType: models.OperationTypeTransfer, Amount: 20},
+	}
+
+	got := walletsToLock("w-1", operations)
+	want := []string{"w-1", "w-3", "w-2"}
+
+	if len(got) != len(want) {
+		t.Fatalf("wallets: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wallets: got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCheckCurrency(t *testing.T) {
+	wallets := map[string]*models.Wallet{
+		"w-1": {ID: "w-1", Currency: "USD"},
+		"w-2": {ID: "w-2", Currency: "USD"},
+		"w-3": {ID: "w-3", Currency: "EUR"},
+	}
+
+	tests := []struct {
+		name      string
+		operation models.KafkaMessage
+		want      string
+	}{
+		{
+			name:      "same currency",
+			operation: models.KafkaMessage{WalletID: "w-1", OperationType: models.OperationTypeDeposit, Currency: "USD"},
+			want:      "",
+		},
+		{
+			name:      "message without currency uses wallet currency",
+			operation: models.KafkaMessage{WalletID: "w-3", OperationType: models.OperationTypeWithdraw},
+			want:      "",
+		},
+		{
+			name:      "wallet currency mismatch",
+			operation: models.KafkaMessage{WalletID: "w-3", OperationType: models.OperationTypeDeposit, Currency: "USD"},
+			want:      "currency mismatch: operation in USD, wallet in EUR",
+		},
+		{
+			name:      "transfer to wallet in another currency",
+			operation: models.KafkaMessage{WalletID: "w-1", DestinationWalletID: "w-3", OperationType: models.OperationTypeTransfer, Currency: "USD"},
+			want:      "currency mismatch: operation in USD, destination wallet in EUR",
+		},
+		{
+			name:      "transfer in the same currency",
+			operation: models.KafkaMessage{WalletID: "w-1", DestinationWalletID: "w-2", OperationType: models.OperationTypeTransfer, Currency: "USD"},
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkCurrency(tt.operation, wallets); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckStatus(t *testing.T) {
+	wallets := map[string]*models.Wallet{
+		"w-1": {ID: "w-1", Status: models.WalletStatusActive},
+		"w-2": {ID: "w-2", Status: models.WalletStatusFrozen},
+		"w-3": {ID: "w-3", Status: models.WalletStatusClosed},
+	}
+
+	tests := []struct {
+		name      string
+		operation models.KafkaMessage
+		want      string
+	}{
+		{
+			name:      "active wallet",
+			operation: models.KafkaMessage{WalletID: "w-1", OperationType: models.OperationTypeWithdraw},
+			want:      "",
+		},
+		{
+			name:      "frozen wallet",
+			operation: models.KafkaMessage{WalletID: "w-2", OperationType: models.OperationTypeDeposit},
+			want:      "wallet is frozen",
+		},
+		{
+			name:      "closed wallet",
+			operation: models.KafkaMessage{WalletID: "w-3", OperationType: models.OperationTypeRelease},
+			want:      "wallet is closed",
+		},
+		{
+			name:      "transfer to a frozen wallet",
+			operation: models.KafkaMessage{WalletID: "w-1", DestinationWalletID: "w-2", OperationType: models.OperationTypeTransfer},
+			want:      "destination wallet is frozen",
+		},
+		{
+			// Возврат перевода списывает средства с получателя исходного перевода
+			name:      "reversal debiting a closed wallet",
+			operation: models.KafkaMessage{WalletID: "w-1", DestinationWalletID: "w-3", OperationType: models.OperationTypeReversal},
+			want:      "destination wallet is closed",
+		},
+		{
+			name:      "sweep of a frozen wallet",
+			operation: models.KafkaMessage{WalletID: "w-2", DestinationWalletID: "w-1", OperationType: models.OperationTypeTransfer, CloseWallet: true},
+			want:      "",
+		},
+		{
+			name:      "sweep of a closed wallet",
+			operation: models.KafkaMessage{WalletID: "w-3", DestinationWalletID: "w-1", OperationType: models.OperationTypeTransfer, CloseWallet: true},
+			want:      "wallet is closed",
+		},
+		{
+			name:      "sweep to a closed wallet",
+			operation: models.KafkaMessage{WalletID: "w-2", DestinationWalletID: "w-3", OperationType: models.OperationTypeTransfer, CloseWallet: true},
+			want:      "destination wallet is closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkStatus(tt.operation, wallets); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSweep(t *testing.T) {
+	wallets := map[string]*models.Wallet{
+		"w-1": {ID: "w-1", Balance: 1000, Status: models.WalletStatusFrozen},
+	}
+
+	tests := []struct {
+		name      string
+		operation models.KafkaMessage
+		want      string
+	}{
+		{
+			name:      "ordinary transfer",
+			operation: models.KafkaMessage{WalletID: "w-1", OperationType: models.OperationTypeTransfer, Amount: 10},
+			want:      "",
+		},
+		{
+			name:      "whole balance",
+			operation: models.KafkaMessage{WalletID: "w-1", OperationType: models.OperationTypeTransfer, Amount: 1000, CloseWallet: true},
+			want:      "",
+		},
+		{
+			name:      "balance changed",
+			operation: models.KafkaMessage{WalletID: "w-1", OperationType: models.OperationTypeTransfer, Amount: 900, CloseWallet: true},
+			want:      "wallet balance changed before it was closed",
+		},
+		{
+			name:      "not a transfer",
+			operation: models.KafkaMessage{WalletID: "w-1", OperationType: models.OperationTypeWithdraw, Amount: 1000, CloseWallet: true},
+			want:      "wallet can only be closed by a transfer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkSweep(tt.operation, wallets); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountsToLimits(t *testing.T) {
+	withdraw := models.KafkaMessage{OperationType: models.OperationTypeWithdraw}
+	if !countsToLimits(withdraw) {
+		t.Fatal("withdraw must count towards the limits")
+	}
+
+	sweep := models.KafkaMessage{OperationType: models.OperationTypeTransfer, CloseWallet: true}
+	if countsToLimits(sweep) {
+		t.Fatal("the sweep of a closed wallet must not count towards the limits")
+	}
+}
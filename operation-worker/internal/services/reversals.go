@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"operation-worker/internal/models"
+	"time"
+)
+
+// reversibleTypes — типы операций, которые можно отменить возвратом
+var reversibleTypes = map[string]bool{
+	models.OperationTypeDeposit:  true,
+	models.OperationTypeWithdraw: true,
+	models.OperationTypeTransfer: true,
+	models.OperationTypeCapture:  true,
+}
+
+// processReversal обрабатывает REVERSAL — полный или частичный возврат исходной операции.
+// Возвращает кошелек после возврата и исходную операцию с увеличенной возвращенной суммой.
+// Для возврата перевода destination — кошелек получателя исходного перевода: списание
+// с него выполняет processOperationsInTx, здесь проверяется только достаточность средств.
+func (s *WalletService) processReversal(
+	operation models.KafkaMessage,
+	existingOperation models.WalletOperation,
+	wallet models.Wallet,
+	destination *models.Wallet,
+	original *models.WalletOperation,
+	now time.Time,
+) (models.Wallet, models.WalletOperation, *models.WalletOperation, error) {
+
+	if original == nil {
+		return wallet, failOperation(existingOperation, "original operation not found"), nil, nil
+	}
+	if original.Status != models.OperationStatusProcessed {
+		return wallet, failOperation(existingOperation, "original operation is not processed"), nil, nil
+	}
+	if !reversibleTypes[original.OperationType] {
+		return wallet, failOperation(existingOperation,
+			fmt.Sprintf("operation type %s cannot be reversed", original.OperationType)), nil, nil
+	}
+	if operation.Amount > original.Amount-original.ReversedAmount {
+		return wallet, failOperation(existingOperation, "amount exceeds reversible amount"), nil, nil
+	}
+
+	switch original.OperationType {
+	case models.OperationTypeDeposit:
+		// Возврат пополнения списывает деньги в пределах доступного остатка и кредитного лимита
+		if wallet.Headroom() < operation.Amount {
+			return wallet, failOperation(existingOperation, "insufficient funds"), nil, nil
+		}
+		wallet.Balance -= operation.Amount
+
+	case models.OperationTypeWithdraw, models.OperationTypeCapture:
+		wallet.Balance += operation.Amount
+
+	case models.OperationTypeTransfer:
+		if destination == nil || original.DestinationWalletID == nil || destination.ID != *original.DestinationWalletID {
+			return wallet, failOperation(existingOperation, "destination wallet not found"), nil, nil
+		}
+		if destination.Headroom() < operation.Amount {
+			return wallet, failOperation(existingOperation, "insufficient funds in destination wallet"), nil, nil
+		}
+		wallet.Balance += operation.Amount
+	}
+
+	updatedOriginal := *original
+	updatedOriginal.ReversedAmount += operation.Amount
+
+	return wallet, processOperation(existingOperation, now), &updatedOriginal, nil
+}
+
+// reversalEntriesFor возвращает проводки возврата: это проводки исходной операции
+// на сумму возврата с противоположными направлениями
+func reversalEntriesFor(reversal, original models.WalletOperation, wallets map[string]*models.Wallet, now time.Time) []models.LedgerEntry {
+	mirrored := original
+	mirrored.ID = reversal.ID
+	mirrored.Amount = reversal.Amount
+
+	entries := ledgerEntriesFor(mirrored, wallets, now)
+	for i := range entries {
+		if entries[i].Direction == models.EntryDirectionDebit {
+			entries[i].Direction = models.EntryDirectionCredit
+		} else {
+			entries[i].Direction = models.EntryDirectionDebit
+		}
+	}
+
+	return entries
+}
+
+// reversedOperations возвращает ID операций, которые отменяются возвратами батча
+func reversedOperations(operations []models.KafkaMessage) []string {
+	operationIDs := make([]string, 0)
+	for _, op := range operations {
+		if op.OperationType == models.OperationTypeReversal && op.ReferenceOperationID != "" {
+			operationIDs = append(operationIDs, op.ReferenceOperationID)
+		}
+	}
+	return operationIDs
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"operation-worker/internal/models"
+)
+
+func TestWalletService_processReversal(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := NewWalletService(nil, nil, nil, nil)
+
+	original := func(opType string, amount, reversed int64) *models.WalletOperation {
+		op := &models.WalletOperation{
+			ID:             "op-1",
+			WalletID:       "w-1",
+			OperationType:  opType,
+			Amount:         amount,
+			ReversedAmount: reversed,
+			Status:         models.OperationStatusProcessed,
+		}
+		if opType == models.OperationTypeTransfer {
+			op.DestinationWalletID = strptr("w-2")
+		}
+		return op
+	}
+
+	tests := []struct {
+		name        string
+		amount      int64
+		wallet      models.Wallet
+		destination *models.Wallet
+		original    *models.WalletOperation
+		wantStatus  string
+		wantError   string
+		wantBalance int64
+		wantTotal   int64 // возвращенная сумма исходной операции после возврата
+	}{
+		{
+			name:        "partial deposit reversal",
+			amount:      300,
+			wallet:      models.Wallet{ID: "w-1", Balance: 1000},
+			original:    original(models.OperationTypeDeposit, 500, 0),
+			wantStatus:  models.OperationStatusProcessed,
+			wantBalance: 700,
+			wantTotal:   300,
+		},
+		{
+			name:        "withdraw reversal credits the wallet",
+			amount:      200,
+			wallet:      models.Wallet{ID: "w-1", Balance: 100},
+			original:    original(models.OperationTypeWithdraw, 500, 300),
+			wantStatus:  models.OperationStatusProcessed,
+			wantBalance: 300,
+			wantTotal:   500,
+		},
+		{
+			name:        "amount above the reversible remainder",
+			amount:      300,
+			wallet:      models.Wallet{ID: "w-1", Balance: 1000},
+			original:    original(models.OperationTypeDeposit, 500, 300),
+			wantStatus:  models.OperationStatusFailed,
+			wantError:   "amount exceeds reversible amount",
+			wantBalance: 1000,
+		},
+		{
+			name:        "deposit reversal cannot take held funds",
+			amount:      300,
+			wallet:      models.Wallet{ID: "w-1", Balance: 1000, HeldAmount: 800},
+			original:    original(models.OperationTypeDeposit, 500, 0),
+			wantStatus:  models.OperationStatusFailed,
+			wantError:   "insufficient funds",
+			wantBalance: 1000,
+		},
+		{
+			name:        "original is not processed",
+			amount:      100,
+			wallet:      models.Wallet{ID: "w-1", Balance: 1000},
+			original:    &models.WalletOperation{ID: "op-1", OperationType: models.OperationTypeDeposit, Amount: 500, Status: models.OperationStatusFailed},
+			wantStatus:  models.OperationStatusFailed,
+			wantError:   "original operation is not processed",
+			wantBalance: 1000,
+		},
+		{
+			name:        "hold cannot be reversed",
+			amount:      100,
+			wallet:      models.Wallet{ID: "w-1", Balance: 1000},
+			original:    original(models.OperationTypeHold, 500, 0),
+			wantStatus:  models.OperationStatusFailed,
+			wantError:   "operation type HOLD cannot be reversed",
+			wantBalance: 1000,
+		},
+		{
+			name:        "original not found",
+			amount:      100,
+			wallet:      models.Wallet{ID: "w-1", Balance: 1000},
+			wantStatus:  models.OperationStatusFailed,
+			wantError:   "original operation not found",
+			wantBalance: 1000,
+		},
+		{
+			name:        "transfer reversal returns funds from the destination",
+			amount:      400,
+			wallet:      models.Wallet{ID: "w-1", Balance: 100},
+			destination: &models.Wallet{ID: "w-2", Balance: 600},
+			original:    original(models.OperationTypeTransfer, 500, 0),
+			wantStatus:  models.OperationStatusProcessed,
+			wantBalance: 500,
+			wantTotal:   400,
+		},
+		{
+			name:        "transfer reversal with insufficient destination funds",
+			amount:      400,
+			wallet:      models.Wallet{ID: "w-1", Balance: 100},
+			destination: &models.Wallet{ID: "w-2", Balance: 300},
+			original:    original(models.OperationTypeTransfer, 500, 0),
+			wantStatus:  models.OperationStatusFailed,
+			wantError:   "insufficient funds in destination wallet",
+			wantBalance: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := models.KafkaMessage{
+				OperationID:          "rev-1",
+				WalletID:             "w-1",
+				ReferenceOperationID: "op-1",
+				OperationType:        models.OperationTypeReversal,
+				Amount:               tt.amount,
+			}
+			existing := models.WalletOperation{
+				ID:            "rev-1",
+				WalletID:      "w-1",
+				OperationType: models.OperationTypeReversal,
+				Amount:        tt.amount,
+				Status:        models.OperationStatusPending,
+			}
+
+			wallet, updated, updatedOriginal, err := s.processReversal(msg, existing, tt.wallet, tt.destination, tt.original, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if updated.Status != tt.wantStatus {
+				t.Fatalf("status: got %q, want %q", updated.Status, tt.wantStatus)
+			}
+			if tt.wantError != "" && (updated.Error == nil || *updated.Error != tt.wantError) {
+				t.Fatalf("error: got %v, want %q", updated.Error, tt.wantError)
+			}
+			if wallet.Balance != tt.wantBalance {
+				t.Fatalf("balance: got %d, want %d", wallet.Balance, tt.wantBalance)
+			}
+
+			// Возвращенная сумма меняется только у успешного возврата
+			if tt.wantStatus == models.OperationStatusFailed {
+				if updatedOriginal != nil {
+					t.Fatalf("failed reversal must not change the original: got %+v", updatedOriginal)
+				}
+				return
+			}
+			if updatedOriginal == nil || updatedOriginal.ReversedAmount != tt.wantTotal {
+				t.Fatalf("original: got %+v, want reversed amount %d", updatedOriginal, tt.wantTotal)
+			}
+			if tt.original.ReversedAmount == updatedOriginal.ReversedAmount {
+				t.Fatalf("original must not be modified in place")
+			}
+		})
+	}
+}
+
+func TestReversalEntriesFor(t *testing.T) {
+	now := time.Now()
+	wallets := map[string]*models.Wallet{"w-1": {ID: "w-1", Balance: 500}, "w-2": {ID: "w-2", Balance: 200}}
+
+	transfer := models.WalletOperation{
+		ID:                  "op-1",
+		WalletID:            "w-1",
+		DestinationWalletID: strptr("w-2"),
+		OperationType:       models.OperationTypeTransfer,
+		Amount:              1000,
+		Currency:            "USD",
+	}
+	reversal := models.WalletOperation{ID: "rev-1", WalletID: "w-1", OperationType: models.OperationTypeReversal, Amount: 400}
+
+	// Возврат перевода: зачисление отправителю и списание с получателя на сумму возврата
+	entries := reversalEntriesFor(reversal, transfer, wallets, now)
+	if len(entries) != 2 {
+		t.Fatalf("entries: got %d, want 2", len(entries))
+	}
+	if entries[0].OperationID != "rev-1" || *entries[0].WalletID != "w-1" ||
+		entries[0].Direction != models.EntryDirectionCredit || entries[0].Amount != 400 {
+		t.Fatalf("source entry: got %+v", entries[0])
+	}
+	if *entries[1].WalletID != "w-2" || entries[1].Direction != models.EntryDirectionDebit || *entries[1].BalanceAfter != 200 {
+		t.Fatalf("destination entry: got %+v", entries[1])
+	}
+
+	initial := map[string]int64{"w-1": 100, "w-2": 600}
+	if err := validateLedger(initial, wallets, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"operation-worker/internal/config"
+	"operation-worker/internal/services"
+	"time"
+)
+
+// Maximum number of wallets whose expired holds are released per tick
+const holdExpiryBatchSize = 100
+
+type HoldExpirer struct {
+	cfg           *config.Config
+	walletService *services.WalletService
+}
+
+func NewHoldExpirer(cfg *config.Config, walletService *services.WalletService) *HoldExpirer {
+	return &HoldExpirer{
+		cfg:           cfg,
+		walletService: walletService,
+	}
+}
+
+// Start periodically releases funds reserved by holds that are past their expiry.
+// It is safe to run on every worker replica: each wallet is expired under its row lock.
+func (e *HoldExpirer) Start(ctx context.Context) {
+	if e.cfg.Worker.HoldExpiryInterval <= 0 {
+		log.Println("Hold expiry is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(e.cfg.Worker.HoldExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			// Pages through the wallets in ID order, so wallets that fail every tick
+			// do not keep the ones after them from being expired
+			after := ""
+			for {
+				walletIDs, released, err := e.walletService.ExpireHolds(ctx, time.Now(), after, holdExpiryBatchSize)
+				if err != nil {
+					log.Printf("Failed to expire holds: %v", err)
+				}
+				if released > 0 {
+					log.Printf("Released expired holds of %d wallets", released)
+				}
+				// Continue while there may be more wallets left
+				if len(walletIDs) < holdExpiryBatchSize {
+					break
+				}
+				after = walletIDs[len(walletIDs)-1]
+			}
+		}
+	}
+}
@@ -15,9 +15,52 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/wallet": {
+        "/v1/admin/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists all API keys in creation order, revoked ones included. Keys themselves are never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Creates a new deposit or withdraw operation for a wallet",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an API key with scopes: wallets:read, wallets:write, operations:read, operations:write, webhooks\nand admin (every scope, on every wallet). With walletIds or ownerIds the key only reaches those wallets,\nor the wallets of those owners; such a key cannot have the webhooks or admin scope.\nThe key is only returned in this response, just its hash is stored. Send it in the X-API-Key header.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,54 +68,110 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "operations"
+                    "admin"
                 ],
-                "summary": "Create a wallet operation (deposit/withdraw)",
+                "summary": "Create an API key",
                 "parameters": [
                     {
-                        "description": "Operation Request",
-                        "name": "operation",
+                        "description": "API Key Request",
+                        "name": "apiKey",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.WalletOperationRequest"
+                            "$ref": "#/definitions/models.APIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.OperationCreateResponse"
+                            "$ref": "#/definitions/models.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/api-keys/{apiKeyId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID (UUIDv4)",
+                        "name": "apiKeyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/wallets": {
+        "/v1/admin/api-keys/{apiKeyId}/revoke": {
             "post": {
-                "description": "Creates a new wallet with an initial balance of 0",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes an API key for good, together with its key before the last rotation. The key stays listed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -80,29 +179,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "admin"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID (UUIDv4)",
+                        "name": "apiKeyId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Create a new wallet",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.WalletCreateResponse"
+                            "$ref": "#/definitions/models.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/wallets/{walletId}": {
-            "get": {
-                "description": "Retrieves the current balance of a wallet by its ID",
+        "/v1/admin/api-keys/{apiKeyId}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issues a new key for the API key, keeping its ID, scopes and restrictions. The new key is only returned\nin this response; the replaced key keeps working until previousKeyExpiresAt (API_KEY_ROTATION_GRACE).",
                 "consumes": [
                     "application/json"
                 ],
@@ -110,14 +237,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "admin"
                 ],
-                "summary": "Get wallet balance",
+                "summary": "Rotate an API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Wallet ID (UUIDv4)",
-                        "name": "walletId",
+                        "description": "API key ID (UUIDv4)",
+                        "name": "apiKeyId",
                         "in": "path",
                         "required": true
                     }
@@ -126,36 +253,47 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.WalletBalanceResponse"
+                            "$ref": "#/definitions/models.APIKeyResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/wallets/{walletId}/operations/{operationId}": {
-            "get": {
-                "description": "Retrieves the status of a specific operation for a wallet",
+        "/v1/admin/wallets/{walletId}/close": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Closes an ACTIVE or FROZEN wallet for good. The wallet must have no active holds and no debt.\nA positive balance requires sweepDestinationWalletId: the wallet is frozen and the balance is moved there\nby a TRANSFER (no fee, no limits, sweepOperationId) that the worker processes like any other and that closes\nthe wallet once posted. Until then the response is 202 with the FROZEN status; with wait (or \"Prefer: wait=\u003cseconds\u003e\")\nthe request blocks, up to 30s, for the sweep and returns 200 once the wallet is closed or 409 when the sweep failed.\nScheduled operations of the wallet are cancelled and its standing orders completed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -163,9 +301,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "operations"
+                    "admin"
                 ],
-                "summary": "Get operation status",
+                "summary": "Close a wallet",
                 "parameters": [
                     {
                         "type": "string",
@@ -176,135 +314,5644 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Operation ID (UUIDv4)",
-                        "name": "operationId",
-                        "in": "path",
-                        "required": true
+                        "description": "How long to wait for the sweep, e.g. 5s",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "wait=\u003cseconds\u003e, used when the wait parameter is absent",
+                        "name": "Prefer",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Close Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CloseWalletRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.OperationStatusResponse"
+                            "$ref": "#/definitions/models.WalletStatusResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.OperationCreateResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                },
-                "operationId": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                }
-            }
         },
-        "models.OperationStatusResponse": {
-            "type": "object",
-            "properties": {
-                "amount": {
-                    "type": "integer"
-                },
-                "error": {
-                    "type": "string"
-                },
-                "operationId": {
-                    "type": "string"
-                },
-                "operationType": {
-                    "type": "string"
-                },
-                "processedAt": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                },
-                "walletId": {
-                    "type": "string"
+        "/v1/admin/wallets/{walletId}/freeze": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Blocks new operations of an ACTIVE wallet. Operations already queued for it fail with \"wallet is frozen\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Freeze a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
                 }
             }
         },
-        "models.WalletBalanceResponse": {
-            "type": "object",
-            "properties": {
-                "balance": {
-                    "type": "integer"
+        "/v1/admin/wallets/{walletId}/unfreeze": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes a FROZEN wallet ACTIVE again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Unfreeze a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/webhooks/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the deliveries that used all their attempts, newest first, optionally of one webhook.\nPass nextCursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List dead webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUIDv4)",
+                        "name": "webhookId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveryListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/webhooks/dead-letters/{deliveryId}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues a dead delivery again with a fresh set of attempts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replay a dead webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookReplayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/webhooks/{webhookId}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues all dead deliveries of a webhook again with a fresh set of attempts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replay the dead deliveries of a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUIDv4)",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookReplayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/operations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the operations created with an externalRef, newest first, optionally only those of one wallet\n(including transfers to it). Pass nextCursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Search operations by external reference",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "External reference",
+                        "name": "externalRef",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/operations:batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Validates up to 1000 operations, creates them with a single insert and queues them with a single Kafka write.\nEvery operation is validated like POST /wallet and gets its own result in request order; idempotencyKey\nof an operation deduplicates it like the Idempotency-Key header of POST /wallet.\nWith atomic set no operation is created when any of them fails validation, and the response is 422.\nAtomicity covers validation only: an operation that was created but could not be queued is reported as failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Create a batch of wallet operations",
+                "parameters": [
+                    {
+                        "description": "Batch Request",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchOperationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchOperationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchOperationResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallet": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new deposit, withdraw, transfer, hold, capture or release operation for a wallet.\nA transfer moves funds to destinationWalletId: both legs are posted or neither is.\nA hold reserves funds until expiresAt; the reserved funds are excluded from availableBalance.\nCapture debits part or all of the hold identified by holdId, release frees it\n(a release without amount frees the whole remaining hold).\nWith executeAt a DEPOSIT, WITHDRAW or TRANSFER is stored as SCHEDULED and queued when it is due.\nA request repeated with the same Idempotency-Key returns the original operation without creating\na new one (marked by the Idempotent-Replayed header); the same key with a different request is rejected with 422.\nWith wait (or \"Prefer: wait=\u003cseconds\u003e\") the request blocks, up to 30s, until the worker processes or fails\nthe operation and returns 200 with its final status; when the wait elapses first it returns 202 as without it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Create a wallet operation (deposit/withdraw/transfer/hold/capture/release)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request per wallet, at most 255 characters",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait for the result, e.g. 5s",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "wait=\u003cseconds\u003e, used when the wait parameter is absent",
+                        "name": "Prefer",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Operation Request",
+                        "name": "operation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletOperationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationStatusResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists wallets in creation order, optionally of one owner and with the given labels.\nlabel is repeatable and all given labels must match: \"key=value\" matches the value, \"key\" only requires the label.\nPass nextCursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "List wallets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner ID",
+                        "name": "ownerId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label filter, key=value or key",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new wallet with an initial balance of 0.\nThe request body is optional; without it the wallet is created in USD.\nownerId and externalRef identify the wallet in the calling system: creating a wallet again with\nthe same pair returns the existing wallet with 200 instead of creating a duplicate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Create a new wallet",
+                "parameters": [
+                    {
+                        "description": "Wallet Request",
+                        "name": "wallet",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletCreateResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the current balance of a wallet by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Get wallet balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletBalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/credit-limit": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets how far below zero the wallet balance may go (in minor units, 0 disables overdraft).\nWithdrawals, transfers and holds are allowed while balance - held - amount \u003e= -creditLimit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Set wallet credit limit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Credit Limit Request",
+                        "name": "limit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreditLimitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletBalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream of the changes the operation worker commits for the wallet.\nEvent \"operation\" carries the new status of an operation of the wallet (transfers appear in both wallets),\nevent \"balance\" the balance of the wallet after the commit. The data is a models.WalletEvent.\nEvery event has an id; a reconnecting client sends the last one in Last-Event-ID to receive what it missed.\nThe last 1000 events of a wallet are kept for 24 hours.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Stream wallet events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/limits": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the daily (rolling 24h) and monthly (rolling 30 days) withdraw limits of a wallet\nand how much of them is used. Withdrawals, captures and outgoing transfers count against the limits.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Get wallet withdraw limits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletLimitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the withdraw limits of a wallet (in minor units).\nAn omitted limit falls back to the global default, 0 disables the limit for this wallet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Set wallet withdraw limits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Withdraw Limits Request",
+                        "name": "limits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WithdrawLimitsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletLimitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/operations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the operations of a wallet, including transfers to it, newest first.\nstatus and type are repeatable or comma-separated; amounts are in minor units and inclusive;\nfrom is inclusive and to exclusive (RFC 3339). Pass nextCursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "List the operations of a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "SCHEDULED, PENDING, PROCESSED, FAILED, CANCELLED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "DEPOSIT, WITHDRAW, TRANSFER, HOLD, CAPTURE, RELEASE, REVERSAL",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum amount",
+                        "name": "minAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum amount",
+                        "name": "maxAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/operations/{operationId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the status of a specific operation for a wallet.\nWith wait (or \"Prefer: wait=\u003cseconds\u003e\") the request blocks, up to 30s, until the operation is processed\nor failed; when the wait elapses first the current status is returned with 202.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Get operation status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation ID (UUIDv4)",
+                        "name": "operationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait for the final status, e.g. 5s",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "wait=\u003cseconds\u003e, used when the wait parameter is absent",
+                        "name": "Prefer",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationStatusResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/operations/{operationId}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancels an operation that is still SCHEDULED. Once the scheduler has queued it, it can no longer be cancelled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Cancel a scheduled operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation ID (UUIDv4)",
+                        "name": "operationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/operations/{operationId}/reversals": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a full or partial REVERSAL (refund) of a processed DEPOSIT, WITHDRAW, TRANSFER or CAPTURE.\nWithout amount everything that has not been reversed yet is reversed.\nA transfer is reversed from its source wallet: funds are returned from the destination wallet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Reverse an operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the operation to reverse (UUIDv4)",
+                        "name": "operationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reversal Request",
+                        "name": "reversal",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReversalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/standing-orders": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a recurring DEPOSIT, WITHDRAW or TRANSFER. schedule is \"@every \u003cduration\u003e\" (at least 1m),\n\"@hourly\", \"@daily\", \"@weekly\", \"@monthly\", \"@yearly\" or a cron expression \"minute hour day-of-month month day-of-week\" in UTC.\nEvery occurrence becomes a regular operation processed by the worker; the order completes after endAt or maxOccurrences.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Create a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Standing Order Request",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/standing-orders/{orderId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Get a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops a standing order for good. The order and its execution history remain readable with status DELETED.",
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Delete a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/standing-orders/{orderId}/operations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the operations created by a standing order, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Get the execution history of a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/standing-orders/{orderId}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops an ACTIVE standing order from firing until it is resumed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Pause a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallets/{walletId}/standing-orders/{orderId}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reactivates a PAUSED standing order. Occurrences that fell due while it was paused are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Resume a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Subscribes url to events of walletId, or of every wallet when walletId is omitted.\nEvent types: operation.processed, operation.failed and balance.updated. Deliveries are queued in the\ntransaction of the worker that commits the change and POSTed with a models.WebhookPayload body and the headers\nWebhook-Id, Webhook-Timestamp and Webhook-Signature: \"v1=\" + hex HMAC-SHA256 of \"\u003ctimestamp\u003e.\u003cbody\u003e\" keyed by the secret.\nA delivery that does not get a 2xx response is retried with exponential backoff and then dead-lettered.\nThe secret is generated when omitted and only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook Request",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{webhookId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUIDv4)",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops the deliveries of a webhook; its pending and dead deliveries are dropped.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUIDv4)",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists all API keys in creation order, revoked ones included. Keys themselves are never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an API key with scopes: wallets:read, wallets:write, operations:read, operations:write, webhooks\nand admin (every scope, on every wallet). With walletIds or ownerIds the key only reaches those wallets,\nor the wallets of those owners; such a key cannot have the webhooks or admin scope.\nThe key is only returned in this response, just its hash is stored. Send it in the X-API-Key header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "API Key Request",
+                        "name": "apiKey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/api-keys/{apiKeyId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID (UUIDv4)",
+                        "name": "apiKeyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/api-keys/{apiKeyId}/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes an API key for good, together with its key before the last rotation. The key stays listed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID (UUIDv4)",
+                        "name": "apiKeyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/api-keys/{apiKeyId}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issues a new key for the API key, keeping its ID, scopes and restrictions. The new key is only returned\nin this response; the replaced key keeps working until previousKeyExpiresAt (API_KEY_ROTATION_GRACE).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Rotate an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID (UUIDv4)",
+                        "name": "apiKeyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/wallets/{walletId}/close": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Closes an ACTIVE or FROZEN wallet for good. The wallet must have no active holds and no debt.\nA positive balance requires sweepDestinationWalletId: the wallet is frozen and the balance is moved there\nby a TRANSFER (no fee, no limits, sweepOperationId) that the worker processes like any other and that closes\nthe wallet once posted. Until then the response is 202 with the FROZEN status; with wait (or \"Prefer: wait=\u003cseconds\u003e\")\nthe request blocks, up to 30s, for the sweep and returns 200 once the wallet is closed or 409 when the sweep failed.\nScheduled operations of the wallet are cancelled and its standing orders completed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Close a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait for the sweep, e.g. 5s",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "wait=\u003cseconds\u003e, used when the wait parameter is absent",
+                        "name": "Prefer",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Close Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CloseWalletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/wallets/{walletId}/freeze": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Blocks new operations of an ACTIVE wallet. Operations already queued for it fail with \"wallet is frozen\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Freeze a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/wallets/{walletId}/unfreeze": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes a FROZEN wallet ACTIVE again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Unfreeze a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/webhooks/dead-letters": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the deliveries that used all their attempts, newest first, optionally of one webhook.\nPass nextCursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List dead webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUIDv4)",
+                        "name": "webhookId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveryListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/webhooks/dead-letters/{deliveryId}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues a dead delivery again with a fresh set of attempts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replay a dead webhook delivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookReplayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/admin/webhooks/{webhookId}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queues all dead deliveries of a webhook again with a fresh set of attempts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Replay the dead deliveries of a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUIDv4)",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookReplayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/operations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the operations created with an externalRef, newest first, optionally only those of one wallet\n(including transfers to it). Pass nextCursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Search operations by external reference",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "External reference",
+                        "name": "externalRef",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/operations:batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Validates up to 1000 operations, creates them with a single insert and queues them with a single Kafka write.\nEvery operation is validated like POST /wallet and gets its own result in request order; idempotencyKey\nof an operation deduplicates it like the Idempotency-Key header of POST /wallet.\nWith atomic set no operation is created when any of them fails validation, and the response is 422.\nAtomicity covers validation only: an operation that was created but could not be queued is reported as failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Create a batch of wallet operations",
+                "parameters": [
+                    {
+                        "description": "Batch Request",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchOperationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchOperationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchOperationResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists wallets in creation order, optionally of one owner and with the given labels.\nlabel is repeatable and all given labels must match: \"key=value\" matches the value, \"key\" only requires the label.\nPass nextCursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "List wallets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner ID",
+                        "name": "ownerId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label filter, key=value or key",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new wallet with an initial balance of 0.\nThe request body is optional; without it the wallet is created in USD.\nownerId and externalRef identify the wallet in the calling system: creating a wallet again with\nthe same pair returns the existing wallet with 200 instead of creating a duplicate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Create a new wallet",
+                "parameters": [
+                    {
+                        "description": "Wallet Request",
+                        "name": "wallet",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletCreateResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the current balance of a wallet by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Get wallet balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletBalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/credit-limit": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets how far below zero the wallet balance may go (in minor units, 0 disables overdraft).\nWithdrawals, transfers and holds are allowed while balance - held - amount \u003e= -creditLimit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Set wallet credit limit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Credit Limit Request",
+                        "name": "limit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreditLimitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletBalanceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream of the changes the operation worker commits for the wallet.\nEvent \"operation\" carries the new status of an operation of the wallet (transfers appear in both wallets),\nevent \"balance\" the balance of the wallet after the commit. The data is a models.WalletEvent.\nEvery event has an id; a reconnecting client sends the last one in Last-Event-ID to receive what it missed.\nThe last 1000 events of a wallet are kept for 24 hours.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Stream wallet events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/limits": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the daily (rolling 24h) and monthly (rolling 30 days) withdraw limits of a wallet\nand how much of them is used. Withdrawals, captures and outgoing transfers count against the limits.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Get wallet withdraw limits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletLimitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the withdraw limits of a wallet (in minor units).\nAn omitted limit falls back to the global default, 0 disables the limit for this wallet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Set wallet withdraw limits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Withdraw Limits Request",
+                        "name": "limits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WithdrawLimitsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletLimitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/operations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the operations of a wallet, including transfers to it, newest first.\nstatus and type are repeatable or comma-separated; amounts are in minor units and inclusive;\nfrom is inclusive and to exclusive (RFC 3339). Pass nextCursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "List the operations of a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "SCHEDULED, PENDING, PROCESSED, FAILED, CANCELLED",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "DEPOSIT, WITHDRAW, TRANSFER, HOLD, CAPTURE, RELEASE, REVERSAL",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum amount",
+                        "name": "minAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum amount",
+                        "name": "maxAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-200, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an operation like POST /v1/wallet on the wallet of the path; walletId may be omitted from the body\nand must match the path when given. The response points the Location header at the operation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Create an operation of a wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request per wallet, at most 255 characters",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait for the result, e.g. 5s",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "wait=\u003cseconds\u003e, used when the wait parameter is absent",
+                        "name": "Prefer",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Operation Request",
+                        "name": "operation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletOperationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/envelope.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.OperationStatusResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the operation"
+                            }
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/envelope.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.OperationCreateResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Path of the operation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/operations/{operationId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves the status of a specific operation for a wallet.\nWith wait (or \"Prefer: wait=\u003cseconds\u003e\") the request blocks, up to 30s, until the operation is processed\nor failed; when the wait elapses first the current status is returned with 202.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Get operation status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation ID (UUIDv4)",
+                        "name": "operationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait for the final status, e.g. 5s",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "wait=\u003cseconds\u003e, used when the wait parameter is absent",
+                        "name": "Prefer",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationStatusResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/operations/{operationId}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancels an operation that is still SCHEDULED. Once the scheduler has queued it, it can no longer be cancelled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Cancel a scheduled operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation ID (UUIDv4)",
+                        "name": "operationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/operations/{operationId}/reversals": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a full or partial REVERSAL (refund) of a processed DEPOSIT, WITHDRAW, TRANSFER or CAPTURE.\nWithout amount everything that has not been reversed yet is reversed.\nA transfer is reversed from its source wallet: funds are returned from the destination wallet.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operations"
+                ],
+                "summary": "Reverse an operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the operation to reverse (UUIDv4)",
+                        "name": "operationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reversal Request",
+                        "name": "reversal",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReversalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.OperationCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/standing-orders": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a recurring DEPOSIT, WITHDRAW or TRANSFER. schedule is \"@every \u003cduration\u003e\" (at least 1m),\n\"@hourly\", \"@daily\", \"@weekly\", \"@monthly\", \"@yearly\" or a cron expression \"minute hour day-of-month month day-of-week\" in UTC.\nEvery occurrence becomes a regular operation processed by the worker; the order completes after endAt or maxOccurrences.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Create a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Standing Order Request",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/standing-orders/{orderId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Get a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops a standing order for good. The order and its execution history remain readable with status DELETED.",
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Delete a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/standing-orders/{orderId}/operations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the operations created by a standing order, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Get the execution history of a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/standing-orders/{orderId}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops an ACTIVE standing order from firing until it is resumed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Pause a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/wallets/{walletId}/standing-orders/{orderId}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reactivates a PAUSED standing order. Occurrences that fell due while it was paused are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standing-orders"
+                ],
+                "summary": "Resume a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet ID (UUIDv4)",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Standing order ID (UUIDv4)",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StandingOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Subscribes url to events of walletId, or of every wallet when walletId is omitted.\nEvent types: operation.processed, operation.failed and balance.updated. Deliveries are queued in the\ntransaction of the worker that commits the change and POSTed with a models.WebhookPayload body and the headers\nWebhook-Id, Webhook-Timestamp and Webhook-Signature: \"v1=\" + hex HMAC-SHA256 of \"\u003ctimestamp\u003e.\u003cbody\u003e\" keyed by the secret.\nA delivery that does not get a 2xx response is retried with exponential backoff and then dead-lettered.\nThe secret is generated when omitted and only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook Request",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks/{webhookId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUIDv4)",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stops the deliveries of a webhook; its pending and dead deliveries are dropped.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUIDv4)",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "envelope.Envelope": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "links": {
+                    "$ref": "#/definitions/envelope.Links"
+                }
+            }
+        },
+        "envelope.Links": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "description": "the next page of a list, absent on the last one",
+                    "type": "string"
+                },
+                "self": {
+                    "description": "the resource, or the page of a list",
+                    "type": "string",
+                    "example": "/api/v2/wallets/6f1c0b1e-4a5d-4a3b-9a4e-2f1d6c7b8a90"
+                }
+            }
+        },
+        "models.APIKeyListResponse": {
+            "type": "object",
+            "properties": {
+                "apiKeys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.APIKeyResponse"
+                    }
+                }
+            }
+        },
+        "models.APIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expiresAt": {
+                    "description": "never expires when omitted",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "ownerIds": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "walletIds": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "apiKeyId": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "key": {
+                    "description": "only returned when the key is created or rotated",
+                    "type": "string"
+                },
+                "keyPrefix": {
+                    "description": "start of the key, to tell keys apart",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ownerIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "previousKeyExpiresAt": {
+                    "description": "the key before the last rotation works until then",
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "rotatedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "walletIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.BatchOperationRequest": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "type": "boolean"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WalletOperationRequest"
+                    }
+                }
+            }
+        },
+        "models.BatchOperationResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "rejected": {
+                    "description": "rejected or failed",
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchOperationResult"
+                    }
+                }
+            }
+        },
+        "models.BatchOperationResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "HTTP status the operation would get on its own",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errorCode": {
+                    "description": "problem code the operation would get on its own",
+                    "type": "string"
+                },
+                "errorId": {
+                    "description": "with INTERNAL_ERROR, also found in the logs",
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "operationId": {
+                    "type": "string"
+                },
+                "replayed": {
+                    "description": "the operation was created by an earlier request with the same idempotency key",
+                    "type": "boolean"
+                },
+                "status": {
+                    "description": "accepted, rejected (not created), failed (created but not queued)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CloseWalletRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "sweepDestinationWalletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreditLimitRequest": {
+            "type": "object",
+            "required": [
+                "creditLimit"
+            ],
+            "properties": {
+                "creditLimit": {
+                    "description": "in minor units, 0 disables overdraft",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.HoldResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "remainingAmount": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "ACTIVE, CAPTURED, RELEASED, EXPIRED",
+                    "type": "string"
+                }
+            }
+        },
+        "models.LimitResponse": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "description": "the limit comes from the global default",
+                    "type": "boolean"
+                },
+                "limit": {
+                    "description": "0 means no limit",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "daily withdraw, monthly withdraw",
+                    "type": "string"
+                },
+                "remaining": {
+                    "description": "0 when there is no limit",
+                    "type": "integer"
+                },
+                "used": {
+                    "description": "outflows processed within the window",
+                    "type": "integer"
+                },
+                "window": {
+                    "description": "length of the rolling window, e.g. \"24h0m0s\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OperationCreateResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "operationId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OperationLeg": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "direction": {
+                    "description": "DEBIT, CREDIT",
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OperationListResponse": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "description": "absent on the last page",
+                    "type": "string"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OperationStatusResponse"
+                    }
+                }
+            }
+        },
+        "models.OperationStatusResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "description": "ID of the API key that created the operation",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "destinationWalletId": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "errorCode": {
+                    "description": "problem code of the error of a FAILED operation",
+                    "type": "string",
+                    "example": "INSUFFICIENT_FUNDS"
+                },
+                "executeAt": {
+                    "description": "when a scheduled operation is published for processing",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "expiry of a HOLD",
+                    "type": "string"
+                },
+                "externalRef": {
+                    "type": "string"
+                },
+                "fee": {
+                    "description": "charged on top of the amount, set once the operation is processed",
+                    "type": "integer"
+                },
+                "formattedAmount": {
+                    "type": "string"
+                },
+                "formattedFee": {
+                    "type": "string"
+                },
+                "hold": {
+                    "description": "current state of a processed HOLD",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HoldResponse"
+                        }
+                    ]
+                },
+                "holdId": {
+                    "description": "hold captured or released by this operation",
+                    "type": "string"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OperationLeg"
+                    }
+                },
+                "metadata": {
+                    "type": "object"
+                },
+                "operationId": {
+                    "type": "string"
+                },
+                "operationType": {
+                    "type": "string"
+                },
+                "processedAt": {
+                    "type": "string"
+                },
+                "reversals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReversalResponse"
+                    }
+                },
+                "reversedAmount": {
+                    "description": "part of the amount already reversed",
+                    "type": "integer"
+                },
+                "reversedOperationId": {
+                    "description": "operation undone by a REVERSAL",
+                    "type": "string"
+                },
+                "standingOrderId": {
+                    "description": "standing order that created the operation",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReversalRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "defaults to the whole amount not reversed yet",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.ReversalResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "operationId": {
+                    "type": "string"
+                },
+                "processedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StandingOrderExecution": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fee": {
+                    "type": "integer"
+                },
+                "operationId": {
+                    "type": "string"
+                },
+                "processedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StandingOrderHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "executions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StandingOrderExecution"
+                    }
+                },
+                "standingOrderId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StandingOrderRequest": {
+            "type": "object",
+            "required": [
+                "operationType",
+                "schedule"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "defaults to the wallet currency",
+                    "type": "string"
+                },
+                "destinationWalletId": {
+                    "description": "required for TRANSFER",
+                    "type": "string"
+                },
+                "endAt": {
+                    "description": "no occurrence after this time",
+                    "type": "string"
+                },
+                "maxOccurrences": {
+                    "type": "integer"
+                },
+                "operationType": {
+                    "type": "string",
+                    "enum": [
+                        "DEPOSIT",
+                        "WITHDRAW",
+                        "TRANSFER"
+                    ]
+                },
+                "schedule": {
+                    "description": "\"@every 720h\", \"@monthly\" or a cron expression in UTC",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "startAt": {
+                    "description": "defaults to now",
+                    "type": "string"
+                }
+            }
+        },
+        "models.StandingOrderResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "description": "ID of the API key that created the order",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "destinationWalletId": {
+                    "type": "string"
+                },
+                "endAt": {
+                    "type": "string"
+                },
+                "formattedAmount": {
+                    "type": "string"
+                },
+                "maxOccurrences": {
+                    "type": "integer"
+                },
+                "nextRunAt": {
+                    "description": "absent once the order is completed or deleted",
+                    "type": "string"
+                },
+                "occurrences": {
+                    "description": "operations created so far",
+                    "type": "integer"
+                },
+                "operationType": {
+                    "type": "string"
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "standingOrderId": {
+                    "type": "string"
+                },
+                "startAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "ACTIVE, PAUSED, COMPLETED, DELETED",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WalletBalanceResponse": {
+            "type": "object",
+            "properties": {
+                "availableBalance": {
+                    "description": "balance minus funds reserved by holds",
+                    "type": "integer"
+                },
+                "balance": {
+                    "description": "in minor units",
+                    "type": "integer"
+                },
+                "creditLimit": {
+                    "description": "how far below zero the wallet may go",
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "formattedAvailableBalance": {
+                    "type": "string"
+                },
+                "formattedBalance": {
+                    "description": "decimal amount in major units, e.g. \"12.34\"",
+                    "type": "string"
+                },
+                "formattedHeadroom": {
+                    "type": "string"
+                },
+                "headroom": {
+                    "description": "amount that can still be debited: available balance plus credit limit",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "ACTIVE, FROZEN, CLOSED",
+                    "type": "string"
                 },
                 "walletId": {
                     "type": "string"
                 }
             }
         },
+        "models.WalletCreateRequest": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "defaults to USD",
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "externalRef": {
+                    "description": "the client's own ID of the wallet, requires ownerId",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "ownerId": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
         "models.WalletCreateResponse": {
             "type": "object",
             "properties": {
                 "balance": {
                     "type": "integer"
                 },
+                "currency": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "externalRef": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "message": {
                     "type": "string"
                 },
+                "ownerId": {
+                    "type": "string"
+                },
                 "status": {
+                    "description": "created, exists",
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WalletEvent": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "type": {
+                    "description": "operation, balance",
+                    "type": "string",
+                    "example": "balance"
+                },
+                "walletId": {
                     "type": "string"
+                }
+            }
+        },
+        "models.WalletLimitsResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "limits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LimitResponse"
+                    }
                 },
                 "walletId": {
                     "type": "string"
                 }
             }
         },
+        "models.WalletListResponse": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "description": "absent on the last page",
+                    "type": "string"
+                },
+                "wallets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WalletResponse"
+                    }
+                }
+            }
+        },
         "models.WalletOperationRequest": {
             "type": "object",
             "required": [
-                "amount",
                 "operationType",
                 "walletId"
             ],
             "properties": {
                 "amount": {
-                    "type": "integer"
+                    "description": "may be omitted only for RELEASE: the whole remaining hold is released",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "currency": {
+                    "description": "defaults to the wallet currency",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "destinationWalletId": {
+                    "description": "required for TRANSFER",
+                    "type": "string"
+                },
+                "executeAt": {
+                    "description": "schedules a DEPOSIT, WITHDRAW or TRANSFER for later execution",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "only for HOLD, defaults to now + HOLD_DEFAULT_TTL",
+                    "type": "string"
+                },
+                "externalRef": {
+                    "description": "the client's own reference, e.g. an order ID",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "holdId": {
+                    "description": "required for CAPTURE and RELEASE",
+                    "type": "string"
+                },
+                "idempotencyKey": {
+                    "description": "alternative to the Idempotency-Key header",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "metadata": {
+                    "description": "JSON object of at most 4 KB",
+                    "type": "object"
                 },
                 "operationType": {
                     "type": "string",
                     "enum": [
                         "DEPOSIT",
-                        "WITHDRAW"
+                        "WITHDRAW",
+                        "TRANSFER",
+                        "HOLD",
+                        "CAPTURE",
+                        "RELEASE"
                     ]
                 },
                 "walletId": {
                     "type": "string"
                 }
             }
+        },
+        "models.WalletResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "externalRef": {
+                    "type": "string"
+                },
+                "formattedBalance": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "ownerId": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "ACTIVE, FROZEN, CLOSED",
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WalletStatusRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "models.WalletStatusResponse": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "ACTIVE, FROZEN, CLOSED",
+                    "type": "string"
+                },
+                "statusChangedAt": {
+                    "type": "string"
+                },
+                "sweepOperationId": {
+                    "description": "transfer that moves the balance out of the wallet, which is closed once it is processed",
+                    "type": "string"
+                },
+                "sweptAmount": {
+                    "type": "integer"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDeliveryListResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookDeliveryResponse"
+                    }
+                },
+                "nextCursor": {
+                    "description": "absent on the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveryId": {
+                    "type": "integer"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lastStatusCode": {
+                    "description": "absent when the receiver did not respond",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "PENDING, DELIVERED, DEAD",
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                },
+                "webhookId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookListResponse": {
+            "type": "object",
+            "properties": {
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WebhookResponse"
+                    }
+                }
+            }
+        },
+        "models.WebhookReplayResponse": {
+            "type": "object",
+            "properties": {
+                "replayed": {
+                    "description": "dead deliveries queued again",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WebhookRequest": {
+            "type": "object",
+            "required": [
+                "eventTypes",
+                "url"
+            ],
+            "properties": {
+                "eventTypes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "generated when empty",
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 16
+                },
+                "url": {
+                    "description": "http or https",
+                    "type": "string",
+                    "maxLength": 2048
+                },
+                "walletId": {
+                    "description": "all wallets when empty",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "only returned when the webhook is created",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                },
+                "webhookId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WithdrawLimitsRequest": {
+            "type": "object",
+            "properties": {
+                "dailyWithdraw": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "monthlyWithdraw": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "problem.Code": {
+            "type": "string",
+            "enum": [
+                "MALFORMED_REQUEST",
+                "VALIDATION_FAILED",
+                "UNAUTHENTICATED",
+                "INSUFFICIENT_SCOPE",
+                "ACCESS_DENIED",
+                "OPERATION_NOT_ALLOWED",
+                "RATE_LIMITED",
+                "WALLET_NOT_FOUND",
+                "DESTINATION_WALLET_NOT_FOUND",
+                "OPERATION_NOT_FOUND",
+                "HOLD_NOT_FOUND",
+                "STANDING_ORDER_NOT_FOUND",
+                "WEBHOOK_NOT_FOUND",
+                "WEBHOOK_DELIVERY_NOT_FOUND",
+                "API_KEY_NOT_FOUND",
+                "API_KEY_REVOKED",
+                "IDEMPOTENCY_KEY_REUSED",
+                "BATCH_REJECTED",
+                "EXTERNAL_REF_CONFLICT",
+                "WALLET_FROZEN",
+                "WALLET_CLOSED",
+                "WALLET_NOT_FROZEN",
+                "WALLET_HAS_HOLDS",
+                "NEGATIVE_BALANCE",
+                "BALANCE_NOT_ZERO",
+                "SWEEP_FAILED",
+                "DESTINATION_WALLET_INACTIVE",
+                "CURRENCY_MISMATCH",
+                "HOLD_NOT_ACTIVE",
+                "AMOUNT_EXCEEDS_HOLD",
+                "OPERATION_NOT_CANCELLABLE",
+                "OPERATION_NOT_REVERSIBLE",
+                "AMOUNT_EXCEEDS_REVERSIBLE",
+                "STANDING_ORDER_NOT_ACTIVE",
+                "STANDING_ORDER_NOT_PAUSED",
+                "DELIVERY_NOT_DEAD",
+                "INSUFFICIENT_FUNDS",
+                "LIMIT_EXCEEDED",
+                "HOLD_EXPIRED",
+                "OPERATION_FAILED",
+                "INTERNAL_ERROR",
+                "SERVICE_UNAVAILABLE"
+            ],
+            "x-enum-comments": {
+                "APIKeyNotFound": "no API key with the ID",
+                "APIKeyRevoked": "the API key is revoked",
+                "AccessDenied": "the caller is restricted to other wallets or owners",
+                "AmountExceedsHold": "the amount is above the amount still held",
+                "AmountExceedsReversible": "the amount is above the amount not reversed yet",
+                "BalanceNotZero": "closing needs a zero balance or a sweep destination",
+                "BatchRejected": "the operation is valid, but another one of its atomic batch is not",
+                "CurrencyMismatch": "the currencies of the operation and its wallets differ",
+                "DeliveryNotDead": "only dead webhook deliveries can be replayed",
+                "DestinationWalletInactive": "the destination wallet is frozen or closed",
+                "DestinationWalletNotFound": "no destination or sweep destination wallet with the ID",
+                "ExternalRefConflict": "the external reference is used by a wallet in another currency",
+                "HoldExpired": "the operation failed: the hold expired",
+                "HoldNotActive": "the hold was captured, released or expired",
+                "HoldNotFound": "no hold with the ID on the wallet",
+                "IdempotencyKeyReused": "the idempotency key was used for a different request",
+                "InsufficientFunds": "the operation failed: the wallet cannot cover the amount or the fee",
+                "InsufficientScope": "the caller lacks the scope of the route",
+                "InternalError": "an unexpected error, reported with an error ID",
+                "LimitExceeded": "the operation failed: it exceeds a withdraw limit",
+                "MalformedRequest": "the body is not valid JSON",
+                "NegativeBalance": "a wallet with a negative balance cannot be closed",
+                "OperationFailed": "the operation failed for another reason, see its error",
+                "OperationNotAllowed": "the caller may not create operations of the type",
+                "OperationNotCancellable": "only scheduled operations can be cancelled",
+                "OperationNotFound": "no operation with the ID on the wallet",
+                "OperationNotReversible": "the operation is of a type or status that cannot be reversed",
+                "RateLimited": "the caller or the wallet is over its rate limit, see Retry-After",
+                "ServiceUnavailable": "a dependency is unavailable, retry later",
+                "StandingOrderNotActive": "only active standing orders can be paused",
+                "StandingOrderNotFound": "no standing order with the ID on the wallet",
+                "StandingOrderNotPaused": "only paused standing orders can be resumed",
+                "SweepFailed": "the transfer of the balance failed, the wallet stays frozen",
+                "Unauthenticated": "missing or invalid API key or bearer token",
+                "ValidationFailed": "fields of the request break rules, see errors",
+                "WalletClosed": "the wallet is closed",
+                "WalletFrozen": "the wallet is frozen",
+                "WalletHasHolds": "a wallet with active holds cannot be closed",
+                "WalletNotFound": "no wallet with the ID",
+                "WalletNotFrozen": "only a frozen wallet can be unfrozen",
+                "WebhookDeliveryNotFound": "no webhook delivery with the ID",
+                "WebhookNotFound": "no webhook with the ID"
+            },
+            "x-enum-descriptions": [
+                "the body is not valid JSON",
+                "fields of the request break rules, see errors",
+                "missing or invalid API key or bearer token",
+                "the caller lacks the scope of the route",
+                "the caller is restricted to other wallets or owners",
+                "the caller may not create operations of the type",
+                "the caller or the wallet is over its rate limit, see Retry-After",
+                "no wallet with the ID",
+                "no destination or sweep destination wallet with the ID",
+                "no operation with the ID on the wallet",
+                "no hold with the ID on the wallet",
+                "no standing order with the ID on the wallet",
+                "no webhook with the ID",
+                "no webhook delivery with the ID",
+                "no API key with the ID",
+                "the API key is revoked",
+                "the idempotency key was used for a different request",
+                "the operation is valid, but another one of its atomic batch is not",
+                "the external reference is used by a wallet in another currency",
+                "the wallet is frozen",
+                "the wallet is closed",
+                "only a frozen wallet can be unfrozen",
+                "a wallet with active holds cannot be closed",
+                "a wallet with a negative balance cannot be closed",
+                "closing needs a zero balance or a sweep destination",
+                "the transfer of the balance failed, the wallet stays frozen",
+                "the destination wallet is frozen or closed",
+                "the currencies of the operation and its wallets differ",
+                "the hold was captured, released or expired",
+                "the amount is above the amount still held",
+                "only scheduled operations can be cancelled",
+                "the operation is of a type or status that cannot be reversed",
+                "the amount is above the amount not reversed yet",
+                "only active standing orders can be paused",
+                "only paused standing orders can be resumed",
+                "only dead webhook deliveries can be replayed",
+                "the operation failed: the wallet cannot cover the amount or the fee",
+                "the operation failed: it exceeds a withdraw limit",
+                "the operation failed: the hold expired",
+                "the operation failed for another reason, see its error",
+                "an unexpected error, reported with an error ID",
+                "a dependency is unavailable, retry later"
+            ],
+            "x-enum-varnames": [
+                "MalformedRequest",
+                "ValidationFailed",
+                "Unauthenticated",
+                "InsufficientScope",
+                "AccessDenied",
+                "OperationNotAllowed",
+                "RateLimited",
+                "WalletNotFound",
+                "DestinationWalletNotFound",
+                "OperationNotFound",
+                "HoldNotFound",
+                "StandingOrderNotFound",
+                "WebhookNotFound",
+                "WebhookDeliveryNotFound",
+                "APIKeyNotFound",
+                "APIKeyRevoked",
+                "IdempotencyKeyReused",
+                "BatchRejected",
+                "ExternalRefConflict",
+                "WalletFrozen",
+                "WalletClosed",
+                "WalletNotFrozen",
+                "WalletHasHolds",
+                "NegativeBalance",
+                "BalanceNotZero",
+                "SweepFailed",
+                "DestinationWalletInactive",
+                "CurrencyMismatch",
+                "HoldNotActive",
+                "AmountExceedsHold",
+                "OperationNotCancellable",
+                "OperationNotReversible",
+                "AmountExceedsReversible",
+                "StandingOrderNotActive",
+                "StandingOrderNotPaused",
+                "DeliveryNotDead",
+                "InsufficientFunds",
+                "LimitExceeded",
+                "HoldExpired",
+                "OperationFailed",
+                "InternalError",
+                "ServiceUnavailable"
+            ]
+        },
+        "problem.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/problem.Code"
+                        }
+                    ],
+                    "example": "WALLET_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "Wallet not found"
+                },
+                "errorId": {
+                    "description": "with INTERNAL_ERROR, also found in the logs",
+                    "type": "string",
+                    "example": "1c9d0b7a-51f8-4f0e-9d5b-6c8a2e3f4b10"
+                },
+                "errors": {
+                    "description": "with VALIDATION_FAILED",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/wallets/6f1c0b1e-4a5d-4a3b-9a4e-2f1d6c7b8a90"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "validation.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "JSON name, with the path of nested fields",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "param": {
+                    "description": "parameter of the rule, e.g. the maximum of max",
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "validate tag or name of the check",
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Every route needs an API key with the scope of the route: 401 without a valid key,\n403 when the key lacks the scope or is restricted to other wallets, 429 with Retry-After\nwhen the caller or the wallet is over its rate limit (see the RateLimit-* headers).",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Alternatively a JWT of the edge gateway as \"Bearer \u003ctoken\u003e\". Its subject may only read\nand debit its own wallets, unless it holds the admin role.",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -313,10 +5960,10 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/api/v1",
+	BasePath:         "/api",
 	Schemes:          []string{"http"},
 	Title:            "Wallet API",
-	Description:      "This is a sample server for a wallet service.",
+	Description:      "This is a sample server for a wallet service.\nErrors are RFC 7807 problem details (application/problem+json) with a stable code,\nsee problem.Code for every code. Internal errors carry only an errorId, also found in the logs.\nEvery route is served under /api/v1 and /api/v2, except that v2 creates operations at\nPOST /v2/wallets/{walletId}/operations instead of POST /v1/wallet. v2 wraps the documented bodies in an\nenvelope.Envelope: {\"data\": <body>, \"links\": {\"self\", \"next\"}}, where data of a list is the array of its\nitems and links.next the next page; creations set Location; timestamps are ISO-8601 in UTC.\nv1 is deprecated: its responses carry Deprecation and Sunset headers.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
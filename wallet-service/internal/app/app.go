@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 	_ "wallet-service/docs"
@@ -9,24 +11,50 @@ import (
 	"wallet-service/internal/cache"
 	"wallet-service/internal/config"
 	"wallet-service/internal/database"
+	"wallet-service/internal/events"
 	"wallet-service/internal/repositories/kafkarepo"
 	"wallet-service/internal/repositories/postgresrepo"
 	"wallet-service/internal/repositories/redisrepo"
+	"wallet-service/internal/scheduler"
 	"wallet-service/internal/services"
+	"wallet-service/internal/transport/grpc/server"
 	"wallet-service/internal/transport/http/handler"
+
+	"google.golang.org/grpc"
 )
 
 type App struct {
 	cfg        *config.Config
 	httpServer *http.Server
+	grpcServer *grpc.Server // nil when GRPC_PORT is empty
+	scheduler  *scheduler.Scheduler
+	eventHub   *events.Hub
 }
 
 // @title Wallet API
 // @version 1.0
 // @description This is a sample server for a wallet service.
+// @description Errors are RFC 7807 problem details (application/problem+json) with a stable code,
+// @description see problem.Code for every code. Internal errors carry only an errorId, also found in the logs.
+// @description Every route is served under /api/v1 and /api/v2, except that v2 creates operations at
+// @description POST /v2/wallets/{walletId}/operations instead of POST /v1/wallet. v2 wraps the documented bodies in an
+// @description envelope.Envelope: {"data": <body>, "links": {"self", "next"}}, where data of a list is the array of its
+// @description items and links.next the next page; creations set Location; timestamps are ISO-8601 in UTC.
+// @description v1 is deprecated: its responses carry Deprecation and Sunset headers.
 // @host localhost:8080
-// @BasePath /api/v1
+// @BasePath /api
 // @schemes http
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Every route needs an API key with the scope of the route: 401 without a valid key,
+// @description 403 when the key lacks the scope or is restricted to other wallets, 429 with Retry-After
+// @description when the caller or the wallet is over its rate limit (see the RateLimit-* headers).
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Alternatively a JWT of the edge gateway as "Bearer <token>". Its subject may only read
+// @description and debit its own wallets, unless it holds the admin role.
 func New() (*App, error) {
 	a := new(App)
 
@@ -56,27 +84,63 @@ func New() (*App, error) {
 	redisRepo := redisrepo.NewWalletRepository(redis)
 	kafkaRepo := kafkarepo.NewOperationRepository(kafka)
 
+	// Initialize fan-out of the wallet events published by the worker
+	a.eventHub = events.NewHub(redisRepo)
+
 	// Initialize services
-	walletService := services.NewWalletService(postgresRepo, redisRepo, kafkaRepo)
+	walletService := services.NewWalletService(a.cfg, postgresRepo, redisRepo, kafkaRepo, a.eventHub)
+
+	// Initialize scheduler of future-dated operations
+	a.scheduler = scheduler.New(a.cfg, walletService)
 
 	// Initialize mux and handlers
 	mux := http.NewServeMux()
 
-	handler.NewWallet(mux, walletService)
+	walletHandler := handler.NewWallet(mux, walletService, a.cfg.API)
 
 	// Initialize http server
 	a.httpServer = &http.Server{
 		Addr:         a.cfg.Server.Port,
-		Handler:      mux,
+		Handler:      walletHandler.Deprecate(walletHandler.Authenticate(walletHandler.RateLimit(mux))),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  15 * time.Second,
 	}
 
+	// Initialize grpc server on its own port
+	if a.cfg.Server.GRPCPort != "" {
+		a.grpcServer = grpc.NewServer(
+			grpc.UnaryInterceptor(server.UnaryAuthenticator(walletService)),
+			grpc.StreamInterceptor(server.StreamAuthenticator(walletService)),
+		)
+		server.NewWallet(a.grpcServer, walletService)
+	}
+
 	return a, nil
 }
 
 func (a *App) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go a.scheduler.Start(ctx)
+	go a.eventHub.Run(ctx)
+
+	if a.grpcServer != nil {
+		lis, err := net.Listen("tcp", a.cfg.Server.GRPCPort)
+		if err != nil {
+			return fmt.Errorf("grpc listen error: %w", err)
+		}
+		defer a.grpcServer.Stop()
+
+		fmt.Printf("Starting gRPC server on port %s\n", a.cfg.Server.GRPCPort)
+		go func() {
+			if err := a.grpcServer.Serve(lis); err != nil {
+				fmt.Printf("gRPC server error: %v\n", err)
+			}
+		}()
+	}
+
 	fmt.Printf("Starting HTTP server on port %s\n", a.cfg.Server.Port)
 	if err := a.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("http server error: %w", err)
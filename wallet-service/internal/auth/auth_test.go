@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPrincipalScopes(t *testing.T) {
+	reader := &Principal{Scopes: []string{ScopeWalletsRead}}
+	if !reader.HasScope(ScopeWalletsRead) {
+		t.Error("wallets:read key lacks wallets:read")
+	}
+	if reader.HasScope(ScopeOperationsWrite) {
+		t.Error("wallets:read key has operations:write")
+	}
+
+	admin := &Principal{Scopes: []string{ScopeAdmin}}
+	for _, scope := range []string{ScopeWalletsRead, ScopeWalletsWrite, ScopeOperationsRead, ScopeOperationsWrite, ScopeWebhooks} {
+		if !admin.HasScope(scope) {
+			t.Errorf("admin key lacks %s", scope)
+		}
+	}
+}
+
+func TestPrincipalRestrictions(t *testing.T) {
+	owner := "owner-1"
+	other := "owner-2"
+
+	tests := []struct {
+		name      string
+		principal Principal
+		walletID  string
+		ownerID   *string
+		want      bool
+	}{
+		{"unrestricted", Principal{Scopes: []string{ScopeWalletsRead}}, "w1", nil, true},
+		{"listed wallet", Principal{Scopes: []string{ScopeWalletsRead}, WalletIDs: []string{"w1"}}, "w1", nil, true},
+		{"other wallet", Principal{Scopes: []string{ScopeWalletsRead}, WalletIDs: []string{"w1"}}, "w2", nil, false},
+		{"listed owner", Principal{Scopes: []string{ScopeWalletsRead}, OwnerIDs: []string{owner}}, "w2", &owner, true},
+		{"other owner", Principal{Scopes: []string{ScopeWalletsRead}, OwnerIDs: []string{owner}}, "w2", &other, false},
+		{"no owner", Principal{Scopes: []string{ScopeWalletsRead}, OwnerIDs: []string{owner}}, "w2", nil, false},
+		{"admin ignores restrictions", Principal{Scopes: []string{ScopeAdmin}, WalletIDs: []string{"w1"}}, "w2", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.AllowsWallet(tt.walletID, tt.ownerID); got != tt.want {
+				t.Errorf("AllowsWallet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	restricted := &Principal{Scopes: []string{ScopeWalletsWrite}, OwnerIDs: []string{owner}}
+	if !restricted.AllowsOwner(owner) || restricted.AllowsOwner(other) {
+		t.Error("AllowsOwner does not follow OwnerIDs")
+	}
+	walletOnly := &Principal{Scopes: []string{ScopeWalletsWrite}, WalletIDs: []string{"w1"}}
+	if walletOnly.AllowsOwner(owner) {
+		t.Error("key restricted to wallets allows an owner")
+	}
+	if !walletOnly.AllowsOperation("DEPOSIT") || !walletOnly.AllowsOperation("REVERSAL") {
+		t.Error("key without operation types is restricted to some")
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if FromContext(ctx) != nil || CallerID(ctx) != nil {
+		t.Fatal("empty context has a principal")
+	}
+
+	ctx = WithPrincipal(ctx, &Principal{ID: "key-1"})
+	if id := CallerID(ctx); id == nil || *id != "key-1" {
+		t.Errorf("CallerID() = %v, want key-1", id)
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	key, prefix, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "wk_") || len(key) != 67 {
+		t.Errorf("unexpected key format %q", key)
+	}
+	if !strings.HasPrefix(key, prefix) || len(prefix) != 11 {
+		t.Errorf("prefix %q is not the start of the key", prefix)
+	}
+
+	other, _, _ := NewKey()
+	if other == key {
+		t.Error("two keys are equal")
+	}
+	if HashKey(key) == HashKey(other) || len(HashKey(key)) != 64 {
+		t.Error("unexpected key hash")
+	}
+}
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// minReload limits how often an unknown key ID reloads the key set,
+	// so tokens with made-up key IDs cannot hammer the JWKS source
+	minReload = 30 * time.Second
+	// maxJWKSSize bounds the key set read from the source
+	maxJWKSSize = 1 << 20
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySet is a JSON Web Key Set read from a file or an http(s) URL. It is cached
+// and read again once it is older than the refresh interval, or earlier when a
+// token names a key it does not hold, so keys rotated at the source are picked up.
+// When the source cannot be read, the keys read last stay in use.
+//
+// Lookups never wait for a refresh: the cached keys serve them while the key set is
+// read again in the background. Only a lookup of a key the cache lacks waits for the
+// read, which is shared by all lookups waiting at the same time.
+type KeySet struct {
+	source  string
+	refresh time.Duration
+	client  *http.Client
+	loads   singleflight.Group
+
+	mu       sync.RWMutex
+	keys     map[string]crypto.PublicKey
+	loadedAt time.Time
+}
+
+func NewKeySet(source string, refresh time.Duration) *KeySet {
+	return &KeySet{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Key returns the public key with the given key ID
+func (k *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok, loaded, age := k.lookup(kid)
+	switch {
+	case ok:
+		if k.refresh > 0 && age >= k.refresh {
+			k.loads.DoChan(loadKey, func() (any, error) {
+				return nil, k.load(context.Background())
+			})
+		}
+		return key, nil
+	case loaded && age < minReload:
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+
+	// The key set is missing or lacks the key: wait for it to be read again. The read
+	// is shared, so it must not end with the context of the request that started it.
+	result := <-k.loads.DoChan(loadKey, func() (any, error) {
+		return nil, k.load(context.WithoutCancel(ctx))
+	})
+	if result.Err != nil && !loaded {
+		return nil, result.Err
+	}
+
+	if key, ok, _, _ = k.lookup(kid); !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// loadKey is the key of the reads of the key set in loads
+const loadKey = "jwks"
+
+// lookup returns the cached key with the given ID, whether any key set was read
+// yet and how long ago the key set was read last
+func (k *KeySet) lookup(kid string) (crypto.PublicKey, bool, bool, time.Duration) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[kid]
+	return key, ok, k.keys != nil, time.Since(k.loadedAt)
+}
+
+// load reads the key set without holding the lock and swaps it in
+func (k *KeySet) load(ctx context.Context) error {
+	keys, err := k.fetch(ctx)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// A failed read is not retried before minReload either
+	k.loadedAt = time.Now()
+	if err != nil {
+		if k.keys != nil {
+			fmt.Printf("Failed to reload JWKS, keeping the previous keys: %v\n", err)
+		}
+		return err
+	}
+
+	k.keys = keys
+	return nil
+}
+
+func (k *KeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := k.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+
+	return ParseJWKS(data)
+}
+
+func (k *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(k.source, "http://") && !strings.HasPrefix(k.source, "https://") {
+		return os.ReadFile(k.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// jsonWebKey holds the members of RSA and EC public keys
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS returns the RSA and P-256 signing keys of a key set by key ID.
+// Encryption keys and other key types are skipped.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch jwk.Kty {
+		case "RSA":
+			key, err = rsaKey(jwk)
+		case "EC":
+			key, err = ecKey(jwk)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS holds no signing keys")
+	}
+
+	return keys, nil
+}
+
+func rsaKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	key := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}
+	if key.N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must have at least 2048 bits")
+	}
+
+	return key, nil
+}
+
+func ecKey(jwk jsonWebKey) (*ecdsa.PublicKey, error) {
+	if jwk.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y: %w", err)
+	}
+
+	key := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if !key.Curve.IsOnCurve(key.X, key.Y) {
+		return nil, errors.New("point is not on the curve")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"wallet-service/internal/models"
+)
+
+const (
+	// bearerPrefix starts the Authorization header of a request carrying a JWT
+	bearerPrefix = "Bearer "
+	// SubjectPrefix marks the principal IDs of token subjects, so they are told
+	// apart from API key IDs in the recorded creator of operations
+	SubjectPrefix = "user:"
+	// maxSubjectLength keeps the principal ID within the created_by column
+	maxSubjectLength = 100 - len(SubjectPrefix)
+	// leeway tolerates clock skew between the gateway and the service
+	leeway = 30 * time.Second
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// userScopes are granted to the subject of a token, on its own wallets only:
+// it may read them and their operations and debit them
+var userScopes = []string{ScopeWalletsRead, ScopeOperationsRead, ScopeOperationsWrite}
+
+// userOperationTypes are the operations the subject of a token may create: it may not
+// credit its wallets, reverse their operations or hold their funds
+var userOperationTypes = []string{models.OperationTypeWithdraw, models.OperationTypeTransfer}
+
+// Claims are the claims of a verified token used by the service
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	WalletIDs []string `json:"wallet_ids"` // wallets of the subject besides those it owns
+	Roles     []string `json:"roles"`
+}
+
+// audience is the aud claim, a single string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("aud must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+// BearerToken returns the token of a bearer Authorization header
+func BearerToken(header string) (string, bool) {
+	if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(bearerPrefix):]), true
+}
+
+// Principal maps the claims to the caller of a request. A subject holding the admin
+// role gets the admin scope; any other subject may only reach the wallets it owns
+// and those listed in its wallet_ids claim, and only withdraw or transfer from them.
+func (c *Claims) Principal(adminRole string) *Principal {
+	principal := &Principal{ID: SubjectPrefix + c.Subject}
+
+	if adminRole != "" && slices.Contains(c.Roles, adminRole) {
+		principal.Scopes = []string{ScopeAdmin}
+		return principal
+	}
+
+	principal.Scopes = userScopes
+	principal.OwnerIDs = []string{c.Subject}
+	principal.WalletIDs = c.WalletIDs
+	principal.OperationTypes = userOperationTypes
+	return principal
+}
+
+// TokenVerifier verifies RS256 and ES256 signed JWTs against a key set
+type TokenVerifier struct {
+	keys     *KeySet
+	issuer   string // checked against the iss claim unless empty
+	audience string // must be in the aud claim unless empty
+}
+
+func NewTokenVerifier(keys *KeySet, issuer, audience string) *TokenVerifier {
+	return &TokenVerifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+	}
+}
+
+// Verify checks the signature and the registered claims of a token and returns its claims.
+// Failures of the token itself wrap ErrInvalidToken; other errors mean the key set
+// could not be read.
+func (v *TokenVerifier) Verify(ctx context.Context, token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid header: %v", ErrInvalidToken, err)
+	}
+	// The algorithm is fixed by the key below, never chosen by the token
+	if header.Alg != "RS256" && header.Alg != "ES256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+
+	key, err := v.keys.Key(ctx, header.Kid)
+	if err != nil {
+		if errors.Is(err, ErrUnknownKey) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid signature encoding", ErrInvalidToken)
+	}
+	if !verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature) {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.checkClaims(&claims, now); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return &claims, nil
+}
+
+func (v *TokenVerifier) checkClaims(claims *Claims, now time.Time) error {
+	if claims.ExpiresAt == nil {
+		return errors.New("missing exp")
+	}
+	if now.Add(-leeway).After(numericDate(*claims.ExpiresAt)) {
+		return errors.New("token is expired")
+	}
+	if claims.NotBefore != nil && now.Add(leeway).Before(numericDate(*claims.NotBefore)) {
+		return errors.New("token is not valid yet")
+	}
+
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return errors.New("token is not meant for this service")
+	}
+
+	if claims.Subject == "" {
+		return errors.New("missing sub")
+	}
+	if len(claims.Subject) > maxSubjectLength {
+		return errors.New("sub is too long")
+	}
+
+	return nil
+}
+
+// verifySignature checks the signature of the signing input with a key of the algorithm
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) bool {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature) == nil
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		// JWS carries the raw r || s, not an ASN.1 signature
+		if !ok || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(ecKey, digest[:], r, s)
+	}
+
+	return false
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// numericDate converts seconds since the epoch, possibly fractional
+func numericDate(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
@@ -0,0 +1,361 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestVerifyToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeJWKS(t, filepath.Join(t.TempDir(), "jwks.json"), rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey))
+	verifier := NewTokenVerifier(NewKeySet(path, time.Hour), "https://gateway", "wallet-service")
+
+	now := time.Now()
+	claims := map[string]any{
+		"sub": "user-1",
+		"iss": "https://gateway",
+		"aud": []string{"other", "wallet-service"},
+		"exp": now.Add(time.Minute).Unix(),
+	}
+	with := func(key string, value any) map[string]any {
+		c := map[string]any{}
+		for k, v := range claims {
+			c[k] = v
+		}
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"RS256", signToken(t, "RS256", "rsa-1", rsaKey, claims), true},
+		{"ES256", signToken(t, "ES256", "ec-1", ecKey, claims), true},
+		{"single audience", signToken(t, "RS256", "rsa-1", rsaKey, with("aud", "wallet-service")), true},
+		{"expired within leeway", signToken(t, "RS256", "rsa-1", rsaKey, with("exp", now.Add(-10*time.Second).Unix())), true},
+		{"expired", signToken(t, "RS256", "rsa-1", rsaKey, with("exp", now.Add(-time.Minute).Unix())), false},
+		{"no expiry", signToken(t, "RS256", "rsa-1", rsaKey, with("exp", nil)), false},
+		{"not valid yet", signToken(t, "RS256", "rsa-1", rsaKey, with("nbf", now.Add(time.Minute).Unix())), false},
+		{"other issuer", signToken(t, "RS256", "rsa-1", rsaKey, with("iss", "https://other")), false},
+		{"other audience", signToken(t, "RS256", "rsa-1", rsaKey, with("aud", "other")), false},
+		{"no subject", signToken(t, "RS256", "rsa-1", rsaKey, with("sub", nil)), false},
+		{"key of another algorithm", signToken(t, "ES256", "rsa-1", ecKey, claims), false},
+		{"unknown key", signToken(t, "RS256", "rsa-2", rsaKey, claims), false},
+		{"unsigned", signToken(t, "none", "rsa-1", nil, claims), false},
+		{"malformed", "not-a-token", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifier.Verify(context.Background(), tt.token, now)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("Verify() error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got.Subject != "user-1" {
+				t.Errorf("Subject = %q, want user-1", got.Subject)
+			}
+		})
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		token := signToken(t, "RS256", "rsa-1", rsaKey, claims)
+		other := signToken(t, "RS256", "rsa-1", rsaKey, with("sub", "user-2"))
+		tampered := token[:len(token)-len(signatureOf(token))] + signatureOf(other)
+		if _, err := verifier.Verify(context.Background(), tampered, now); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+		}
+	})
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeJWKS(t, filepath.Join(t.TempDir(), "jwks.json"), ecJWK("old", &oldKey.PublicKey))
+	keys := NewKeySet(path, time.Hour)
+
+	if _, err := keys.Key(context.Background(), "old"); err != nil {
+		t.Fatalf("Key(old) error = %v", err)
+	}
+
+	// The source rotates to a new key, an unknown key ID reloads it once minReload passed
+	writeJWKS(t, path, ecJWK("new", &newKey.PublicKey))
+	if _, err := keys.Key(context.Background(), "new"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Key(new) before minReload error = %v, want ErrUnknownKey", err)
+	}
+	keys.loadedAt = keys.loadedAt.Add(-minReload)
+	if _, err := keys.Key(context.Background(), "new"); err != nil {
+		t.Fatalf("Key(new) error = %v", err)
+	}
+	if _, err := keys.Key(context.Background(), "old"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Key(old) after rotation error = %v, want ErrUnknownKey", err)
+	}
+
+	// A source that cannot be read keeps the previous keys
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	keys.loadedAt = keys.loadedAt.Add(-time.Hour)
+	if _, err := keys.Key(context.Background(), "new"); err != nil {
+		t.Errorf("Key(new) with missing source error = %v", err)
+	}
+}
+
+func TestKeySetReloadDoesNotBlockLookups(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(map[string]any{"keys": []map[string]string{ecJWK("a", &key.PublicKey)}})
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every read after the first hangs until released
+		if requests.Add(1) > 1 {
+			<-release
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	keys := NewKeySet(server.URL, time.Hour)
+	if _, err := keys.Key(context.Background(), "a"); err != nil {
+		t.Fatalf("Key(a) error = %v", err)
+	}
+
+	// The key set is stale and its source hangs: cached keys are still served at once
+	keys.mu.Lock()
+	keys.loadedAt = keys.loadedAt.Add(-time.Hour)
+	keys.mu.Unlock()
+
+	done := make(chan error)
+	go func() {
+		for i := 0; i < 10; i++ {
+			if _, err := keys.Key(context.Background(), "a"); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Key(a) during reload error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Key(a) waited for the reload")
+	}
+
+	// Unknown keys wait for the one shared reload
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keys.Key(context.Background(), "b")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	release <- struct{}{}
+	wg.Wait()
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("JWKS read %d times, want 2", got)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signing := ecJWK("sig", &ecKey.PublicKey)
+	encryption := ecJWK("enc", &ecKey.PublicKey)
+	encryption["use"] = "enc"
+
+	data, _ := json.Marshal(map[string]any{"keys": []any{signing, encryption, map[string]string{"kty": "oct", "kid": "hmac"}}})
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys["sig"] == nil {
+		t.Errorf("ParseJWKS() = %v, want only the signing key", keys)
+	}
+
+	offCurve := ecJWK("bad", &ecKey.PublicKey)
+	offCurve["y"] = offCurve["x"]
+	data, _ = json.Marshal(map[string]any{"keys": []any{offCurve}})
+	if _, err := ParseJWKS(data); err == nil {
+		t.Error("ParseJWKS() accepted a point off the curve")
+	}
+
+	if _, err := ParseJWKS([]byte(`{"keys":[]}`)); err == nil {
+		t.Error("ParseJWKS() accepted an empty key set")
+	}
+}
+
+func TestClaimsPrincipal(t *testing.T) {
+	user := (&Claims{Subject: "user-1", WalletIDs: []string{"w1"}, Roles: []string{"support"}}).Principal("admin")
+	if user.ID != "user:user-1" {
+		t.Errorf("ID = %q, want user:user-1", user.ID)
+	}
+	if !user.HasScope(ScopeOperationsWrite) || user.HasScope(ScopeWalletsWrite) || user.HasScope(ScopeWebhooks) {
+		t.Errorf("user scopes = %v", user.Scopes)
+	}
+	owner, other := "user-1", "user-2"
+	if !user.AllowsWallet("w2", &owner) || !user.AllowsWallet("w1", &other) || user.AllowsWallet("w2", &other) {
+		t.Error("user reaches wallets it does not own")
+	}
+	for _, operationType := range []string{"DEPOSIT", "HOLD", "CAPTURE", "RELEASE", "REVERSAL"} {
+		if user.AllowsOperation(operationType) {
+			t.Errorf("user may create %s operations", operationType)
+		}
+	}
+	if !user.AllowsOperation("WITHDRAW") || !user.AllowsOperation("TRANSFER") {
+		t.Error("user may not debit its wallets")
+	}
+
+	admin := (&Claims{Subject: "user-2", Roles: []string{"admin"}}).Principal("admin")
+	if admin.Restricted() || !admin.HasScope(ScopeWebhooks) {
+		t.Error("admin role does not bypass restrictions")
+	}
+	if !admin.AllowsOperation("DEPOSIT") || !admin.AllowsOperation("REVERSAL") {
+		t.Error("admin role does not bypass operation types")
+	}
+
+	noAdminRole := (&Claims{Subject: "user-3", Roles: []string{""}}).Principal("")
+	if !noAdminRole.Restricted() {
+		t.Error("empty admin role grants admin")
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		token  string
+		ok     bool
+	}{
+		{"Bearer abc.def.ghi", "abc.def.ghi", true},
+		{"bearer abc.def.ghi", "abc.def.ghi", true},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"Bearer", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		token, ok := BearerToken(tt.header)
+		if token != tt.token || ok != tt.ok {
+			t.Errorf("BearerToken(%q) = %q, %v, want %q, %v", tt.header, token, ok, tt.token, tt.ok)
+		}
+	}
+}
+
+func writeJWKS(t *testing.T, path string, keys ...map[string]string) string {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signatureOf(token string) string {
+	return token[strings.LastIndex(token, ".")+1:]
+}
@@ -4,17 +4,27 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Postgres PostgresConfig
-	Kafka    KafkaConfig
-	Redis    RedisConfig
+	Server      ServerConfig
+	Postgres    PostgresConfig
+	Kafka       KafkaConfig
+	Redis       RedisConfig
+	Hold        HoldConfig
+	Limits      LimitsConfig
+	Scheduler   SchedulerConfig
+	Idempotency IdempotencyConfig
+	Webhook     WebhookConfig
+	Auth        AuthConfig
+	RateLimit   RateLimitConfig
+	API         APIConfig
 }
 
 type ServerConfig struct {
-	Port string
+	Port     string
+	GRPCPort string // empty disables the gRPC server
 }
 
 type PostgresConfig struct {
@@ -33,10 +43,64 @@ type RedisConfig struct {
 	PoolSize int
 }
 
+type HoldConfig struct {
+	DefaultTTL time.Duration
+}
+
+type SchedulerConfig struct {
+	Interval time.Duration // 0 disables the scheduler on this replica
+}
+
+type IdempotencyConfig struct {
+	Retention time.Duration // how long an idempotency key is kept, 0 keeps keys forever
+}
+
+// WebhookConfig controls the delivery of webhooks by the scheduler
+type WebhookConfig struct {
+	Timeout     time.Duration // of a single delivery attempt
+	MaxAttempts int           // attempts before a delivery is dead-lettered
+	Backoff     time.Duration // delay before the first retry, doubled after every attempt
+	MaxBackoff  time.Duration
+}
+
+// AuthConfig controls API key and JWT authentication
+type AuthConfig struct {
+	BootstrapKey  string        // admin key accepted without being stored, to create the first keys; empty disables it
+	RotationGrace time.Duration // how long the replaced key keeps working after a rotation
+	JWKS          string        // file path or http(s) URL of the key set verifying JWTs; empty disables JWTs
+	JWKSRefresh   time.Duration // how often the key set is read again, 0 only on unknown key IDs
+	JWTIssuer     string        // expected iss claim, empty accepts any
+	JWTAudience   string        // expected aud claim, empty accepts any
+	JWTAdminRole  string        // role in the roles claim granting the admin scope, empty grants it to no token
+}
+
+// APIConfig controls the versions of the REST API
+type APIConfig struct {
+	V1Deprecation time.Time // when v1 was deprecated, sent in its Deprecation header; zero sends no deprecation headers
+	V1Sunset      time.Time // when v1 stops being served, sent in its Sunset header; zero sends none
+}
+
+// RateLimitConfig controls the token buckets limiting requests per second, shared
+// by the replicas in Redis
+type RateLimitConfig struct {
+	ClientRate  int64 // requests per second of an API key or token subject, 0 disables the limit
+	ClientBurst int64 // requests above the rate a client may send at once, 0 means the rate
+	WalletRate  int64 // requests per second naming a wallet, 0 disables the limit
+	WalletBurst int64
+	FailOpen    bool // let requests through while Redis is unavailable instead of rejecting them
+}
+
+// LimitsConfig holds the global default withdraw limits in minor units, 0 means no limit
+type LimitsConfig struct {
+	DailyWithdraw   int64
+	MonthlyWithdraw int64
+}
+
 func New() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: os.Getenv("SERVER_PORT"),
+			Port:     os.Getenv("SERVER_PORT"),
+			GRPCPort: os.Getenv("GRPC_PORT"),
 		},
 		Postgres: PostgresConfig{
 			URL: os.Getenv("POSTGRES_URL"),
@@ -57,5 +121,98 @@ func New() *Config {
 				return redisPoolSize
 			}(os.Getenv("REDIS_POOL_SIZE")),
 		},
+		Hold: HoldConfig{
+			DefaultTTL: func(ttl string) time.Duration {
+				holdTTL, _ := strconv.Atoi(ttl)
+				return time.Duration(holdTTL) * time.Second
+			}(os.Getenv("HOLD_DEFAULT_TTL")),
+		},
+		Limits: LimitsConfig{
+			DailyWithdraw: func(dw string) int64 {
+				dailyWithdraw, _ := strconv.ParseInt(dw, 10, 64)
+				return dailyWithdraw
+			}(os.Getenv("LIMIT_DAILY_WITHDRAW")),
+			MonthlyWithdraw: func(mw string) int64 {
+				monthlyWithdraw, _ := strconv.ParseInt(mw, 10, 64)
+				return monthlyWithdraw
+			}(os.Getenv("LIMIT_MONTHLY_WITHDRAW")),
+		},
+		Scheduler: SchedulerConfig{
+			Interval: func(si string) time.Duration {
+				schedulerInterval, _ := strconv.Atoi(si)
+				return time.Duration(schedulerInterval) * time.Millisecond
+			}(os.Getenv("SCHEDULER_INTERVAL")),
+		},
+		Idempotency: IdempotencyConfig{
+			Retention: func(ir string) time.Duration {
+				idempotencyRetention, _ := strconv.Atoi(ir)
+				return time.Duration(idempotencyRetention) * time.Second
+			}(os.Getenv("IDEMPOTENCY_KEY_RETENTION")),
+		},
+		Webhook: WebhookConfig{
+			Timeout: func(wt string) time.Duration {
+				webhookTimeout, _ := strconv.Atoi(wt)
+				return time.Duration(webhookTimeout) * time.Millisecond
+			}(os.Getenv("WEBHOOK_TIMEOUT")),
+			MaxAttempts: func(wa string) int {
+				webhookMaxAttempts, _ := strconv.Atoi(wa)
+				return webhookMaxAttempts
+			}(os.Getenv("WEBHOOK_MAX_ATTEMPTS")),
+			Backoff: func(wb string) time.Duration {
+				webhookBackoff, _ := strconv.Atoi(wb)
+				return time.Duration(webhookBackoff) * time.Millisecond
+			}(os.Getenv("WEBHOOK_BACKOFF")),
+			MaxBackoff: func(wb string) time.Duration {
+				webhookMaxBackoff, _ := strconv.Atoi(wb)
+				return time.Duration(webhookMaxBackoff) * time.Millisecond
+			}(os.Getenv("WEBHOOK_MAX_BACKOFF")),
+		},
+		Auth: AuthConfig{
+			BootstrapKey: os.Getenv("API_BOOTSTRAP_KEY"),
+			RotationGrace: func(rg string) time.Duration {
+				rotationGrace, _ := strconv.Atoi(rg)
+				return time.Duration(rotationGrace) * time.Second
+			}(os.Getenv("API_KEY_ROTATION_GRACE")),
+			JWKS: os.Getenv("JWT_JWKS"),
+			JWKSRefresh: func(jr string) time.Duration {
+				jwksRefresh, _ := strconv.Atoi(jr)
+				return time.Duration(jwksRefresh) * time.Second
+			}(os.Getenv("JWT_JWKS_REFRESH")),
+			JWTIssuer:    os.Getenv("JWT_ISSUER"),
+			JWTAudience:  os.Getenv("JWT_AUDIENCE"),
+			JWTAdminRole: os.Getenv("JWT_ADMIN_ROLE"),
+		},
+		RateLimit: RateLimitConfig{
+			ClientRate: func(cr string) int64 {
+				clientRate, _ := strconv.ParseInt(cr, 10, 64)
+				return clientRate
+			}(os.Getenv("RATE_LIMIT_CLIENT_RPS")),
+			ClientBurst: func(cb string) int64 {
+				clientBurst, _ := strconv.ParseInt(cb, 10, 64)
+				return clientBurst
+			}(os.Getenv("RATE_LIMIT_CLIENT_BURST")),
+			WalletRate: func(wr string) int64 {
+				walletRate, _ := strconv.ParseInt(wr, 10, 64)
+				return walletRate
+			}(os.Getenv("RATE_LIMIT_WALLET_RPS")),
+			WalletBurst: func(wb string) int64 {
+				walletBurst, _ := strconv.ParseInt(wb, 10, 64)
+				return walletBurst
+			}(os.Getenv("RATE_LIMIT_WALLET_BURST")),
+			FailOpen: func(fo string) bool {
+				failOpen, _ := strconv.ParseBool(fo)
+				return failOpen
+			}(os.Getenv("RATE_LIMIT_FAIL_OPEN")),
+		},
+		API: APIConfig{
+			V1Deprecation: func(d string) time.Time {
+				deprecation, _ := time.Parse(time.DateOnly, d)
+				return deprecation
+			}(os.Getenv("API_V1_DEPRECATION")),
+			V1Sunset: func(s string) time.Time {
+				sunset, _ := time.Parse(time.DateOnly, s)
+				return sunset
+			}(os.Getenv("API_V1_SUNSET")),
+		},
 	}
 }
@@ -0,0 +1,61 @@
+package currency
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Default is the currency of wallets created without an explicit currency
+const Default = "USD"
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+)
+
+// Currency describes an ISO 4217 currency. Amounts are always stored in minor
+// units, MinorUnits is the number of decimal digits between minor and major units.
+type Currency struct {
+	Code       string
+	MinorUnits int
+}
+
+// registry of currencies supported by the wallet system
+var registry = map[string]Currency{
+	"USD": {Code: "USD", MinorUnits: 2},
+	"EUR": {Code: "EUR", MinorUnits: 2},
+	"GBP": {Code: "GBP", MinorUnits: 2},
+	"CHF": {Code: "CHF", MinorUnits: 2},
+	"RUB": {Code: "RUB", MinorUnits: 2},
+	"JPY": {Code: "JPY", MinorUnits: 0},
+	"KWD": {Code: "KWD", MinorUnits: 3},
+}
+
+// Lookup returns a registered currency by its code
+func Lookup(code string) (Currency, error) {
+	c, ok := registry[code]
+	if !ok {
+		return Currency{}, ErrUnsupportedCurrency
+	}
+	return c, nil
+}
+
+// Format renders an amount in minor units as a decimal string, e.g. 12345 USD -> "123.45"
+func (c Currency) Format(amount int64) string {
+	digits := strconv.FormatInt(amount, 10)
+	sign := ""
+	if amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	if c.MinorUnits == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= c.MinorUnits {
+		digits = strings.Repeat("0", c.MinorUnits-len(digits)+1) + digits
+	}
+
+	split := len(digits) - c.MinorUnits
+	return sign + digits[:split] + "." + digits[split:]
+}
@@ -0,0 +1,35 @@
+package currency
+
+import "testing"
+
+func TestCurrency_Format(t *testing.T) {
+	tests := []struct {
+		code   string
+		amount int64
+		want   string
+	}{
+		{"USD", 12345, "123.45"},
+		{"USD", 5, "0.05"},
+		{"USD", 0, "0.00"},
+		{"USD", -250, "-2.50"},
+		{"JPY", 1500, "1500"},
+		{"KWD", 1, "0.001"},
+		{"KWD", -123456, "-123.456"},
+	}
+
+	for _, tt := range tests {
+		c, err := Lookup(tt.code)
+		if err != nil {
+			t.Fatalf("lookup %s: %v", tt.code, err)
+		}
+		if got := c.Format(tt.amount); got != tt.want {
+			t.Fatalf("format %d %s: got %q, want %q", tt.amount, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestLookup_Unsupported(t *testing.T) {
+	if _, err := Lookup("XYZ"); err != ErrUnsupportedCurrency {
+		t.Fatalf("got %v, want ErrUnsupportedCurrency", err)
+	}
+}
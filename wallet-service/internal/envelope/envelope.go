@@ -0,0 +1,79 @@
+// Package envelope wraps the responses of the v2 REST API. Every v2 body other than
+// a problem is an Envelope holding the resource, or the page of resources, in data.
+package envelope
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// Envelope is the body of a v2 response
+type Envelope struct {
+	Data  any   `json:"data"`
+	Links Links `json:"links"`
+}
+
+// Links of a v2 response
+type Links struct {
+	Self string `json:"self" example:"/api/v2/wallets/6f1c0b1e-4a5d-4a3b-9a4e-2f1d6c7b8a90"` // the resource, or the page of a list
+	Next string `json:"next,omitempty"`                                                      // the next page of a list, absent on the last one
+}
+
+// New wraps data in an envelope. The timestamps of data are converted to UTC, so they
+// are ISO-8601 with a Z offset whatever the time zone of the server or the database;
+// data passed by pointer is converted in place.
+func New(data any, links Links) Envelope {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Struct {
+		copied := reflect.New(v.Type())
+		copied.Elem().Set(v)
+		v, data = copied, copied.Interface()
+	}
+	toUTC(v)
+
+	return Envelope{Data: data, Links: links}
+}
+
+// NextLink returns the link of the page after the one at u, or "" on the last page
+func NextLink(u *url.URL, nextCursor string) string {
+	if nextCursor == "" {
+		return ""
+	}
+
+	query := u.Query()
+	query.Set("cursor", nextCursor)
+	return u.Path + "?" + query.Encode()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// toUTC converts every settable time.Time reachable from v to UTC
+func toUTC(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			toUTC(v.Elem())
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(v.Interface().(time.Time).UTC()))
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				toUTC(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		// Skips raw JSON and other byte slices
+		switch v.Type().Elem().Kind() {
+		case reflect.Pointer, reflect.Struct, reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				toUTC(v.Index(i))
+			}
+		}
+	}
+}
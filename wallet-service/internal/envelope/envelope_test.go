@@ -0,0 +1,73 @@
+package envelope
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type resource struct {
+	CreatedAt   time.Time  `json:"createdAt"`
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+	Children    []resource `json:"children,omitempty"`
+}
+
+func TestNewConvertsTimestampsToUTC(t *testing.T) {
+	zone := time.FixedZone("MSK", 3*60*60)
+	at := time.Date(2026, 10, 17, 12, 30, 0, 0, zone)
+	processedAt := at.Add(time.Minute)
+
+	body := resource{
+		CreatedAt:   at,
+		ProcessedAt: &processedAt,
+		Children:    []resource{{CreatedAt: at}},
+	}
+
+	data, err := json.Marshal(New(body, Links{Self: "/api/v2/resources/1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(data)
+	for _, want := range []string{
+		`"createdAt":"2026-10-17T09:30:00Z"`,
+		`"processedAt":"2026-10-17T09:31:00Z"`,
+		`"children":[{"createdAt":"2026-10-17T09:30:00Z"}]`,
+		`"links":{"self":"/api/v2/resources/1"}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("body %s lacks %s", got, want)
+		}
+	}
+	if strings.Contains(got, "+03:00") {
+		t.Errorf("body %s keeps the offset", got)
+	}
+
+	// A body passed by value is copied, not converted in place
+	if body.CreatedAt.Location() != zone {
+		t.Error("body passed by value was modified")
+	}
+}
+
+func TestNewSlice(t *testing.T) {
+	at := time.Date(2026, 10, 17, 12, 30, 0, 0, time.FixedZone("", -5*60*60))
+	items := []resource{{CreatedAt: at}}
+
+	data, _ := json.Marshal(New(items, Links{}))
+	if want := `{"data":[{"createdAt":"2026-10-17T17:30:00Z"}],"links":{"self":""}}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	u, _ := url.Parse("/api/v2/wallets?limit=10&label=tier%3Dgold&cursor=old")
+
+	if got, want := NextLink(u, "abc"), "/api/v2/wallets?cursor=abc&label=tier%3Dgold&limit=10"; got != want {
+		t.Errorf("NextLink = %q, want %q", got, want)
+	}
+	if got := NextLink(u, ""); got != "" {
+		t.Errorf("NextLink on the last page = %q", got)
+	}
+}
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"wallet-service/internal/models"
+	"wallet-service/internal/repositories/redisrepo"
+)
+
+const (
+	// bufferSize is the number of events a subscriber may fall behind before it is dropped
+	bufferSize = 64
+	// resubscribeDelay is the pause before subscribing again after a failure
+	resubscribeDelay = time.Second
+)
+
+// Hub fans the wallet events published by the operation worker out to the
+// subscribers of this replica. Every replica holds a single Redis subscription.
+type Hub struct {
+	redisRepo *redisrepo.WalletRepository
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan models.WalletEvent]struct{}
+}
+
+func NewHub(redisRepo *redisrepo.WalletRepository) *Hub {
+	return &Hub{
+		redisRepo:   redisRepo,
+		subscribers: make(map[string]map[chan models.WalletEvent]struct{}),
+	}
+}
+
+// Run receives wallet events until ctx is done, subscribing again after a failure
+func (h *Hub) Run(ctx context.Context) {
+	for {
+		if err := h.redisRepo.SubscribeEvents(ctx, h.dispatch); err != nil {
+			log.Printf("Wallet events subscription failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+// Subscribe returns the events of a wallet received from now on and a function
+// that ends the subscription. The channel is closed when the subscriber falls
+// too far behind; it should reconnect and resume from the last event it got.
+func (h *Hub) Subscribe(walletID string) (<-chan models.WalletEvent, func()) {
+	ch := make(chan models.WalletEvent, bufferSize)
+
+	h.mu.Lock()
+	if h.subscribers[walletID] == nil {
+		h.subscribers[walletID] = make(map[chan models.WalletEvent]struct{})
+	}
+	h.subscribers[walletID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(walletID, ch)
+	}
+}
+
+// dispatch sends an event to the subscribers of its wallet without blocking
+func (h *Hub) dispatch(event models.WalletEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[event.WalletID] {
+		select {
+		case ch <- event:
+		default:
+			h.remove(event.WalletID, ch)
+		}
+	}
+}
+
+// remove closes the channel of a subscriber once; h.mu must be held
+func (h *Hub) remove(walletID string, ch chan models.WalletEvent) {
+	subscribers := h.subscribers[walletID]
+	if _, ok := subscribers[ch]; !ok {
+		return
+	}
+
+	delete(subscribers, ch)
+	close(ch)
+	if len(subscribers) == 0 {
+		delete(h.subscribers, walletID)
+	}
+}
+
+// After reports whether the stream ID a ("<milliseconds>-<sequence>") comes after b
+func After(a, b string) bool {
+	aMillis, aSeq := parseID(a)
+	bMillis, bSeq := parseID(b)
+	if aMillis != bMillis {
+		return aMillis > bMillis
+	}
+	return aSeq > bSeq
+}
+
+// ValidID reports whether id is a stream ID
+func ValidID(id string) bool {
+	millis, seq, found := strings.Cut(id, "-")
+	if !found {
+		return false
+	}
+	if _, err := strconv.ParseUint(millis, 10, 64); err != nil {
+		return false
+	}
+	_, err := strconv.ParseUint(seq, 10, 64)
+	return err == nil
+}
+
+func parseID(id string) (uint64, uint64) {
+	millis, seq, _ := strings.Cut(id, "-")
+	m, _ := strconv.ParseUint(millis, 10, 64)
+	s, _ := strconv.ParseUint(seq, 10, 64)
+	return m, s
+}
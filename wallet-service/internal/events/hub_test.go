@@ -0,0 +1,86 @@
+package events
+
+import (
+	"testing"
+
+	"wallet-service/internal/models"
+)
+
+func TestHub_Dispatch(t *testing.T) {
+	h := NewHub(nil)
+
+	first, cancelFirst := h.Subscribe("w-1")
+	second, cancelSecond := h.Subscribe("w-2")
+	defer cancelSecond()
+
+	h.dispatch(models.WalletEvent{ID: "1-0", WalletID: "w-1"})
+
+	if event := <-first; event.ID != "1-0" {
+		t.Fatalf("first: got %q, want 1-0", event.ID)
+	}
+	select {
+	case event := <-second:
+		t.Fatalf("second got an event of another wallet: %+v", event)
+	default:
+	}
+
+	// Cancelling twice must not close the channel twice
+	cancelFirst()
+	cancelFirst()
+	if _, ok := <-first; ok {
+		t.Fatal("first: channel is open after cancel")
+	}
+}
+
+func TestHub_DropsSlowSubscriber(t *testing.T) {
+	h := NewHub(nil)
+
+	ch, cancel := h.Subscribe("w-1")
+	defer cancel()
+
+	for i := 0; i <= bufferSize; i++ {
+		h.dispatch(models.WalletEvent{WalletID: "w-1"})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	if received != bufferSize {
+		t.Fatalf("received %d events, want %d", received, bufferSize)
+	}
+	if len(h.subscribers) != 0 {
+		t.Fatalf("slow subscriber was not removed")
+	}
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1700000000001-0", "1700000000000-5", true},
+		{"1700000000000-10", "1700000000000-9", true},
+		{"1700000000000-9", "1700000000000-9", false},
+		{"999-0", "1000-0", false},
+	}
+
+	for _, tt := range tests {
+		if got := After(tt.a, tt.b); got != tt.want {
+			t.Errorf("After(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestValidID(t *testing.T) {
+	for _, id := range []string{"0-0", "1700000000000-12"} {
+		if !ValidID(id) {
+			t.Errorf("ValidID(%q) = false, want true", id)
+		}
+	}
+	for _, id := range []string{"", "abc", "1700000000000", "1-x", "-1", "1--1"} {
+		if ValidID(id) {
+			t.Errorf("ValidID(%q) = true, want false", id)
+		}
+	}
+}
@@ -1,23 +1,172 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type WalletOperationRequest struct {
-	WalletID      string `json:"walletId" validate:"required,uuid4"`
-	OperationType string `json:"operationType" validate:"required,oneof=DEPOSIT WITHDRAW"`
-	Amount        int64  `json:"amount" validate:"required,gt=0"`
+	WalletID            string          `json:"walletId" validate:"required,uuid4"`
+	OperationType       string          `json:"operationType" validate:"required,oneof=DEPOSIT WITHDRAW TRANSFER HOLD CAPTURE RELEASE"`
+	Amount              int64           `json:"amount" validate:"gte=0"`                                          // may be omitted only for RELEASE: the whole remaining hold is released
+	DestinationWalletID string          `json:"destinationWalletId,omitempty" validate:"omitempty,uuid4"`         // required for TRANSFER
+	HoldID              string          `json:"holdId,omitempty" validate:"omitempty,uuid4"`                      // required for CAPTURE and RELEASE
+	ExpiresAt           *time.Time      `json:"expiresAt,omitempty"`                                              // only for HOLD, defaults to now + HOLD_DEFAULT_TTL
+	ExecuteAt           *time.Time      `json:"executeAt,omitempty"`                                              // schedules a DEPOSIT, WITHDRAW or TRANSFER for later execution
+	Currency            string          `json:"currency,omitempty" validate:"omitempty,len=3"`                    // defaults to the wallet currency
+	IdempotencyKey      string          `json:"idempotencyKey,omitempty" validate:"omitempty,max=255,printascii"` // alternative to the Idempotency-Key header
+	Description         string          `json:"description,omitempty" validate:"omitempty,max=500"`
+	ExternalRef         string          `json:"externalRef,omitempty" validate:"omitempty,max=100"` // the client's own reference, e.g. an order ID
+	Metadata            json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`            // JSON object of at most 4 KB
+}
+
+// BatchOperationRequest submits many operations in one request. With atomic set
+// nothing is created when any operation fails validation.
+type BatchOperationRequest struct {
+	Operations []WalletOperationRequest `json:"operations"`
+	Atomic     bool                     `json:"atomic,omitempty"`
+}
+
+// ReversalRequest refunds all or part of a processed operation
+type ReversalRequest struct {
+	Amount int64 `json:"amount,omitempty" validate:"gte=0"` // defaults to the whole amount not reversed yet
+}
+
+type CreditLimitRequest struct {
+	CreditLimit *int64 `json:"creditLimit" validate:"required,gte=0"` // in minor units, 0 disables overdraft
+}
+
+// WithdrawLimitsRequest replaces the withdraw limits of a wallet.
+// An omitted limit falls back to the global default, 0 disables it.
+type WithdrawLimitsRequest struct {
+	DailyWithdraw   *int64 `json:"dailyWithdraw,omitempty" validate:"omitempty,gte=0"`
+	MonthlyWithdraw *int64 `json:"monthlyWithdraw,omitempty" validate:"omitempty,gte=0"`
+}
+
+// StandingOrderRequest creates a recurring DEPOSIT, WITHDRAW or TRANSFER
+type StandingOrderRequest struct {
+	OperationType       string     `json:"operationType" validate:"required,oneof=DEPOSIT WITHDRAW TRANSFER"`
+	Amount              int64      `json:"amount" validate:"gt=0"`
+	DestinationWalletID string     `json:"destinationWalletId,omitempty" validate:"omitempty,uuid4"` // required for TRANSFER
+	Currency            string     `json:"currency,omitempty" validate:"omitempty,len=3"`            // defaults to the wallet currency
+	Schedule            string     `json:"schedule" validate:"required,max=100"`                     // "@every 720h", "@monthly" or a cron expression in UTC
+	StartAt             *time.Time `json:"startAt,omitempty"`                                        // defaults to now
+	EndAt               *time.Time `json:"endAt,omitempty"`                                          // no occurrence after this time
+	MaxOccurrences      *int       `json:"maxOccurrences,omitempty" validate:"omitempty,gt=0"`
+}
+
+// WalletStatusRequest freezes or unfreezes a wallet
+type WalletStatusRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// CloseWalletRequest closes a wallet. A wallet with a positive balance can only
+// be closed by sweeping the balance to another wallet in the same currency.
+type CloseWalletRequest struct {
+	Reason                   string `json:"reason" validate:"required,max=500"`
+	SweepDestinationWalletID string `json:"sweepDestinationWalletId,omitempty" validate:"omitempty,uuid4"`
+}
+
+// WalletCreateRequest creates a wallet. A wallet created again with the same ownerId
+// and externalRef is not duplicated: the existing wallet is returned instead.
+type WalletCreateRequest struct {
+	Currency    string            `json:"currency,omitempty" validate:"omitempty,len=3"` // defaults to USD
+	OwnerID     string            `json:"ownerId,omitempty" validate:"omitempty,max=100"`
+	ExternalRef string            `json:"externalRef,omitempty" validate:"omitempty,max=100"` // the client's own ID of the wallet, requires ownerId
+	DisplayName string            `json:"displayName,omitempty" validate:"omitempty,max=200"`
+	Labels      map[string]string `json:"labels,omitempty" validate:"omitempty,max=20,dive,keys,min=1,max=63,endkeys,max=255"`
+}
+
+// WebhookRequest subscribes a URL to the events of one wallet or of all wallets
+type WebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048"` // http or https
+	EventTypes []string `json:"eventTypes" validate:"required,min=1,dive,oneof=operation.processed operation.failed balance.updated"`
+	WalletID   string   `json:"walletId,omitempty" validate:"omitempty,uuid4"`        // all wallets when empty
+	Secret     string   `json:"secret,omitempty" validate:"omitempty,min=16,max=255"` // generated when empty
+}
+
+// APIKeyRequest creates an API key. A key with walletIds or ownerIds only reaches those
+// wallets, or the wallets of those owners; such a key cannot have the webhooks or admin scope.
+type APIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=wallets:read wallets:write operations:read operations:write webhooks admin"`
+	WalletIDs []string   `json:"walletIds,omitempty" validate:"omitempty,max=100,dive,uuid4"`
+	OwnerIDs  []string   `json:"ownerIds,omitempty" validate:"omitempty,max=100,dive,min=1,max=100"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // never expires when omitted
 }
 
 type WalletBalanceResponse struct {
-	WalletID string `json:"walletId"`
-	Balance  int64  `json:"balance"`
+	WalletID                  string `json:"walletId"`
+	Balance                   int64  `json:"balance"`          // in minor units
+	AvailableBalance          int64  `json:"availableBalance"` // balance minus funds reserved by holds
+	CreditLimit               int64  `json:"creditLimit"`      // how far below zero the wallet may go
+	Headroom                  int64  `json:"headroom"`         // amount that can still be debited: available balance plus credit limit
+	Currency                  string `json:"currency"`
+	Status                    string `json:"status"`           // ACTIVE, FROZEN, CLOSED
+	FormattedBalance          string `json:"formattedBalance"` // decimal amount in major units, e.g. "12.34"
+	FormattedAvailableBalance string `json:"formattedAvailableBalance"`
+	FormattedHeadroom         string `json:"formattedHeadroom"`
 }
 
 type WalletCreateResponse struct {
-	WalletID string `json:"walletId"`
-	Balance  int64  `json:"balance"`
-	Status   string `json:"status"`
-	Message  string `json:"message"`
+	WalletID    string            `json:"walletId"`
+	Balance     int64             `json:"balance"`
+	Currency    string            `json:"currency"`
+	OwnerID     *string           `json:"ownerId,omitempty"`
+	ExternalRef *string           `json:"externalRef,omitempty"`
+	DisplayName *string           `json:"displayName,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Status      string            `json:"status"` // created, exists
+	Message     string            `json:"message"`
+}
+
+// WalletResponse is a wallet as listed by owner or labels
+type WalletResponse struct {
+	WalletID         string            `json:"walletId"`
+	OwnerID          *string           `json:"ownerId,omitempty"`
+	ExternalRef      *string           `json:"externalRef,omitempty"`
+	DisplayName      *string           `json:"displayName,omitempty"`
+	Labels           map[string]string `json:"labels"`
+	Balance          int64             `json:"balance"`
+	Currency         string            `json:"currency"`
+	FormattedBalance string            `json:"formattedBalance"`
+	Status           string            `json:"status"` // ACTIVE, FROZEN, CLOSED
+	CreatedAt        time.Time         `json:"createdAt"`
+}
+
+// WalletListResponse is a page of wallets in creation order
+type WalletListResponse struct {
+	Wallets    []WalletResponse `json:"wallets"`
+	NextCursor string           `json:"nextCursor,omitempty"` // absent on the last page
+}
+
+type WalletStatusResponse struct {
+	WalletID         string     `json:"walletId"`
+	Status           string     `json:"status"` // ACTIVE, FROZEN, CLOSED
+	Reason           *string    `json:"reason,omitempty"`
+	StatusChangedAt  *time.Time `json:"statusChangedAt,omitempty"`
+	SweepOperationID *string    `json:"sweepOperationId,omitempty"` // transfer that moves the balance out of the wallet, which is closed once it is processed
+	SweptAmount      int64      `json:"sweptAmount,omitempty"`
+}
+
+type WalletLimitsResponse struct {
+	WalletID string          `json:"walletId"`
+	Currency string          `json:"currency"`
+	Limits   []LimitResponse `json:"limits"`
+}
+
+// LimitResponse is the usage of a limit within its rolling window
+type LimitResponse struct {
+	Name      string `json:"name"`      // daily withdraw, monthly withdraw
+	Window    string `json:"window"`    // length of the rolling window, e.g. "24h0m0s"
+	Limit     int64  `json:"limit"`     // 0 means no limit
+	Used      int64  `json:"used"`      // outflows processed within the window
+	Remaining int64  `json:"remaining"` // 0 when there is no limit
+	Default   bool   `json:"default"`   // the limit comes from the global default
 }
 
 type OperationCreateResponse struct {
@@ -26,58 +175,475 @@ type OperationCreateResponse struct {
 	Message     string `json:"message"`
 }
 
+// BatchOperationResponse holds the result of every operation of a batch in request order
+type BatchOperationResponse struct {
+	Accepted int                    `json:"accepted"`
+	Rejected int                    `json:"rejected"` // rejected or failed
+	Results  []BatchOperationResult `json:"results"`
+}
+
+type BatchOperationResult struct {
+	Index       int    `json:"index"`
+	OperationID string `json:"operationId,omitempty"`
+	Status      string `json:"status"`              // accepted, rejected (not created), failed (created but not queued)
+	Replayed    bool   `json:"replayed,omitempty"`  // the operation was created by an earlier request with the same idempotency key
+	Code        int    `json:"code,omitempty"`      // HTTP status the operation would get on its own
+	ErrorCode   string `json:"errorCode,omitempty"` // problem code the operation would get on its own
+	Error       string `json:"error,omitempty"`
+	ErrorID     string `json:"errorId,omitempty"` // with INTERNAL_ERROR, also found in the logs
+}
+
 type OperationStatusResponse struct {
-	OperationID   string     `json:"operationId"`
-	WalletID      string     `json:"walletId"`
-	OperationType string     `json:"operationType"`
-	Amount        int64      `json:"amount"`
-	Status        string     `json:"status"`
-	ProcessedAt   *time.Time `json:"processedAt,omitempty"`
-	Error         *string    `json:"error,omitempty"`
+	OperationID         string             `json:"operationId"`
+	WalletID            string             `json:"walletId"`
+	DestinationWalletID *string            `json:"destinationWalletId,omitempty"`
+	OperationType       string             `json:"operationType"`
+	Amount              int64              `json:"amount"`
+	Currency            string             `json:"currency"`
+	FormattedAmount     string             `json:"formattedAmount"`
+	Fee                 int64              `json:"fee"` // charged on top of the amount, set once the operation is processed
+	FormattedFee        string             `json:"formattedFee"`
+	Status              string             `json:"status"`
+	ProcessedAt         *time.Time         `json:"processedAt,omitempty"`
+	ExecuteAt           *time.Time         `json:"executeAt,omitempty"` // when a scheduled operation is published for processing
+	Error               *string            `json:"error,omitempty"`
+	ErrorCode           *string            `json:"errorCode,omitempty" example:"INSUFFICIENT_FUNDS"` // problem code of the error of a FAILED operation
+	Legs                []OperationLeg     `json:"legs,omitempty"`
+	HoldID              *string            `json:"holdId,omitempty"`              // hold captured or released by this operation
+	ExpiresAt           *time.Time         `json:"expiresAt,omitempty"`           // expiry of a HOLD
+	Hold                *HoldResponse      `json:"hold,omitempty"`                // current state of a processed HOLD
+	ReversedOperationID *string            `json:"reversedOperationId,omitempty"` // operation undone by a REVERSAL
+	ReversedAmount      int64              `json:"reversedAmount,omitempty"`      // part of the amount already reversed
+	Reversals           []ReversalResponse `json:"reversals,omitempty"`
+	StandingOrderID     *string            `json:"standingOrderId,omitempty"` // standing order that created the operation
+	CreatedBy           *string            `json:"createdBy,omitempty"`       // ID of the API key that created the operation
+	Description         *string            `json:"description,omitempty"`
+	ExternalRef         *string            `json:"externalRef,omitempty"`
+	Metadata            json.RawMessage    `json:"metadata,omitempty" swaggertype:"object"`
+	CreatedAt           time.Time          `json:"createdAt"`
+}
+
+// OperationListResponse is a page of operations, newest first
+type OperationListResponse struct {
+	Operations []OperationStatusResponse `json:"operations"`
+	NextCursor string                    `json:"nextCursor,omitempty"` // absent on the last page
+}
+
+type StandingOrderResponse struct {
+	StandingOrderID     string     `json:"standingOrderId"`
+	WalletID            string     `json:"walletId"`
+	DestinationWalletID *string    `json:"destinationWalletId,omitempty"`
+	OperationType       string     `json:"operationType"`
+	Amount              int64      `json:"amount"`
+	Currency            string     `json:"currency"`
+	FormattedAmount     string     `json:"formattedAmount"`
+	Schedule            string     `json:"schedule"`
+	Status              string     `json:"status"` // ACTIVE, PAUSED, COMPLETED, DELETED
+	StartAt             time.Time  `json:"startAt"`
+	EndAt               *time.Time `json:"endAt,omitempty"`
+	MaxOccurrences      *int       `json:"maxOccurrences,omitempty"`
+	Occurrences         int        `json:"occurrences"`         // operations created so far
+	NextRunAt           *time.Time `json:"nextRunAt,omitempty"` // absent once the order is completed or deleted
+	CreatedBy           *string    `json:"createdBy,omitempty"` // ID of the API key that created the order
+	CreatedAt           time.Time  `json:"createdAt"`
+	UpdatedAt           time.Time  `json:"updatedAt"`
+}
+
+// StandingOrderHistoryResponse lists the operations created by a standing order, newest first
+type StandingOrderHistoryResponse struct {
+	StandingOrderID string                   `json:"standingOrderId"`
+	Executions      []StandingOrderExecution `json:"executions"`
+}
+
+type StandingOrderExecution struct {
+	OperationID string     `json:"operationId"`
+	Amount      int64      `json:"amount"`
+	Fee         int64      `json:"fee"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+}
+
+type ReversalResponse struct {
+	OperationID string     `json:"operationId"`
+	Amount      int64      `json:"amount"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+}
+
+type HoldResponse struct {
+	Amount          int64     `json:"amount"`
+	RemainingAmount int64     `json:"remainingAmount"`
+	Status          string    `json:"status"` // ACTIVE, CAPTURED, RELEASED, EXPIRED
+	ExpiresAt       time.Time `json:"expiresAt"`
+}
+
+// OperationLeg is one side of a transfer: the debit of the source wallet
+// or the credit of the destination wallet
+type OperationLeg struct {
+	WalletID  string `json:"walletId"`
+	Direction string `json:"direction"` // DEBIT, CREDIT
+	Amount    int64  `json:"amount"`
+}
+
+type WebhookResponse struct {
+	WebhookID  string    `json:"webhookId"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	WalletID   *string   `json:"walletId,omitempty"`
+	Secret     string    `json:"secret,omitempty"` // only returned when the webhook is created
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type APIKeyResponse struct {
+	APIKeyID             string     `json:"apiKeyId"`
+	Name                 string     `json:"name"`
+	Key                  string     `json:"key,omitempty"` // only returned when the key is created or rotated
+	KeyPrefix            string     `json:"keyPrefix"`     // start of the key, to tell keys apart
+	Scopes               []string   `json:"scopes"`
+	WalletIDs            []string   `json:"walletIds,omitempty"`
+	OwnerIDs             []string   `json:"ownerIds,omitempty"`
+	ExpiresAt            *time.Time `json:"expiresAt,omitempty"`
+	PreviousKeyExpiresAt *time.Time `json:"previousKeyExpiresAt,omitempty"` // the key before the last rotation works until then
+	CreatedAt            time.Time  `json:"createdAt"`
+	RotatedAt            *time.Time `json:"rotatedAt,omitempty"`
+	RevokedAt            *time.Time `json:"revokedAt,omitempty"`
+}
+
+type APIKeyListResponse struct {
+	APIKeys []APIKeyResponse `json:"apiKeys"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	DeliveryID     int64     `json:"deliveryId"`
+	WebhookID      string    `json:"webhookId"`
+	EventType      string    `json:"eventType"`
+	WalletID       string    `json:"walletId"`
+	Status         string    `json:"status"` // PENDING, DELIVERED, DEAD
+	Attempts       int       `json:"attempts"`
+	LastStatusCode *int      `json:"lastStatusCode,omitempty"` // absent when the receiver did not respond
+	LastError      *string   `json:"lastError,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	NextCursor string                    `json:"nextCursor,omitempty"` // absent on the last page
+}
+
+type WebhookReplayResponse struct {
+	Replayed int `json:"replayed"` // dead deliveries queued again
+}
+
+// WebhookPayload is the body of a webhook delivery
+type WebhookPayload struct {
+	ID        string          `json:"id"` // ID of the delivery, the same on every attempt
+	Type      string          `json:"type" example:"operation.processed"`
+	WalletID  string          `json:"walletId"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"` // the data of the matching wallet event
 }
 
 // Database model
 type Wallet struct {
-	ID        string    `db:"id"`
-	Balance   int64     `db:"balance"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID          string `db:"id"`
+	Balance     int64  `db:"balance"`
+	HeldAmount  int64  `db:"held_amount"`  // sum of active holds
+	CreditLimit int64  `db:"credit_limit"` // how far below zero the wallet may go
+	// Withdraw limits of the wallet, nil means the global default
+	DailyWithdrawLimit   *int64     `db:"daily_withdraw_limit"`
+	MonthlyWithdrawLimit *int64     `db:"monthly_withdraw_limit"`
+	Currency             string     `db:"currency"`
+	Status               string     `db:"status"` // ACTIVE, FROZEN, CLOSED
+	StatusReason         *string    `db:"status_reason"`
+	StatusChangedAt      *time.Time `db:"status_changed_at"`
+	OwnerID              *string    `db:"owner_id"`
+	ExternalRef          *string    `db:"external_ref"` // unique per owner
+	DisplayName          *string    `db:"display_name"`
+	Labels               Labels     `db:"labels"`
+	CreatedAt            time.Time  `db:"created_at"`
+	UpdatedAt            time.Time  `db:"updated_at"`
+}
+
+// Labels are free-form key/value pairs of a wallet, stored as a JSONB object
+type Labels map[string]string
+
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *Labels) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("unsupported labels type %T", src)
+	}
+}
+
+// WalletFilter selects the wallets to list. All Labels must match and all LabelKeys
+// must be present. After is the (created_at, id) of the last wallet of the previous page.
+type WalletFilter struct {
+	OwnerID        string
+	Labels         map[string]string
+	LabelKeys      []string
+	AfterCreatedAt *time.Time
+	AfterID        string
+	Limit          int
 }
 
 type WalletOperation struct {
-	ID            string     `db:"id"`
-	WalletID      string     `db:"wallet_id"`
-	OperationType string     `db:"operation_type"`
-	Amount        int64      `db:"amount"`
-	Status        string     `db:"status"` // PENDING, PROCESSED, FAILED
-	CreatedAt     time.Time  `db:"created_at"`
-	ProcessedAt   *time.Time `db:"processed_at"`
-	Error         *string    `db:"error"`
+	ID                   string     `db:"id"`
+	WalletID             string     `db:"wallet_id"`
+	DestinationWalletID  *string    `db:"destination_wallet_id"`  // only for TRANSFER
+	ReferenceOperationID *string    `db:"reference_operation_id"` // HOLD referenced by CAPTURE / RELEASE, operation undone by REVERSAL
+	OperationType        string     `db:"operation_type"`
+	Amount               int64      `db:"amount"`
+	ReversedAmount       int64      `db:"reversed_amount"` // part of the amount already undone by reversals
+	Fee                  int64      `db:"fee"`
+	Currency             string     `db:"currency"`
+	Status               string     `db:"status"`     // SCHEDULED, PENDING, PROCESSED, FAILED, CANCELLED
+	ExpiresAt            *time.Time `db:"expires_at"` // only for HOLD
+	ExecuteAt            *time.Time `db:"execute_at"` // only for scheduled operations
+	StandingOrderID      *string    `db:"standing_order_id"`
+	IdempotencyKey       *string    `db:"idempotency_key"` // unique per wallet until it expires
+	RequestHash          *string    `db:"request_hash"`    // SHA-256 of the request that used the idempotency key
+	Description          *string    `db:"description"`
+	ExternalRef          *string    `db:"external_ref"`
+	Metadata             *string    `db:"metadata"`   // JSON object
+	CreatedBy            *string    `db:"created_by"` // ID of the API key that created the operation
+	CreatedAt            time.Time  `db:"created_at"`
+	ProcessedAt          *time.Time `db:"processed_at"`
+	Error                *string    `db:"error"`
+}
+
+// Hold is the funds reserved by a processed HOLD operation
+type Hold struct {
+	OperationID     string    `db:"operation_id"`
+	WalletID        string    `db:"wallet_id"`
+	Amount          int64     `db:"amount"`
+	RemainingAmount int64     `db:"remaining_amount"`
+	Status          string    `db:"status"` // ACTIVE, CAPTURED, RELEASED, EXPIRED
+	ExpiresAt       time.Time `db:"expires_at"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
+}
+
+// StandingOrder repeats an operation by a recurrence rule
+type StandingOrder struct {
+	ID                  string     `db:"id"`
+	WalletID            string     `db:"wallet_id"`
+	DestinationWalletID *string    `db:"destination_wallet_id"` // only for TRANSFER
+	OperationType       string     `db:"operation_type"`
+	Amount              int64      `db:"amount"`
+	Currency            string     `db:"currency"`
+	Schedule            string     `db:"schedule"`
+	Status              string     `db:"status"` // ACTIVE, PAUSED, COMPLETED, DELETED
+	StartAt             time.Time  `db:"start_at"`
+	EndAt               *time.Time `db:"end_at"`
+	MaxOccurrences      *int       `db:"max_occurrences"`
+	Occurrences         int        `db:"occurrences"`
+	NextRunAt           *time.Time `db:"next_run_at"`
+	CreatedBy           *string    `db:"created_by"`
+	CreatedAt           time.Time  `db:"created_at"`
+	UpdatedAt           time.Time  `db:"updated_at"`
+}
+
+// APIKey is an API key as stored: only the hash of the key is kept
+type APIKey struct {
+	ID                   string         `db:"id"`
+	Name                 string         `db:"name"`
+	KeyPrefix            string         `db:"key_prefix"`
+	KeyHash              string         `db:"key_hash"`
+	PreviousKeyHash      *string        `db:"previous_key_hash"`
+	PreviousKeyExpiresAt *time.Time     `db:"previous_key_expires_at"`
+	Scopes               pq.StringArray `db:"scopes"`
+	WalletIDs            pq.StringArray `db:"wallet_ids"`
+	OwnerIDs             pq.StringArray `db:"owner_ids"`
+	ExpiresAt            *time.Time     `db:"expires_at"`
+	CreatedAt            time.Time      `db:"created_at"`
+	RotatedAt            *time.Time     `db:"rotated_at"`
+	RevokedAt            *time.Time     `db:"revoked_at"`
+}
+
+type WebhookSubscription struct {
+	ID         string         `db:"id"`
+	URL        string         `db:"url"`
+	EventTypes pq.StringArray `db:"event_types"`
+	WalletID   *string        `db:"wallet_id"` // nil subscribes to every wallet
+	Secret     string         `db:"secret"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+// WebhookDelivery is an event queued for a webhook subscription by the operation worker
+type WebhookDelivery struct {
+	ID             int64      `db:"id"`
+	SubscriptionID string     `db:"subscription_id"`
+	EventType      string     `db:"event_type"`
+	WalletID       string     `db:"wallet_id"`
+	Data           string     `db:"data"`
+	Status         string     `db:"status"` // PENDING, DELIVERED, DEAD
+	Attempts       int        `db:"attempts"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	LastStatusCode *int       `db:"last_status_code"`
+	LastError      *string    `db:"last_error"`
+	CreatedAt      time.Time  `db:"created_at"`
+	DeliveredAt    *time.Time `db:"delivered_at"`
+	// Of the subscription, only set on claimed deliveries
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// WebhookDeliveryFilter selects dead webhook deliveries, newest first.
+// After is the (created_at, id) of the last delivery of the previous page.
+type WebhookDeliveryFilter struct {
+	WebhookID      string
+	AfterCreatedAt *time.Time
+	AfterID        int64
+	Limit          int
 }
 
 type KafkaMessage struct {
-	OperationID   string `json:"operation_id"`
-	WalletID      string `json:"wallet_id"`
-	OperationType string `json:"operation_type"`
-	Amount        int64  `json:"amount"`
+	OperationID          string          `json:"operation_id"`
+	WalletID             string          `json:"wallet_id"`
+	DestinationWalletID  string          `json:"destination_wallet_id,omitempty"`
+	ReferenceOperationID string          `json:"reference_operation_id,omitempty"`
+	OperationType        string          `json:"operation_type"`
+	Amount               int64           `json:"amount"`
+	Currency             string          `json:"currency"`
+	ExpiresAt            *time.Time      `json:"expires_at,omitempty"`
+	CloseWallet          bool            `json:"close_wallet,omitempty"` // the sweep of a wallet being closed, see WalletService.CloseWallet
+	Description          string          `json:"description,omitempty"`
+	ExternalRef          string          `json:"external_ref,omitempty"`
+	Metadata             json.RawMessage `json:"metadata,omitempty"`
+}
+
+// OperationFilter selects the operations to list, newest first. Empty fields do not
+// filter. After is the (created_at, id) of the last operation of the previous page.
+type OperationFilter struct {
+	WalletID       string // operations of the wallet, including transfers to it
+	ExternalRef    string
+	Statuses       []string
+	OperationTypes []string
+	MinAmount      *int64
+	MaxAmount      *int64
+	CreatedFrom    *time.Time // inclusive
+	CreatedTo      *time.Time // exclusive
+	AfterCreatedAt *time.Time
+	AfterID        string
+	Limit          int
+}
+
+// WalletEvent is a change of a wallet committed by the operation worker: the new
+// status of an operation of the wallet or its balance after a commit.
+// ID is the ID of the event in the stream of the wallet, sent as the SSE id.
+type WalletEvent struct {
+	ID       string          `json:"-"`
+	Type     string          `json:"type" example:"balance"` // operation, balance
+	WalletID string          `json:"walletId"`
+	Data     json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// Wallet event type constants
+const (
+	EventTypeOperation = "operation"
+	EventTypeBalance   = "balance"
+)
+
+// OperationEvent is the data of an operation event: the new status of an operation of the wallet
+type OperationEvent struct {
+	OperationID   string     `json:"operationId"`
+	OperationType string     `json:"operationType"`
+	Status        string     `json:"status"`
+	Amount        int64      `json:"amount"`
+	Fee           int64      `json:"fee"`
+	Currency      string     `json:"currency"`
+	Error         *string    `json:"error,omitempty"`
+	ProcessedAt   *time.Time `json:"processedAt,omitempty"`
 }
 
 // Status constants
 const (
+	OperationStatusScheduled = "SCHEDULED"
 	OperationStatusPending   = "PENDING"
 	OperationStatusProcessed = "PROCESSED"
 	OperationStatusFailed    = "FAILED"
+	OperationStatusCancelled = "CANCELLED"
 	OperationStatusAccepted  = "accepted"
+	OperationStatusRejected  = "rejected" // batch item that was not created
+	OperationStatusUnqueued  = "failed"   // batch item that was created but could not be queued
 )
 
 // Message constants
 const (
-	MessageOperationQueued = "Operation queued for processing"
-	MessageWalletCreated   = "Wallet successfully created"
+	MessageOperationQueued    = "Operation queued for processing"
+	MessageOperationScheduled = "Operation scheduled for execution"
+	MessageWalletCreated      = "Wallet successfully created"
+	MessageWalletExists       = "Wallet already exists"
 )
 
 // Operation type constants
 const (
 	OperationTypeDeposit  = "DEPOSIT"
 	OperationTypeWithdraw = "WITHDRAW"
+	OperationTypeTransfer = "TRANSFER"
+	OperationTypeHold     = "HOLD"
+	OperationTypeCapture  = "CAPTURE"
+	OperationTypeRelease  = "RELEASE"
+	OperationTypeReversal = "REVERSAL"
+)
+
+// Hold status constants
+const (
+	HoldStatusActive = "ACTIVE"
+)
+
+// Wallet status constants
+const (
+	WalletStatusActive = "ACTIVE"
+	WalletStatusFrozen = "FROZEN"
+	WalletStatusClosed = "CLOSED"
+)
+
+// Standing order status constants
+const (
+	StandingOrderStatusActive    = "ACTIVE"
+	StandingOrderStatusPaused    = "PAUSED"
+	StandingOrderStatusCompleted = "COMPLETED"
+	StandingOrderStatusDeleted   = "DELETED"
+)
+
+// Webhook event type constants
+const (
+	WebhookEventOperationProcessed = "operation.processed"
+	WebhookEventOperationFailed    = "operation.failed"
+	WebhookEventBalanceUpdated     = "balance.updated"
+)
+
+// Webhook delivery status constants
+const (
+	WebhookDeliveryStatusPending   = "PENDING"
+	WebhookDeliveryStatusDelivered = "DELIVERED"
+	WebhookDeliveryStatusDead      = "DEAD"
+)
+
+// Leg direction constants
+const (
+	LegDirectionDebit  = "DEBIT"
+	LegDirectionCredit = "CREDIT"
 )
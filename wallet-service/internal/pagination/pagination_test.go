@@ -0,0 +1,31 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 123456789, time.UTC), ID: "6f1c0b1e-4a5d-4a3b-9a4e-2f1d6c7b8a90"}
+
+	decoded, err := Decode(cursor.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID {
+		t.Fatalf("got %+v, want %+v", decoded, cursor)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// The first page has no cursor
+	if cursor, err := Decode(""); cursor != nil || err != nil {
+		t.Fatalf("empty cursor: got %+v, %v", cursor, err)
+	}
+
+	for _, s := range []string{"not base64!", "bm9waXBl", "YWJjfGlk", "MTIzfA"} {
+		if _, err := Decode(s); err != ErrInvalidCursor {
+			t.Fatalf("decode %q: got %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
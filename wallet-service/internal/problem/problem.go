@@ -0,0 +1,151 @@
+// Package problem describes the errors of the REST API as RFC 7807 problem
+// details. Every problem carries a stable code clients can branch on; the
+// detail is a human readable message that may change.
+package problem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"wallet-service/internal/validation"
+
+	"github.com/google/uuid"
+)
+
+// ContentType of problem responses
+const ContentType = "application/problem+json"
+
+// Code identifies the kind of a problem
+type Code string
+
+// Problem codes
+const (
+	MalformedRequest          Code = "MALFORMED_REQUEST"            // the body is not valid JSON
+	ValidationFailed          Code = "VALIDATION_FAILED"            // fields of the request break rules, see errors
+	Unauthenticated           Code = "UNAUTHENTICATED"              // missing or invalid API key or bearer token
+	InsufficientScope         Code = "INSUFFICIENT_SCOPE"           // the caller lacks the scope of the route
+	AccessDenied              Code = "ACCESS_DENIED"                // the caller is restricted to other wallets or owners
+	OperationNotAllowed       Code = "OPERATION_NOT_ALLOWED"        // the caller may not create operations of the type
+	RateLimited               Code = "RATE_LIMITED"                 // the caller or the wallet is over its rate limit, see Retry-After
+	WalletNotFound            Code = "WALLET_NOT_FOUND"             // no wallet with the ID
+	DestinationWalletNotFound Code = "DESTINATION_WALLET_NOT_FOUND" // no destination or sweep destination wallet with the ID
+	OperationNotFound         Code = "OPERATION_NOT_FOUND"          // no operation with the ID on the wallet
+	HoldNotFound              Code = "HOLD_NOT_FOUND"               // no hold with the ID on the wallet
+	StandingOrderNotFound     Code = "STANDING_ORDER_NOT_FOUND"     // no standing order with the ID on the wallet
+	WebhookNotFound           Code = "WEBHOOK_NOT_FOUND"            // no webhook with the ID
+	WebhookDeliveryNotFound   Code = "WEBHOOK_DELIVERY_NOT_FOUND"   // no webhook delivery with the ID
+	APIKeyNotFound            Code = "API_KEY_NOT_FOUND"            // no API key with the ID
+	APIKeyRevoked             Code = "API_KEY_REVOKED"              // the API key is revoked
+	IdempotencyKeyReused      Code = "IDEMPOTENCY_KEY_REUSED"       // the idempotency key was used for a different request
+	BatchRejected             Code = "BATCH_REJECTED"               // the operation is valid, but another one of its atomic batch is not
+	ExternalRefConflict       Code = "EXTERNAL_REF_CONFLICT"        // the external reference is used by a wallet in another currency
+	WalletFrozen              Code = "WALLET_FROZEN"                // the wallet is frozen
+	WalletClosed              Code = "WALLET_CLOSED"                // the wallet is closed
+	WalletNotFrozen           Code = "WALLET_NOT_FROZEN"            // only a frozen wallet can be unfrozen
+	WalletHasHolds            Code = "WALLET_HAS_HOLDS"             // a wallet with active holds cannot be closed
+	NegativeBalance           Code = "NEGATIVE_BALANCE"             // a wallet with a negative balance cannot be closed
+	BalanceNotZero            Code = "BALANCE_NOT_ZERO"             // closing needs a zero balance or a sweep destination
+	SweepFailed               Code = "SWEEP_FAILED"                 // the transfer of the balance failed, the wallet stays frozen
+	DestinationWalletInactive Code = "DESTINATION_WALLET_INACTIVE"  // the destination wallet is frozen or closed
+	CurrencyMismatch          Code = "CURRENCY_MISMATCH"            // the currencies of the operation and its wallets differ
+	HoldNotActive             Code = "HOLD_NOT_ACTIVE"              // the hold was captured, released or expired
+	AmountExceedsHold         Code = "AMOUNT_EXCEEDS_HOLD"          // the amount is above the amount still held
+	OperationNotCancellable   Code = "OPERATION_NOT_CANCELLABLE"    // only scheduled operations can be cancelled
+	OperationNotReversible    Code = "OPERATION_NOT_REVERSIBLE"     // the operation is of a type or status that cannot be reversed
+	AmountExceedsReversible   Code = "AMOUNT_EXCEEDS_REVERSIBLE"    // the amount is above the amount not reversed yet
+	StandingOrderNotActive    Code = "STANDING_ORDER_NOT_ACTIVE"    // only active standing orders can be paused
+	StandingOrderNotPaused    Code = "STANDING_ORDER_NOT_PAUSED"    // only paused standing orders can be resumed
+	DeliveryNotDead           Code = "DELIVERY_NOT_DEAD"            // only dead webhook deliveries can be replayed
+	InsufficientFunds         Code = "INSUFFICIENT_FUNDS"           // the operation failed: the wallet cannot cover the amount or the fee
+	LimitExceeded             Code = "LIMIT_EXCEEDED"               // the operation failed: it exceeds a withdraw limit
+	HoldExpired               Code = "HOLD_EXPIRED"                 // the operation failed: the hold expired
+	OperationFailed           Code = "OPERATION_FAILED"             // the operation failed for another reason, see its error
+	InternalError             Code = "INTERNAL_ERROR"               // an unexpected error, reported with an error ID
+	ServiceUnavailable        Code = "SERVICE_UNAVAILABLE"          // a dependency is unavailable, retry later
+)
+
+// Problem is an RFC 7807 problem detail. The type is always about:blank: the
+// title is the HTTP status text and the code tells problems apart.
+type Problem struct {
+	Type     string            `json:"type" example:"about:blank"`
+	Title    string            `json:"title" example:"Not Found"`
+	Status   int               `json:"status" example:"404"`
+	Detail   string            `json:"detail,omitempty" example:"Wallet not found"`
+	Instance string            `json:"instance,omitempty" example:"/api/v1/wallets/6f1c0b1e-4a5d-4a3b-9a4e-2f1d6c7b8a90"`
+	Code     Code              `json:"code" example:"WALLET_NOT_FOUND"`
+	Errors   validation.Errors `json:"errors,omitempty"`                                                 // with VALIDATION_FAILED
+	ErrorID  string            `json:"errorId,omitempty" example:"1c9d0b7a-51f8-4f0e-9d5b-6c8a2e3f4b10"` // with INTERNAL_ERROR, also found in the logs
+}
+
+// New returns a problem with the given status and code
+func New(status int, code Code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Validation returns the VALIDATION_FAILED problem of the violations of a request
+func Validation(violations validation.Errors) *Problem {
+	p := New(http.StatusBadRequest, ValidationFailed, "The request is invalid")
+	p.Errors = violations
+	return p
+}
+
+// Internal returns the INTERNAL_ERROR problem of an unexpected error and logs the
+// error with the ID returned to the client, which tells nothing else about it
+func Internal(message string, err error) *Problem {
+	errorID := uuid.NewString()
+	fmt.Printf("Internal error %s: %s: %v\n", errorID, message, err)
+
+	p := New(http.StatusInternalServerError, InternalError, "An unexpected error occurred, report the error ID")
+	p.ErrorID = errorID
+	return p
+}
+
+// Write writes the problem as the response to r
+func (p *Problem) Write(w http.ResponseWriter, r *http.Request) {
+	p.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// failureCodes maps the reasons the worker fails operations with to codes,
+// by prefix; the reasons are written by the operation-worker
+var failureCodes = []struct {
+	prefix string
+	code   Code
+}{
+	{"insufficient funds", InsufficientFunds},
+	{"limit exceeded", LimitExceeded},
+	{"hold expired", HoldExpired},
+	{"hold not found", HoldNotFound},
+	{"hold is ", HoldNotActive},
+	{"amount exceeds held amount", AmountExceedsHold},
+	{"amount exceeds reversible amount", AmountExceedsReversible},
+	{"wallet is frozen", WalletFrozen},
+	{"wallet is closed", WalletClosed},
+	{"destination wallet not found", DestinationWalletNotFound},
+	{"destination wallet is ", DestinationWalletInactive},
+	{"currency mismatch", CurrencyMismatch},
+	{"fee wallet currency mismatch", CurrencyMismatch},
+	{"original operation not found", OperationNotFound},
+	{"original operation is not processed", OperationNotReversible},
+	{"operation type ", OperationNotReversible},
+}
+
+// FailureCode returns the code of the reason an operation failed
+func FailureCode(reason string) Code {
+	for _, failure := range failureCodes {
+		if strings.HasPrefix(reason, failure.prefix) {
+			return failure.code
+		}
+	}
+	return OperationFailed
+}
@@ -0,0 +1,92 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wallet-service/internal/validation"
+)
+
+func TestWrite(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/w1?wait=1s", nil)
+	w := httptest.NewRecorder()
+
+	New(http.StatusNotFound, WalletNotFound, "Wallet not found").Write(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, ContentType)
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"type":     "about:blank",
+		"title":    "Not Found",
+		"status":   float64(404),
+		"detail":   "Wallet not found",
+		"instance": "/api/v1/wallets/w1",
+		"code":     "WALLET_NOT_FOUND",
+	}
+	if len(body) != len(want) {
+		t.Errorf("body = %v, want %v", body, want)
+	}
+	for key, value := range want {
+		if body[key] != value {
+			t.Errorf("%s = %v, want %v", key, body[key], value)
+		}
+	}
+}
+
+func TestValidation(t *testing.T) {
+	p := Validation(validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+	if p.Status != http.StatusBadRequest || p.Code != ValidationFailed {
+		t.Errorf("problem = %+v", p)
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Field != "walletId" {
+		t.Errorf("errors = %v", p.Errors)
+	}
+}
+
+func TestInternal(t *testing.T) {
+	p := Internal("Failed to create operation", errors.New("pq: connection refused"))
+	if p.Status != http.StatusInternalServerError || p.Code != InternalError || p.ErrorID == "" {
+		t.Fatalf("problem = %+v", p)
+	}
+
+	data, _ := json.Marshal(p)
+	if strings.Contains(string(data), "connection refused") {
+		t.Error("internal problem leaks the error")
+	}
+}
+
+func TestFailureCode(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   Code
+	}{
+		{"insufficient funds", InsufficientFunds},
+		{"insufficient funds for fee", InsufficientFunds},
+		{"insufficient funds in destination wallet", InsufficientFunds},
+		{"limit exceeded: daily", LimitExceeded},
+		{"wallet is frozen", WalletFrozen},
+		{"destination wallet is closed", DestinationWalletInactive},
+		{"hold is CAPTURED", HoldNotActive},
+		{"currency mismatch: operation in USD, wallet in EUR", CurrencyMismatch},
+		{"operation type HOLD cannot be reversed", OperationNotReversible},
+		{"fee wallet not configured", OperationFailed},
+	}
+
+	for _, tt := range tests {
+		if got := FailureCode(tt.reason); got != tt.want {
+			t.Errorf("FailureCode(%q) = %s, want %s", tt.reason, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package ratelimit describes the token buckets limiting clients and wallets and
+// the RateLimit headers reporting them. The buckets themselves live in Redis, so
+// every replica of the service draws from the same ones.
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Response headers, after the IETF RateLimit header fields draft
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+	HeaderRetry     = "Retry-After"
+)
+
+// Limit is a token bucket refilled with Rate tokens per second up to Burst tokens.
+// Every request takes one token.
+type Limit struct {
+	Rate  int64
+	Burst int64
+}
+
+// NewLimit returns the limit of rate requests per second, a burst of 0 is the rate
+func NewLimit(rate, burst int64) Limit {
+	if burst <= 0 {
+		burst = rate
+	}
+	return Limit{Rate: rate, Burst: burst}
+}
+
+// Enabled reports whether the limit applies at all
+func (l Limit) Enabled() bool {
+	return l.Rate > 0
+}
+
+// Result is the state of a bucket after a request took its token, or failed to
+type Result struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, when not allowed
+}
+
+// NewResult returns the result of a request given the tokens left in the bucket
+func NewResult(limit Limit, allowed bool, tokens float64) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int64(math.Floor(tokens)),
+		Reset:     refill(limit, float64(limit.Burst)-tokens),
+	}
+	if !allowed {
+		result.RetryAfter = refill(limit, 1-tokens)
+	}
+
+	return result
+}
+
+// refill returns the whole seconds needed to add tokens to a bucket
+func refill(limit Limit, tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens/float64(limit.Rate))) * time.Second
+}
+
+// SetHeaders reports the result in the headers of a response. A request is checked
+// against several buckets, so headers already set by a bucket with fewer tokens
+// left are kept: the client sees the limit it will hit first.
+func SetHeaders(h http.Header, result Result) {
+	if remaining, err := strconv.ParseInt(h.Get(HeaderRemaining), 10, 64); err == nil &&
+		remaining <= result.Remaining && result.Allowed {
+		return
+	}
+
+	h.Set(HeaderLimit, strconv.FormatInt(result.Limit, 10))
+	h.Set(HeaderRemaining, strconv.FormatInt(result.Remaining, 10))
+	h.Set(HeaderReset, strconv.FormatInt(int64(result.Reset/time.Second), 10))
+	if !result.Allowed {
+		h.Set(HeaderRetry, strconv.FormatInt(int64(result.RetryAfter/time.Second), 10))
+	}
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewLimit(t *testing.T) {
+	if got := NewLimit(100, 0); got.Burst != 100 {
+		t.Errorf("NewLimit(100, 0).Burst = %d, want 100", got.Burst)
+	}
+	if got := NewLimit(100, 500); got.Burst != 500 {
+		t.Errorf("NewLimit(100, 500).Burst = %d, want 500", got.Burst)
+	}
+	if NewLimit(0, 10).Enabled() {
+		t.Error("limit without a rate is enabled")
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	limit := NewLimit(10, 20)
+
+	tests := []struct {
+		name       string
+		allowed    bool
+		tokens     float64
+		remaining  int64
+		reset      time.Duration
+		retryAfter time.Duration
+	}{
+		{"full bucket", true, 19, 19, time.Second, 0},
+		{"partial token", true, 4.5, 4, 2 * time.Second, 0},
+		{"empty bucket", false, 0, 0, 2 * time.Second, time.Second},
+		{"almost a token", false, 0.95, 0, 2 * time.Second, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewResult(limit, tt.allowed, tt.tokens)
+			if got.Allowed != tt.allowed || got.Limit != 20 || got.Remaining != tt.remaining ||
+				got.Reset != tt.reset || got.RetryAfter != tt.retryAfter {
+				t.Errorf("NewResult() = %+v", got)
+			}
+		})
+	}
+
+	slow := NewLimit(1, 1)
+	if got := NewResult(slow, false, 0.25); got.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", got.RetryAfter)
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	h := http.Header{}
+	SetHeaders(h, NewResult(NewLimit(10, 20), true, 5))
+	if h.Get(HeaderLimit) != "20" || h.Get(HeaderRemaining) != "5" || h.Get(HeaderReset) != "2" || h.Get(HeaderRetry) != "" {
+		t.Fatalf("headers = %v", h)
+	}
+
+	// A bucket with more tokens left does not replace the tighter one
+	SetHeaders(h, NewResult(NewLimit(100, 100), true, 80))
+	if h.Get(HeaderLimit) != "20" || h.Get(HeaderRemaining) != "5" {
+		t.Errorf("looser bucket replaced headers: %v", h)
+	}
+
+	// A rejection always wins
+	SetHeaders(h, NewResult(NewLimit(100, 100), false, 0.5))
+	if h.Get(HeaderLimit) != "100" || h.Get(HeaderRemaining) != "0" || h.Get(HeaderRetry) != "1" {
+		t.Errorf("rejection did not replace headers: %v", h)
+	}
+}
@@ -0,0 +1,220 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinInterval is the shortest period of an @every rule
+const MinInterval = time.Minute
+
+var (
+	ErrInvalidRule = errors.New("invalid recurrence rule")
+)
+
+// Rule is the recurrence rule of a standing order. All times are evaluated in UTC.
+type Rule interface {
+	// First returns the first occurrence at or after start
+	First(start time.Time) time.Time
+	// Next returns the first occurrence strictly after t, or the zero time if there is none
+	Next(t time.Time) time.Time
+}
+
+// shortcuts are the named cron expressions
+var shortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// Parse parses a recurrence rule:
+//   - "@every <duration>", e.g. "@every 720h": fixed interval counted from the start
+//   - "@hourly", "@daily", "@weekly", "@monthly" or "@yearly"
+//   - a cron expression "minute hour day-of-month month day-of-week", e.g. "0 9 1 * *"
+func Parse(spec string) (Rule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+		if every < MinInterval {
+			return nil, fmt.Errorf("%w: interval must be at least %s", ErrInvalidRule, MinInterval)
+		}
+		return interval{every: every}, nil
+	}
+
+	if expr, ok := shortcuts[spec]; ok {
+		spec = expr
+	}
+
+	return parseCron(spec)
+}
+
+// interval repeats every fixed duration starting from the start time
+type interval struct {
+	every time.Duration
+}
+
+func (r interval) First(start time.Time) time.Time {
+	return start.UTC()
+}
+
+func (r interval) Next(t time.Time) time.Time {
+	return t.UTC().Add(r.every)
+}
+
+// cron matches the minutes whose fields are all set in the bit sets
+type cron struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// A restricted day-of-month or day-of-week matches on its own, as in cron(8)
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+// searchLimit bounds the search for the next occurrence; it covers a leap day
+const searchLimit = 5
+
+func parseCron(spec string) (Rule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 cron fields, got %d", ErrInvalidRule, len(fields))
+	}
+
+	bounds := []struct {
+		name     string
+		min, max int
+	}{
+		{"minute", 0, 59},
+		{"hour", 0, 23},
+		{"day of month", 1, 31},
+		{"month", 1, 12},
+		{"day of week", 0, 7},
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := parseField(field, bounds[i].min, bounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRule, bounds[i].name, err)
+		}
+		sets[i] = set
+	}
+
+	// Sunday is both 0 and 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	c := cron{
+		minute:        sets[0],
+		hour:          sets[1],
+		dayOfMonth:    sets[2],
+		month:         sets[3],
+		dayOfWeek:     sets[4],
+		anyDayOfMonth: strings.HasPrefix(fields[2], "*"),
+		anyDayOfWeek:  strings.HasPrefix(fields[4], "*"),
+	}
+
+	// e.g. "0 0 30 2 *" never happens
+	if c.Next(time.Unix(0, 0)).IsZero() {
+		return nil, fmt.Errorf("%w: expression never matches", ErrInvalidRule)
+	}
+
+	return c, nil
+}
+
+// parseField parses a comma separated list of "*", "n" or "a-b", each optionally followed by "/step"
+func parseField(field string, min, max int) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		from, to := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var errA, errB error
+			from, errA = strconv.Atoi(a)
+			to, errB = strconv.Atoi(b)
+			if errA != nil || errB != nil || from > to {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rangePart)
+			}
+			from, to = n, n
+			// "5/15" means every 15 starting at 5
+			if hasStep {
+				to = max
+			}
+		}
+
+		if from < min || to > max {
+			return 0, fmt.Errorf("value out of range %d-%d in %q", min, max, part)
+		}
+		for v := from; v <= to; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+
+	return set, nil
+}
+
+func (c cron) First(start time.Time) time.Time {
+	return c.Next(start.Add(-time.Nanosecond))
+}
+
+func (c cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchLimit, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c cron) dayMatches(t time.Time) bool {
+	dayOfMonth := c.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := c.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if c.anyDayOfMonth || c.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
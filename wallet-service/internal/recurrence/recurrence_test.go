@@ -0,0 +1,82 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRule_Next(t *testing.T) {
+	at := func(s string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatalf("parse %s: %v", s, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		spec string
+		from string
+		want string
+	}{
+		{"@every 1h30m", "2025-01-31T10:15:00Z", "2025-01-31T11:45:00Z"},
+		{"@hourly", "2025-01-31T10:15:00Z", "2025-01-31T11:00:00Z"},
+		{"@daily", "2025-01-31T10:15:00Z", "2025-02-01T00:00:00Z"},
+		{"@monthly", "2025-01-31T10:15:00Z", "2025-02-01T00:00:00Z"},
+		{"0 9 1 * *", "2025-01-01T09:00:00Z", "2025-02-01T09:00:00Z"},
+		{"*/15 * * * *", "2025-01-31T10:15:30Z", "2025-01-31T10:30:00Z"},
+		{"30 8 * * 1-5", "2025-01-31T09:00:00Z", "2025-02-03T08:30:00Z"}, // Friday -> Monday
+		{"0 0 * * 7", "2025-01-31T00:00:00Z", "2025-02-02T00:00:00Z"},    // 7 is Sunday
+		{"0 0 29 2 *", "2025-01-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"0 12 13 * 5", "2025-01-01T00:00:00Z", "2025-01-03T12:00:00Z"}, // day of month or Friday
+		{"5/20 0 1 1,7 *", "2025-01-01T00:10:00Z", "2025-01-01T00:25:00Z"},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.spec, err)
+		}
+		if got := rule.Next(at(tt.from)); !got.Equal(at(tt.want)) {
+			t.Fatalf("%q next after %s: got %s, want %s", tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestRule_First(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	// The start itself is the first occurrence when it matches
+	cron, _ := Parse("0 9 1 * *")
+	if got := cron.First(start); !got.Equal(start) {
+		t.Fatalf("cron first: got %s, want %s", got, start)
+	}
+	if got := cron.First(start.Add(time.Second)); !got.Equal(start.AddDate(0, 1, 0)) {
+		t.Fatalf("cron first after start: got %s", got)
+	}
+
+	every, _ := Parse("@every 24h")
+	if got := every.First(start.Add(time.Second)); !got.Equal(start.Add(time.Second)) {
+		t.Fatalf("interval first: got %s", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"@every 30s",
+		"@every soon",
+		"@fortnightly",
+		"* * * *",
+		"60 * * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"0 0 30 2 *",
+	} {
+		if _, err := Parse(spec); !errors.Is(err, ErrInvalidRule) {
+			t.Fatalf("parse %q: got %v, want ErrInvalidRule", spec, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package kafkarepo
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"wallet-service/internal/models"
 
@@ -39,3 +40,50 @@ func (r *OperationRepository) SendOperation(ctx context.Context, msg models.Kafk
 
 	return nil
 }
+
+// SendOperations sends operations to Kafka with a single write. It returns nil when
+// every message was written, otherwise the error of each message (nil for written ones).
+func (r *OperationRepository) SendOperations(ctx context.Context, msgs []models.KafkaMessage) []error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	kafkaMsgs := make([]kafka.Message, len(msgs))
+	for i, msg := range msgs {
+		msgBytes, err := json.Marshal(msg)
+		if err != nil {
+			return sameError(len(msgs), fmt.Errorf("failed to marshal kafka message: %w", err))
+		}
+		kafkaMsgs[i] = kafka.Message{
+			Key:   []byte(msg.WalletID),
+			Value: msgBytes,
+		}
+	}
+
+	err := r.writer.WriteMessages(ctx, kafkaMsgs...)
+	if err == nil {
+		return nil
+	}
+
+	// The writer reports the messages it failed to write when only some of them failed
+	var writeErrors kafka.WriteErrors
+	if errors.As(err, &writeErrors) && len(writeErrors) == len(msgs) {
+		errs := make([]error, len(msgs))
+		for i, writeErr := range writeErrors {
+			if writeErr != nil {
+				errs[i] = fmt.Errorf("failed to write message to kafka: %w", writeErr)
+			}
+		}
+		return errs
+	}
+
+	return sameError(len(msgs), fmt.Errorf("failed to write messages to kafka: %w", err))
+}
+
+func sameError(n int, err error) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
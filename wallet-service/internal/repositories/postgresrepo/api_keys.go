@@ -0,0 +1,134 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"wallet-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const apiKeyColumns = `
+	id, name, key_prefix, key_hash, previous_key_hash, previous_key_expires_at, scopes, wallet_ids, owner_ids,
+	expires_at, created_at, rotated_at, revoked_at
+`
+
+// CreateAPIKey create a new API key and return it
+func (r *WalletRepository) CreateAPIKey(ctx context.Context, key models.APIKey) (*models.APIKey, error) {
+	var created models.APIKey
+
+	query := `
+		INSERT INTO api_keys (id, name, key_prefix, key_hash, scopes, wallet_ids, owner_ids, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING ` + apiKeyColumns
+
+	err := r.db.GetContext(ctx, &created, query,
+		uuid.New().String(),
+		key.Name,
+		key.KeyPrefix,
+		key.KeyHash,
+		key.Scopes,
+		key.WalletIDs,
+		key.OwnerIDs,
+		key.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetAPIKey get an API key by ID
+func (r *WalletRepository) GetAPIKey(ctx context.Context, keyID string) (*models.APIKey, error) {
+	var key models.APIKey
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+	if err := r.db.GetContext(ctx, &key, query, keyID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key from postgres: %w", err)
+	}
+
+	return &key, nil
+}
+
+// GetActiveAPIKeyByHash get the API key that is valid at now for the hash of a key:
+// not revoked, not expired, and either the current key or the previous one within
+// its grace period
+func (r *WalletRepository) GetActiveAPIKeyByHash(ctx context.Context, keyHash string, now time.Time) (*models.APIKey, error) {
+	var key models.APIKey
+
+	query := `
+		SELECT ` + apiKeyColumns + ` FROM api_keys
+		WHERE (key_hash = $1 OR (previous_key_hash = $1 AND previous_key_expires_at > $2))
+			AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+	`
+	if err := r.db.GetContext(ctx, &key, query, keyHash, now); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key from postgres: %w", err)
+	}
+
+	return &key, nil
+}
+
+// ListAPIKeys get all API keys in creation order, revoked ones included
+func (r *WalletRepository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &keys, query); err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RotateAPIKey replace the key of an API key that is not revoked. The replaced key
+// stays valid until previousExpiresAt; a previous key still in its grace period is dropped.
+func (r *WalletRepository) RotateAPIKey(ctx context.Context, keyID, keyPrefix, keyHash string, previousExpiresAt time.Time) (*models.APIKey, error) {
+	var rotated models.APIKey
+
+	query := `
+		UPDATE api_keys
+		SET previous_key_hash = key_hash, previous_key_expires_at = $4,
+			key_prefix = $2, key_hash = $3, rotated_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING ` + apiKeyColumns
+
+	if err := r.db.GetContext(ctx, &rotated, query, keyID, keyPrefix, keyHash, previousExpiresAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	return &rotated, nil
+}
+
+// RevokeAPIKey revoke an API key together with its previous key, keeping the time
+// of the first revocation
+func (r *WalletRepository) RevokeAPIKey(ctx context.Context, keyID string) (*models.APIKey, error) {
+	var revoked models.APIKey
+
+	query := `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, NOW()), previous_key_hash = NULL, previous_key_expires_at = NULL
+		WHERE id = $1
+		RETURNING ` + apiKeyColumns
+
+	if err := r.db.GetContext(ctx, &revoked, query, keyID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	return &revoked, nil
+}
@@ -0,0 +1,84 @@
+package postgresrepo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"wallet-service/internal/models"
+
+	"github.com/lib/pq"
+)
+
+const operationColumns = `
+	id, wallet_id, destination_wallet_id, reference_operation_id, operation_type, amount, reversed_amount,
+	fee, currency, status, expires_at, execute_at, standing_order_id, description, external_ref, metadata,
+	created_by, created_at, processed_at, error
+`
+
+// ListOperations get up to filter.Limit operations matching the filter, newest first.
+// The operations of a wallet are read as two keyset scans, over its outgoing operations
+// and over the transfers to it, merged into one page.
+func (r *WalletRepository) ListOperations(ctx context.Context, filter models.OperationFilter) ([]models.WalletOperation, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.ExternalRef != "" {
+		conditions = append(conditions, "external_ref = "+arg(filter.ExternalRef))
+	}
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, "status = ANY("+arg(pq.Array(filter.Statuses))+")")
+	}
+	if len(filter.OperationTypes) > 0 {
+		conditions = append(conditions, "operation_type = ANY("+arg(pq.Array(filter.OperationTypes))+")")
+	}
+	if filter.MinAmount != nil {
+		conditions = append(conditions, "amount >= "+arg(*filter.MinAmount))
+	}
+	if filter.MaxAmount != nil {
+		conditions = append(conditions, "amount <= "+arg(*filter.MaxAmount))
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.CreatedTo))
+	}
+	if filter.AfterCreatedAt != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(*filter.AfterCreatedAt), arg(filter.AfterID)))
+	}
+
+	page := ` ORDER BY created_at DESC, id DESC LIMIT ` + arg(filter.Limit)
+	where := func(extra ...string) string {
+		all := append(extra, conditions...)
+		if len(all) == 0 {
+			return ""
+		}
+		return ` WHERE ` + strings.Join(all, " AND ")
+	}
+
+	query := `SELECT ` + operationColumns + ` FROM wallet_operations` + where() + page
+	if filter.WalletID != "" {
+		walletID := arg(filter.WalletID)
+		query = `
+			SELECT ` + operationColumns + ` FROM (
+				(SELECT ` + operationColumns + ` FROM wallet_operations` + where("wallet_id = "+walletID) + page + `)
+				UNION ALL
+				(SELECT ` + operationColumns + ` FROM wallet_operations` +
+			where("destination_wallet_id = "+walletID, "wallet_id <> "+walletID) + page + `)
+			) operations` + page
+	}
+
+	var operations []models.WalletOperation
+	if err := r.db.SelectContext(ctx, &operations, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list operations: %w", err)
+	}
+
+	return operations, nil
+}
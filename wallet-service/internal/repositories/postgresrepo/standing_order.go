@@ -0,0 +1,177 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"wallet-service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const standingOrderColumns = `
+	id, wallet_id, destination_wallet_id, operation_type, amount, currency, schedule, status,
+	start_at, end_at, max_occurrences, occurrences, next_run_at, created_by, created_at, updated_at
+`
+
+// CreateStandingOrder create a new standing order and return it
+func (r *WalletRepository) CreateStandingOrder(ctx context.Context, order models.StandingOrder) (*models.StandingOrder, error) {
+	var created models.StandingOrder
+
+	query := `
+		INSERT INTO standing_orders
+		(id, wallet_id, destination_wallet_id, operation_type, amount, currency, schedule, status,
+		 start_at, end_at, max_occurrences, next_run_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW(), NOW())
+		RETURNING ` + standingOrderColumns
+
+	err := r.db.GetContext(ctx, &created, query,
+		uuid.New().String(),
+		order.WalletID,
+		order.DestinationWalletID,
+		order.OperationType,
+		order.Amount,
+		order.Currency,
+		order.Schedule,
+		order.Status,
+		order.StartAt,
+		order.EndAt,
+		order.MaxOccurrences,
+		order.NextRunAt,
+		order.CreatedBy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create standing order: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetStandingOrder get a standing order of a wallet
+func (r *WalletRepository) GetStandingOrder(ctx context.Context, walletID, orderID string) (*models.StandingOrder, error) {
+	var order models.StandingOrder
+
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders WHERE wallet_id = $1 AND id = $2`
+
+	err := r.db.GetContext(ctx, &order, query, walletID, orderID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrStandingOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get standing order from postgres: %w", err)
+	}
+
+	return &order, nil
+}
+
+// UpdateStandingOrderStatus change the status and the next run of a standing order
+// if its status is still one of fromStatuses. It returns false when it is not.
+func (r *WalletRepository) UpdateStandingOrderStatus(ctx context.Context, walletID, orderID string, fromStatuses []string, status string, nextRunAt *time.Time) (bool, error) {
+	query, args, err := sqlx.In(`
+		UPDATE standing_orders SET status = ?, next_run_at = ?, updated_at = NOW()
+		WHERE wallet_id = ? AND id = ? AND status IN (?)
+	`, status, nextRunAt, walletID, orderID, fromStatuses)
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update standing order: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetStandingOrderOperations get the operations created by a standing order, newest first
+func (r *WalletRepository) GetStandingOrderOperations(ctx context.Context, orderID string) ([]models.WalletOperation, error) {
+	var operations []models.WalletOperation
+
+	query := `
+		SELECT
+			id, wallet_id, destination_wallet_id, reference_operation_id, operation_type, amount, reversed_amount,
+			fee, currency, status, expires_at, execute_at, standing_order_id, created_at, processed_at, error
+		FROM wallet_operations
+		WHERE standing_order_id = $1
+		ORDER BY created_at DESC
+	`
+
+	if err := r.db.SelectContext(ctx, &operations, query, orderID); err != nil {
+		return nil, fmt.Errorf("failed to get standing order operations from postgres: %w", err)
+	}
+
+	return operations, nil
+}
+
+// FireDueStandingOrders creates a PENDING operation for every active standing order
+// whose next run has come and returns the created operations. advance moves the
+// order to its next occurrence, or completes it. Orders locked by another scheduler
+// replica are skipped, so every occurrence is created exactly once.
+func (r *WalletRepository) FireDueStandingOrders(ctx context.Context, now time.Time, limit int, advance func(order *models.StandingOrder)) ([]models.WalletOperation, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	query := `
+		SELECT ` + standingOrderColumns + `
+		FROM standing_orders
+		WHERE status = 'ACTIVE' AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+
+	var orders []models.StandingOrder
+	if err := tx.SelectContext(ctx, &orders, query, now, limit); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to select due standing orders: %w", err)
+	}
+
+	operations := make([]models.WalletOperation, 0, len(orders))
+	for _, order := range orders {
+		orderID := order.ID
+		operation := models.WalletOperation{
+			ID:                  uuid.New().String(),
+			WalletID:            order.WalletID,
+			DestinationWalletID: order.DestinationWalletID,
+			OperationType:       order.OperationType,
+			Amount:              order.Amount,
+			Currency:            order.Currency,
+			Status:              models.OperationStatusPending,
+			StandingOrderID:     &orderID,
+			CreatedBy:           order.CreatedBy,
+		}
+		if err := insertOperation(ctx, tx, operation); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		advance(&order)
+
+		update := `
+			UPDATE standing_orders SET occurrences = $1, next_run_at = $2, status = $3, updated_at = NOW()
+			WHERE id = $4
+		`
+		if _, err := tx.ExecContext(ctx, update, order.Occurrences, order.NextRunAt, order.Status, order.ID); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to advance standing order: %w", err)
+		}
+
+		operations = append(operations, operation)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return operations, nil
+}
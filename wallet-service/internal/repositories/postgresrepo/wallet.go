@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"wallet-service/internal/models"
 
@@ -13,10 +15,21 @@ import (
 )
 
 var (
-	ErrWalletNotFound    = errors.New("wallet not found")
-	ErrOperationNotFound = errors.New("operation not found")
+	ErrWalletNotFound        = errors.New("wallet not found")
+	ErrOperationNotFound     = errors.New("operation not found")
+	ErrHoldNotFound          = errors.New("hold not found")
+	ErrStandingOrderNotFound = errors.New("standing order not found")
+	ErrIdempotencyKeyExists  = errors.New("idempotency key already exists")
+	ErrWebhookNotFound       = errors.New("webhook not found")
+	ErrDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrAPIKeyNotFound        = errors.New("API key not found")
 )
 
+const walletColumns = `
+	id, balance, held_amount, credit_limit, daily_withdraw_limit, monthly_withdraw_limit, currency,
+	status, status_reason, status_changed_at, owner_id, external_ref, display_name, labels, created_at, updated_at
+`
+
 type WalletRepository struct {
 	db *sqlx.DB
 }
@@ -29,11 +42,23 @@ func NewWalletRepository(db *sqlx.DB) *WalletRepository {
 func (r *WalletRepository) GetWallet(ctx context.Context, walletID string) (*models.Wallet, error) {
 	var wallet models.Wallet
 
-	query := `SELECT id, balance, created_at, updated_at FROM wallets WHERE id = $1`
+	query := `SELECT ` + walletColumns + ` FROM wallets WHERE id = $1`
 
 	err := r.db.QueryRowContext(ctx, query, walletID).Scan(
 		&wallet.ID,
 		&wallet.Balance,
+		&wallet.HeldAmount,
+		&wallet.CreditLimit,
+		&wallet.DailyWithdrawLimit,
+		&wallet.MonthlyWithdrawLimit,
+		&wallet.Currency,
+		&wallet.Status,
+		&wallet.StatusReason,
+		&wallet.StatusChangedAt,
+		&wallet.OwnerID,
+		&wallet.ExternalRef,
+		&wallet.DisplayName,
+		&wallet.Labels,
 		&wallet.CreatedAt,
 		&wallet.UpdatedAt,
 	)
@@ -48,36 +73,179 @@ func (r *WalletRepository) GetWallet(ctx context.Context, walletID string) (*mod
 	return &wallet, nil
 }
 
-// CreateWallet create a new wallet
-func (r *WalletRepository) CreateWallet(ctx context.Context, walletID string) error {
-	query := `INSERT INTO wallets (id, balance) VALUES ($1, $2)`
+// CreateWallet create a new wallet. It returns false without creating anything
+// when the owner already has a wallet with the same external reference.
+func (r *WalletRepository) CreateWallet(ctx context.Context, wallet models.Wallet) (bool, error) {
+	query := `
+		INSERT INTO wallets (id, balance, currency, owner_id, external_ref, display_name, labels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (owner_id, external_ref) DO NOTHING
+	`
 
-	_, err := r.db.ExecContext(ctx, query, walletID, 0)
+	result, err := r.db.ExecContext(ctx, query,
+		wallet.ID, 0, wallet.Currency, wallet.OwnerID, wallet.ExternalRef, wallet.DisplayName, wallet.Labels)
+	if err != nil {
+		return false, fmt.Errorf("failed to create wallet: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to create wallet: %w", err)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetWalletByExternalRef get the wallet of an owner by its external reference
+func (r *WalletRepository) GetWalletByExternalRef(ctx context.Context, ownerID, externalRef string) (*models.Wallet, error) {
+	var wallet models.Wallet
+
+	query := `SELECT ` + walletColumns + ` FROM wallets WHERE owner_id = $1 AND external_ref = $2`
+
+	err := r.db.GetContext(ctx, &wallet, query, ownerID, externalRef)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet from postgres: %w", err)
+	}
+
+	return &wallet, nil
+}
+
+// ListWallets get up to filter.Limit wallets matching the filter in (created_at, id) order
+func (r *WalletRepository) ListWallets(ctx context.Context, filter models.WalletFilter) ([]models.Wallet, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.OwnerID != "" {
+		conditions = append(conditions, "owner_id = "+arg(filter.OwnerID))
+	}
+	if len(filter.Labels) > 0 {
+		conditions = append(conditions, "labels @> "+arg(models.Labels(filter.Labels)))
+	}
+	for _, key := range filter.LabelKeys {
+		conditions = append(conditions, "labels ? "+arg(key))
+	}
+	if filter.AfterCreatedAt != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) > (%s, %s)", arg(*filter.AfterCreatedAt), arg(filter.AfterID)))
+	}
+
+	query := `SELECT ` + walletColumns + ` FROM wallets`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at, id LIMIT ` + arg(filter.Limit)
+
+	var wallets []models.Wallet
+	if err := r.db.SelectContext(ctx, &wallets, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// UpdateCreditLimit set the credit limit of a wallet and return the updated wallet
+func (r *WalletRepository) UpdateCreditLimit(ctx context.Context, walletID string, creditLimit int64) (*models.Wallet, error) {
+	var wallet models.Wallet
+
+	query := `
+		UPDATE wallets SET credit_limit = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING ` + walletColumns
+
+	err := r.db.GetContext(ctx, &wallet, query, creditLimit, walletID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to update credit limit: %w", err)
+	}
+
+	return &wallet, nil
+}
+
+// UpdateWithdrawLimits set the withdraw limits of a wallet, nil resets a limit to the global default
+func (r *WalletRepository) UpdateWithdrawLimits(ctx context.Context, walletID string, daily, monthly *int64) error {
+	query := `
+		UPDATE wallets SET daily_withdraw_limit = $1, monthly_withdraw_limit = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, daily, monthly, walletID)
+	if err != nil {
+		return fmt.Errorf("failed to update withdraw limits: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrWalletNotFound
 	}
 
 	return nil
 }
 
-// GetOperation get the operation by wallet ID and operation ID
+// SumOperationsSince get the total amount of the wallet's processed operations
+// of the given types processed at or after since, less the part already reversed:
+// a reversal restores the limit headroom used by its operation
+func (r *WalletRepository) SumOperationsSince(ctx context.Context, walletID string, operationTypes []string, since time.Time) (int64, error) {
+	query, args, err := sqlx.In(`
+		SELECT COALESCE(SUM(amount - reversed_amount), 0) FROM wallet_operations
+		WHERE wallet_id = ? AND status = 'PROCESSED' AND operation_type IN (?) AND processed_at >= ?
+	`, walletID, operationTypes, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, r.db.Rebind(query), args...); err != nil {
+		return 0, fmt.Errorf("failed to sum operations: %w", err)
+	}
+
+	return total, nil
+}
+
+// GetOperation get the operation by wallet ID and operation ID.
+// A transfer is visible from both the source and the destination wallet.
 func (r *WalletRepository) GetOperation(ctx context.Context, walletID, operationID string) (*models.WalletOperation, error) {
 	var operation models.WalletOperation
 
 	query := `
 		SELECT 
-			id, wallet_id, operation_type, amount, status, 
-			created_at, processed_at, error
+			id, wallet_id, destination_wallet_id, reference_operation_id, operation_type, amount, reversed_amount,
+			fee, currency, status, expires_at, execute_at, standing_order_id, description, external_ref, metadata,
+			created_by, created_at, processed_at, error
 		FROM wallet_operations 
-		WHERE wallet_id = $1 AND id = $2
+		WHERE (wallet_id = $1 OR destination_wallet_id = $1) AND id = $2
 	`
 
 	err := r.db.QueryRowContext(ctx, query, walletID, operationID).Scan(
 		&operation.ID,
 		&operation.WalletID,
+		&operation.DestinationWalletID,
+		&operation.ReferenceOperationID,
 		&operation.OperationType,
 		&operation.Amount,
+		&operation.ReversedAmount,
+		&operation.Fee,
+		&operation.Currency,
 		&operation.Status,
+		&operation.ExpiresAt,
+		&operation.ExecuteAt,
+		&operation.StandingOrderID,
+		&operation.Description,
+		&operation.ExternalRef,
+		&operation.Metadata,
+		&operation.CreatedBy,
 		&operation.CreatedAt,
 		&operation.ProcessedAt,
 		&operation.Error,
@@ -93,6 +261,47 @@ func (r *WalletRepository) GetOperation(ctx context.Context, walletID, operation
 	return &operation, nil
 }
 
+// GetReversals get the reversals of an operation in creation order
+func (r *WalletRepository) GetReversals(ctx context.Context, operationID string) ([]models.WalletOperation, error) {
+	var reversals []models.WalletOperation
+
+	query := `
+		SELECT
+			id, wallet_id, destination_wallet_id, reference_operation_id, operation_type, amount, reversed_amount,
+			fee, currency, status, expires_at, execute_at, created_at, processed_at, error
+		FROM wallet_operations
+		WHERE reference_operation_id = $1 AND operation_type = 'REVERSAL'
+		ORDER BY created_at
+	`
+
+	if err := r.db.SelectContext(ctx, &reversals, query, operationID); err != nil {
+		return nil, fmt.Errorf("failed to get reversals from postgres: %w", err)
+	}
+
+	return reversals, nil
+}
+
+// GetHold get the hold created by a processed HOLD operation
+func (r *WalletRepository) GetHold(ctx context.Context, walletID, holdID string) (*models.Hold, error) {
+	var hold models.Hold
+
+	query := `
+		SELECT operation_id, wallet_id, amount, remaining_amount, status, expires_at, created_at, updated_at
+		FROM holds
+		WHERE wallet_id = $1 AND operation_id = $2
+	`
+
+	err := r.db.GetContext(ctx, &hold, query, walletID, holdID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrHoldNotFound
+		}
+		return nil, fmt.Errorf("failed to get hold from postgres: %w", err)
+	}
+
+	return &hold, nil
+}
+
 // WalletExists check the existence of a wallet
 func (r *WalletRepository) WalletExists(ctx context.Context, walletID string) (bool, error) {
 	var exists bool
@@ -106,22 +315,267 @@ func (r *WalletRepository) WalletExists(ctx context.Context, walletID string) (b
 	return exists, nil
 }
 
-// CreateOperation create a new operation with the status PENDING
-func (r *WalletRepository) CreateOperation(ctx context.Context, walletID, operationType string, amount int64) (string, error) {
-	operationID := uuid.New().String()
+// CreateOperation create a new operation with the status PENDING,
+// or SCHEDULED when the operation has an execution time
+func (r *WalletRepository) CreateOperation(ctx context.Context, operation models.WalletOperation) (string, error) {
+	operation.ID = uuid.New().String()
+
+	operation.Status = models.OperationStatusPending
+	if operation.ExecuteAt != nil {
+		operation.Status = models.OperationStatusScheduled
+	}
+
+	if err := insertOperation(ctx, r.db, operation); err != nil {
+		return "", err
+	}
+
+	return operation.ID, nil
+}
 
+// insertOperation insert an operation with its ID and status already set.
+// It returns ErrIdempotencyKeyExists when the wallet already has an operation with the same idempotency key.
+func insertOperation(ctx context.Context, db sqlx.ExecerContext, operation models.WalletOperation) error {
 	query := `
 		INSERT INTO wallet_operations 
-		(id, wallet_id, operation_type, amount, status, created_at)
-		VALUES ($1, $2, $3, $4, 'PENDING', NOW())
+		(id, wallet_id, destination_wallet_id, reference_operation_id, operation_type, amount, currency,
+		 status, expires_at, execute_at, standing_order_id, processed_at, idempotency_key, request_hash,
+		 description, external_ref, metadata, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NOW())
+		ON CONFLICT (wallet_id, idempotency_key) DO NOTHING
 	`
 
-	_, err := r.db.ExecContext(ctx, query, operationID, walletID, operationType, amount)
+	result, err := db.ExecContext(ctx, query, operationInsertArgs(operation)...)
+	if err != nil {
+		return fmt.Errorf("failed to create operation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrIdempotencyKeyExists
+	}
+
+	return nil
+}
+
+// operationInsertArgs returns the values of an operation in the column order of insertOperation
+func operationInsertArgs(operation models.WalletOperation) []interface{} {
+	return []interface{}{
+		operation.ID,
+		operation.WalletID,
+		operation.DestinationWalletID,
+		operation.ReferenceOperationID,
+		operation.OperationType,
+		operation.Amount,
+		operation.Currency,
+		operation.Status,
+		operation.ExpiresAt,
+		operation.ExecuteAt,
+		operation.StandingOrderID,
+		operation.ProcessedAt,
+		operation.IdempotencyKey,
+		operation.RequestHash,
+		operation.Description,
+		operation.ExternalRef,
+		operation.Metadata,
+		operation.CreatedBy,
+	}
+}
+
+// CreateOperations create operations with a single multi-row insert, setting their ID and
+// status like CreateOperation. Operations whose idempotency key already exists on the wallet
+// are not inserted; the returned set holds the IDs of the operations that were.
+func (r *WalletRepository) CreateOperations(ctx context.Context, operations []models.WalletOperation) (map[string]bool, error) {
+	if len(operations) == 0 {
+		return map[string]bool{}, nil
+	}
+
+	var (
+		rows []string
+		args []interface{}
+	)
+	for i := range operations {
+		operations[i].ID = uuid.New().String()
+		operations[i].Status = models.OperationStatusPending
+		if operations[i].ExecuteAt != nil {
+			operations[i].Status = models.OperationStatusScheduled
+		}
+
+		values := operationInsertArgs(operations[i])
+		placeholders := make([]string, len(values))
+		for j := range values {
+			placeholders[j] = fmt.Sprintf("$%d", len(args)+j+1)
+		}
+		rows = append(rows, "("+strings.Join(placeholders, ", ")+", NOW())")
+		args = append(args, values...)
+	}
+
+	query := `
+		INSERT INTO wallet_operations 
+		(id, wallet_id, destination_wallet_id, reference_operation_id, operation_type, amount, currency,
+		 status, expires_at, execute_at, standing_order_id, processed_at, idempotency_key, request_hash,
+		 description, external_ref, metadata, created_by, created_at)
+		VALUES ` + strings.Join(rows, ", ") + `
+		ON CONFLICT (wallet_id, idempotency_key) DO NOTHING
+		RETURNING id
+	`
+
+	var ids []string
+	if err := r.db.SelectContext(ctx, &ids, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to create operations: %w", err)
+	}
+
+	inserted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		inserted[id] = true
+	}
+
+	return inserted, nil
+}
+
+// GetOperationByIdempotencyKey get the operation of a wallet created with an idempotency key
+func (r *WalletRepository) GetOperationByIdempotencyKey(ctx context.Context, walletID, idempotencyKey string) (*models.WalletOperation, error) {
+	var operation models.WalletOperation
+
+	query := `
+		SELECT id, wallet_id, operation_type, amount, currency, status, execute_at, idempotency_key, request_hash, created_at
+		FROM wallet_operations
+		WHERE wallet_id = $1 AND idempotency_key = $2
+	`
+
+	err := r.db.GetContext(ctx, &operation, query, walletID, idempotencyKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOperationNotFound
+		}
+		return nil, fmt.Errorf("failed to get operation from postgres: %w", err)
+	}
+
+	return &operation, nil
+}
+
+// ExpireIdempotencyKeys clear the idempotency keys of up to limit operations created
+// before the given time, so the keys can be used again, and return how many were cleared
+func (r *WalletRepository) ExpireIdempotencyKeys(ctx context.Context, before time.Time, limit int) (int, error) {
+	query := `
+		UPDATE wallet_operations SET idempotency_key = NULL, request_hash = NULL
+		WHERE id IN (
+			SELECT id FROM wallet_operations
+			WHERE idempotency_key IS NOT NULL AND created_at < $1
+			LIMIT $2
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire idempotency keys: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// ClaimDueOperations moves scheduled operations whose execution time has come to PENDING
+// and returns them. Rows claimed by another scheduler replica are skipped, so every
+// operation is claimed by one replica at a time. Operations claimed before staleBefore
+// and never marked published are claimed again, so a replica that crashed between the
+// claim and the publication does not leave them PENDING forever.
+func (r *WalletRepository) ClaimDueOperations(ctx context.Context, now, staleBefore time.Time, limit int) ([]models.WalletOperation, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// Every column is read: the operation is published from the claimed row
+	query := `
+		SELECT ` + operationColumns + `
+		FROM wallet_operations
+		WHERE (status = 'SCHEDULED' AND execute_at <= $1)
+			OR (status = 'PENDING' AND claimed_at <= $2)
+		ORDER BY execute_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	`
+
+	var operations []models.WalletOperation
+	if err := tx.SelectContext(ctx, &operations, query, now, staleBefore, limit); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to select due operations: %w", err)
+	}
+	if len(operations) == 0 {
+		return operations, tx.Commit()
+	}
+
+	operationIDs := make([]string, len(operations))
+	for i, op := range operations {
+		operationIDs[i] = op.ID
+	}
+
+	update, args, err := sqlx.In(`UPDATE wallet_operations SET status = 'PENDING', claimed_at = ? WHERE id IN (?)`, now, operationIDs)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(update), args...); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to claim due operations: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for i := range operations {
+		operations[i].Status = models.OperationStatusPending
+	}
+
+	return operations, nil
+}
+
+// MarkOperationsPublished clears the claim of scheduled operations sent to Kafka,
+// so they are not claimed again
+func (r *WalletRepository) MarkOperationsPublished(ctx context.Context, operationIDs []string) error {
+	if len(operationIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(`UPDATE wallet_operations SET claimed_at = NULL WHERE id IN (?)`, operationIDs)
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to mark operations published: %w", err)
+	}
+
+	return nil
+}
+
+// CancelScheduledOperation cancel an operation that is still scheduled.
+// It returns false when the operation does not exist or is no longer scheduled.
+func (r *WalletRepository) CancelScheduledOperation(ctx context.Context, walletID, operationID string) (bool, error) {
+	query := `
+		UPDATE wallet_operations
+		SET status = 'CANCELLED', processed_at = NOW()
+		WHERE wallet_id = $1 AND id = $2 AND status = 'SCHEDULED'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, walletID, operationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel operation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return "", fmt.Errorf("failed to create operation: %w", err)
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
-	return operationID, nil
+	return rowsAffected > 0, nil
 }
 
 // UpdateOperationStatus update the operation status
@@ -0,0 +1,152 @@
+package postgresrepo
+
+import (
+	"context"
+	"fmt"
+
+	"wallet-service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// UpdateWalletStatus change the status of a wallet and record the reason
+// if its status is still one of fromStatuses. It returns false when it is not.
+func (r *WalletRepository) UpdateWalletStatus(ctx context.Context, walletID string, fromStatuses []string, status, reason string) (bool, error) {
+	query, args, err := sqlx.In(`
+		UPDATE wallets SET status = ?, status_reason = ?, status_changed_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND status IN (?)
+	`, status, reason, walletID, fromStatuses)
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update wallet status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// CloseWallet close a wallet in one transaction. The wallet and the sweep destination
+// are locked in the same order as in the operation worker, then check decides whether
+// the wallet may be closed. A wallet with a positive balance is frozen instead and a
+// pending TRANSFER of the whole balance to the destination is created and returned;
+// the worker closes the wallet once it processes the transfer. nil means the wallet
+// was closed at once. Scheduled operations of the wallet are cancelled and its
+// standing orders completed either way. createdBy is recorded as the creator of the sweep.
+func (r *WalletRepository) CloseWallet(
+	ctx context.Context,
+	walletID string,
+	sweepDestinationID *string,
+	reason string,
+	createdBy *string,
+	check func(wallet models.Wallet, destination *models.Wallet) error,
+) (*models.WalletOperation, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	walletIDs := []string{walletID}
+	if sweepDestinationID != nil {
+		walletIDs = append(walletIDs, *sweepDestinationID)
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT id, balance, held_amount, credit_limit, daily_withdraw_limit, monthly_withdraw_limit, currency,
+			status, status_reason, status_changed_at, created_at, updated_at
+		FROM wallets
+		WHERE id IN (?)
+		ORDER BY id
+		FOR UPDATE
+	`, walletIDs)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var locked []models.Wallet
+	if err := tx.SelectContext(ctx, &locked, tx.Rebind(query), args...); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to lock wallets: %w", err)
+	}
+
+	var wallet, destination *models.Wallet
+	for i := range locked {
+		switch {
+		case locked[i].ID == walletID:
+			wallet = &locked[i]
+		case sweepDestinationID != nil && locked[i].ID == *sweepDestinationID:
+			destination = &locked[i]
+		}
+	}
+	if wallet == nil {
+		tx.Rollback()
+		return nil, ErrWalletNotFound
+	}
+
+	if err := check(*wallet, destination); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// The frozen wallet takes no other operation, so the balance stays as swept
+	status := models.WalletStatusClosed
+	var sweep *models.WalletOperation
+	if wallet.Balance > 0 && destination != nil {
+		status = models.WalletStatusFrozen
+		destinationID := destination.ID
+		sweep = &models.WalletOperation{
+			ID:                  uuid.New().String(),
+			WalletID:            wallet.ID,
+			DestinationWalletID: &destinationID,
+			OperationType:       models.OperationTypeTransfer,
+			Amount:              wallet.Balance,
+			Currency:            wallet.Currency,
+			Status:              models.OperationStatusPending,
+			CreatedBy:           createdBy,
+		}
+		if err := insertOperation(ctx, tx, *sweep); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{
+			`UPDATE wallets SET status = $1, status_reason = $2, status_changed_at = NOW(), updated_at = NOW() WHERE id = $3`,
+			[]interface{}{status, reason, walletID},
+		},
+		{
+			`UPDATE wallet_operations SET status = 'CANCELLED', processed_at = NOW() WHERE wallet_id = $1 AND status = 'SCHEDULED'`,
+			[]interface{}{walletID},
+		},
+		{
+			`UPDATE standing_orders SET status = 'COMPLETED', next_run_at = NULL, updated_at = NOW()
+			 WHERE wallet_id = $1 AND status IN ('ACTIVE', 'PAUSED')`,
+			[]interface{}{walletID},
+		},
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to close wallet: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return sweep, nil
+}
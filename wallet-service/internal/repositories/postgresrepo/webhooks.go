@@ -0,0 +1,221 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"wallet-service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const webhookColumns = `id, url, event_types, wallet_id, secret, created_at`
+
+const webhookDeliveryColumns = `
+	id, subscription_id, event_type, wallet_id, data, status, attempts, next_attempt_at,
+	last_status_code, last_error, created_at, delivered_at
+`
+
+// CreateWebhook create a new webhook subscription and return it
+func (r *WalletRepository) CreateWebhook(ctx context.Context, subscription models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	var created models.WebhookSubscription
+
+	query := `
+		INSERT INTO webhook_subscriptions (id, url, event_types, wallet_id, secret, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING ` + webhookColumns
+
+	err := r.db.GetContext(ctx, &created, query,
+		uuid.New().String(),
+		subscription.URL,
+		subscription.EventTypes,
+		subscription.WalletID,
+		subscription.Secret,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetWebhook get a webhook subscription by ID
+func (r *WalletRepository) GetWebhook(ctx context.Context, webhookID string) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+
+	query := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions WHERE id = $1`
+	if err := r.db.GetContext(ctx, &subscription, query, webhookID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook from postgres: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+// ListWebhooks get all webhook subscriptions in creation order
+func (r *WalletRepository) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+
+	query := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &subscriptions, query); err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// DeleteWebhook delete a webhook subscription together with its deliveries
+func (r *WalletRepository) DeleteWebhook(ctx context.Context, webhookID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, webhookID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ClaimWebhookDeliveries claim up to limit pending deliveries that are due at now, with
+// the URL and secret of their subscription. The next attempt of a claimed delivery is
+// moved to leaseUntil, so other replicas skip it and it is retried if this one dies
+// before recording the result. Nothing stays locked while the deliveries are sent.
+func (r *WalletRepository) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM webhook_subscriptions s
+		WHERE d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		) AND s.id = d.subscription_id
+		RETURNING d.id, d.subscription_id, d.event_type, d.wallet_id, d.data, d.status, d.attempts, d.next_attempt_at,
+			d.last_status_code, d.last_error, d.created_at, d.delivered_at, s.url, s.secret
+	`
+
+	var deliveries []models.WebhookDelivery
+	if err := r.db.SelectContext(ctx, &deliveries, query, now, leaseUntil, limit); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// UpdateWebhookDelivery record the result of a delivery attempt
+func (r *WalletRepository) UpdateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5, delivered_at = $6
+		WHERE id = $7
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeadWebhookDeliveries get up to filter.Limit dead deliveries, newest first
+func (r *WalletRepository) ListDeadWebhookDeliveries(ctx context.Context, filter models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"status = 'DEAD'"}
+	if filter.WebhookID != "" {
+		conditions = append(conditions, "subscription_id = "+arg(filter.WebhookID))
+	}
+	if filter.AfterCreatedAt != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(*filter.AfterCreatedAt), arg(filter.AfterID)))
+	}
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE ` + strings.Join(conditions, " AND ") +
+		` ORDER BY created_at DESC, id DESC LIMIT ` + arg(filter.Limit)
+
+	var deliveries []models.WebhookDelivery
+	if err := r.db.SelectContext(ctx, &deliveries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list dead webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayWebhookDelivery queue a dead delivery again with a fresh set of attempts.
+// It returns false when the delivery exists but is not dead.
+func (r *WalletRepository) ReplayWebhookDelivery(ctx context.Context, deliveryID int64) (bool, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'PENDING', attempts = 0, next_attempt_at = NOW()
+		WHERE id = $1 AND status = 'DEAD'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, deliveryID)
+	if err != nil {
+		return false, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected > 0 {
+		return true, nil
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM webhook_deliveries WHERE id = $1)`, deliveryID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check webhook delivery existence: %w", err)
+	}
+	if !exists {
+		return false, ErrDeliveryNotFound
+	}
+
+	return false, nil
+}
+
+// ReplayDeadWebhookDeliveries queue all dead deliveries of a webhook again and return how many
+func (r *WalletRepository) ReplayDeadWebhookDeliveries(ctx context.Context, webhookID string) (int, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'PENDING', attempts = 0, next_attempt_at = NOW()
+		WHERE subscription_id = $1 AND status = 'DEAD'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, webhookID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to replay webhook deliveries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,91 @@
+package redisrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"wallet-service/internal/models"
+)
+
+const (
+	// eventsChannel is the channel the operation worker publishes wallet events to,
+	// each message is the stream ID of the event and its JSON separated by a newline
+	eventsChannel = "wallet-events"
+	// eventReplayLimit is the number of events the worker keeps per wallet
+	eventReplayLimit = 1000
+)
+
+// SubscribeEvents call handle for every wallet event published by the operation worker
+// until ctx is done. go-redis resubscribes after a lost connection; events published
+// in the meantime are only available from the streams.
+func (r *WalletRepository) SubscribeEvents(ctx context.Context, handle func(event models.WalletEvent)) error {
+	pubsub := r.client.Subscribe(ctx, eventsChannel)
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to wallet events: %w", err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			id, payload, found := strings.Cut(message.Payload, "\n")
+			if !found {
+				continue
+			}
+			event, err := decodeEvent(id, payload)
+			if err != nil {
+				fmt.Printf("Skipping wallet event %s: %v\n", id, err)
+				continue
+			}
+			handle(event)
+		}
+	}
+}
+
+// GetEventsAfter get the events of a wallet that follow the event with lastID,
+// oldest first. Events trimmed from the stream are not returned.
+func (r *WalletRepository) GetEventsAfter(ctx context.Context, walletID, lastID string) ([]models.WalletEvent, error) {
+	messages, err := r.client.XRangeN(ctx, r.getEventsKey(walletID), lastID, "+", eventReplayLimit+1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events from redis: %w", err)
+	}
+
+	events := make([]models.WalletEvent, 0, len(messages))
+	for _, message := range messages {
+		// The range is inclusive, the client already has lastID
+		if message.ID == lastID {
+			continue
+		}
+		payload, _ := message.Values["event"].(string)
+		event, err := decodeEvent(message.ID, payload)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func decodeEvent(id, payload string) (models.WalletEvent, error) {
+	var event models.WalletEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return models.WalletEvent{}, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	event.ID = id
+
+	return event, nil
+}
+
+func (r *WalletRepository) getEventsKey(walletID string) string {
+	return r.prefix + walletID + ":events"
+}
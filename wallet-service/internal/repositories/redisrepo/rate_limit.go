@@ -0,0 +1,61 @@
+package redisrepo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// takeTokenScript refills the token bucket of KEYS[1] with ARGV[1] tokens per second
+// up to ARGV[2] tokens and takes one if it can. It reads the clock of Redis, so the
+// replicas of the service need not agree on the time. A bucket left alone until it
+// is full again expires, a missing bucket is a full one.
+// Returns whether a token was taken and the tokens left.
+var takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000 + math.floor(tonumber(clock[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * 1000 / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// TakeToken takes a token from the bucket of key, refilled with rate tokens per
+// second up to burst, and returns whether it could and the tokens left
+func (r *WalletRepository) TakeToken(ctx context.Context, key string, rate, burst int64) (bool, float64, error) {
+	values, err := takeTokenScript.Run(ctx, r.client, []string{"ratelimit:" + key}, rate, burst).Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to take rate limit token from redis: %w", err)
+	}
+	if len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	left, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid rate limit tokens %q: %w", left, err)
+	}
+
+	return allowed == 1, tokens, nil
+}
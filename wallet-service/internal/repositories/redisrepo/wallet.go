@@ -14,8 +14,16 @@ const (
 	expiration = 5 * time.Minute
 )
 
+const (
+	// A wallet's currency never changes, so it can be cached much longer than its balance
+	currencyExpiration = 24 * time.Hour
+)
+
 var (
-	ErrBalanceNotFound = errors.New("balance not found in cache")
+	ErrBalanceNotFound     = errors.New("balance not found in cache")
+	ErrCurrencyNotFound    = errors.New("currency not found in cache")
+	ErrCreditLimitNotFound = errors.New("credit limit not found in cache")
+	ErrStatusNotFound      = errors.New("status not found in cache")
 )
 
 type WalletRepository struct {
@@ -30,49 +38,130 @@ func NewWalletRepository(client *redis.Client) *WalletRepository {
 	}
 }
 
-func (r *WalletRepository) SetBalance(ctx context.Context, walletID string, balance int64) error {
-	key := r.getBalanceKey(walletID)
+// SetBalance caches the balance together with the amount reserved by holds
+func (r *WalletRepository) SetBalance(ctx context.Context, walletID string, balance, heldAmount int64) error {
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, r.getBalanceKey(walletID), strconv.FormatInt(balance, 10), expiration)
+	pipe.Set(ctx, r.getHeldKey(walletID), strconv.FormatInt(heldAmount, 10), expiration)
 
-	balanceStr := strconv.FormatInt(balance, 10)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set balance in redis: %w", err)
+	}
 
-	err := r.client.Set(ctx, key, balanceStr, expiration).Err()
+	return nil
+}
+
+// GetBalance returns the cached balance and held amount
+func (r *WalletRepository) GetBalance(ctx context.Context, walletID string) (int64, int64, error) {
+	values, err := r.client.MGet(ctx, r.getBalanceKey(walletID), r.getHeldKey(walletID)).Result()
 	if err != nil {
-		return fmt.Errorf("failed to set balance in redis: %w", err)
+		return 0, 0, fmt.Errorf("failed to get balance from redis: %w", err)
+	}
+
+	amounts := make([]int64, len(values))
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			return 0, 0, ErrBalanceNotFound
+		}
+
+		amounts[i], err = strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to parse balance from redis: %w", err)
+		}
+	}
+
+	return amounts[0], amounts[1], nil
+}
+
+func (r *WalletRepository) DeleteBalance(ctx context.Context, walletID string) error {
+	err := r.client.Del(ctx, r.getBalanceKey(walletID), r.getHeldKey(walletID)).Err()
+	if err != nil {
+		return fmt.Errorf("failed to delete balance from redis: %w", err)
 	}
 
 	return nil
 }
 
-func (r *WalletRepository) GetBalance(ctx context.Context, walletID string) (int64, error) {
-	key := r.getBalanceKey(walletID)
+func (r *WalletRepository) SetCurrency(ctx context.Context, walletID, currency string) error {
+	err := r.client.Set(ctx, r.getCurrencyKey(walletID), currency, currencyExpiration).Err()
+	if err != nil {
+		return fmt.Errorf("failed to set currency in redis: %w", err)
+	}
+
+	return nil
+}
 
-	balanceStr, err := r.client.Get(ctx, key).Result()
+func (r *WalletRepository) GetCurrency(ctx context.Context, walletID string) (string, error) {
+	currency, err := r.client.Get(ctx, r.getCurrencyKey(walletID)).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return 0, ErrBalanceNotFound
+			return "", ErrCurrencyNotFound
 		}
-		return 0, fmt.Errorf("failed to get balance from redis: %w", err)
+		return "", fmt.Errorf("failed to get currency from redis: %w", err)
 	}
 
-	balance, err := strconv.ParseInt(balanceStr, 10, 64)
+	return currency, nil
+}
+
+func (r *WalletRepository) SetCreditLimit(ctx context.Context, walletID string, creditLimit int64) error {
+	err := r.client.Set(ctx, r.getCreditLimitKey(walletID), strconv.FormatInt(creditLimit, 10), expiration).Err()
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse balance from redis: %w", err)
+		return fmt.Errorf("failed to set credit limit in redis: %w", err)
 	}
 
-	return balance, nil
+	return nil
 }
 
-func (r *WalletRepository) DeleteBalance(ctx context.Context, walletID string) error {
-	key := r.getBalanceKey(walletID)
+func (r *WalletRepository) GetCreditLimit(ctx context.Context, walletID string) (int64, error) {
+	creditLimit, err := r.client.Get(ctx, r.getCreditLimitKey(walletID)).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, ErrCreditLimitNotFound
+		}
+		return 0, fmt.Errorf("failed to get credit limit from redis: %w", err)
+	}
+
+	return creditLimit, nil
+}
 
-	err := r.client.Del(ctx, key).Err()
+func (r *WalletRepository) SetStatus(ctx context.Context, walletID, status string) error {
+	err := r.client.Set(ctx, r.getStatusKey(walletID), status, expiration).Err()
 	if err != nil {
-		return fmt.Errorf("failed to delete balance from redis: %w", err)
+		return fmt.Errorf("failed to set status in redis: %w", err)
 	}
 
 	return nil
 }
 
+func (r *WalletRepository) GetStatus(ctx context.Context, walletID string) (string, error) {
+	status, err := r.client.Get(ctx, r.getStatusKey(walletID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", ErrStatusNotFound
+		}
+		return "", fmt.Errorf("failed to get status from redis: %w", err)
+	}
+
+	return status, nil
+}
+
 func (r *WalletRepository) getBalanceKey(walletID string) string {
 	return r.prefix + walletID + ":balance"
 }
+
+func (r *WalletRepository) getHeldKey(walletID string) string {
+	return r.prefix + walletID + ":held"
+}
+
+func (r *WalletRepository) getCurrencyKey(walletID string) string {
+	return r.prefix + walletID + ":currency"
+}
+
+func (r *WalletRepository) getCreditLimitKey(walletID string) string {
+	return r.prefix + walletID + ":credit_limit"
+}
+
+func (r *WalletRepository) getStatusKey(walletID string) string {
+	return r.prefix + walletID + ":status"
+}
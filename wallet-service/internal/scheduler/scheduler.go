@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+	"wallet-service/internal/config"
+	"wallet-service/internal/services"
+)
+
+// Maximum number of scheduled operations, standing orders, idempotency keys or webhook deliveries handled per claim
+const batchSize = 100
+
+type Scheduler struct {
+	cfg           *config.Config
+	walletService *services.WalletService
+}
+
+func New(cfg *config.Config, walletService *services.WalletService) *Scheduler {
+	return &Scheduler{
+		cfg:           cfg,
+		walletService: walletService,
+	}
+}
+
+// Start periodically publishes scheduled operations and fires standing orders that are due,
+// releases expired idempotency keys and sends due webhook deliveries. It is safe to run
+// on every replica: each operation or delivery is claimed by exactly one of them.
+func (s *Scheduler) Start(ctx context.Context) {
+	if s.cfg.Scheduler.Interval <= 0 {
+		log.Println("Scheduler is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Scheduler.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			s.drain(ctx, "scheduled operations", s.walletService.FireDueOperations)
+			s.drain(ctx, "standing orders", s.walletService.FireDueStandingOrders)
+			s.drain(ctx, "expired idempotency keys", s.walletService.ExpireIdempotencyKeys)
+			s.drain(ctx, "webhook deliveries", s.walletService.DeliverWebhooks)
+		}
+	}
+}
+
+// drain calls fire until everything that is due has been fired
+func (s *Scheduler) drain(ctx context.Context, name string, fire func(ctx context.Context, now time.Time, limit int) (int, error)) {
+	for {
+		fired, err := fire(ctx, time.Now(), batchSize)
+		if err != nil {
+			log.Printf("Failed to fire %s: %v", name, err)
+			return
+		}
+		if fired > 0 {
+			log.Printf("Processed %d %s", fired, name)
+		}
+		// Continue while there may be more due items left
+		if fired < batchSize {
+			return
+		}
+	}
+}
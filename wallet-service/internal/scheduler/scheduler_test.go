@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name      string
+		fired     []int
+		err       error
+		wantCalls int
+	}{
+		{"nothing due", []int{0}, nil, 1},
+		{"one batch", []int{3}, nil, 1},
+		{"full batches", []int{batchSize, batchSize, 3}, nil, 3},
+		{"claim failure", []int{batchSize}, errors.New("database down"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			fire := func(ctx context.Context, now time.Time, limit int) (int, error) {
+				if limit != batchSize {
+					t.Errorf("limit = %d, want %d", limit, batchSize)
+				}
+				fired := tt.fired[calls]
+				calls++
+				return fired, tt.err
+			}
+
+			(&Scheduler{}).drain(context.Background(), "test", fire)
+			if calls != tt.wantCalls {
+				t.Errorf("fire was called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"wallet-service/internal/auth"
+	"wallet-service/internal/config"
+	"wallet-service/internal/models"
+	"wallet-service/internal/repositories/postgresrepo"
+)
+
+var (
+	ErrInvalidAPIKey       = errors.New("invalid API key")
+	ErrInvalidToken        = errors.New("invalid bearer token")
+	ErrWalletNotAllowed    = errors.New("wallet is not allowed for the API key")
+	ErrOperationNotAllowed = errors.New("operation type is not allowed for the caller")
+	ErrAPIKeyRevoked       = errors.New("API key is revoked")
+	ErrUnrestrictedScope   = errors.New("scope requires an unrestricted API key")
+	ErrInvalidAPIKeyExpiry = errors.New("API key expiry must be in the future")
+)
+
+// bootstrapPrincipalID identifies the callers authenticated with the bootstrap key
+const bootstrapPrincipalID = "bootstrap"
+
+// Authenticate returns the caller presenting key: the bootstrap key or a stored key
+// that is neither revoked nor expired
+func (s *WalletService) Authenticate(ctx context.Context, key string) (*auth.Principal, error) {
+	if key == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	keyHash := auth.HashKey(key)
+	if bootstrap := s.cfg.Auth.BootstrapKey; bootstrap != "" &&
+		subtle.ConstantTimeCompare([]byte(keyHash), []byte(auth.HashKey(bootstrap))) == 1 {
+		return &auth.Principal{ID: bootstrapPrincipalID, Scopes: []string{auth.ScopeAdmin}}, nil
+	}
+
+	apiKey, err := s.postgresRepo.GetActiveAPIKeyByHash(ctx, keyHash, time.Now())
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	return &auth.Principal{
+		ID:        apiKey.ID,
+		Scopes:    apiKey.Scopes,
+		WalletIDs: apiKey.WalletIDs,
+		OwnerIDs:  apiKey.OwnerIDs,
+	}, nil
+}
+
+// AuthenticateToken returns the caller presenting a JWT of the edge gateway.
+// Tokens are rejected when no key set is configured.
+func (s *WalletService) AuthenticateToken(ctx context.Context, token string) (*auth.Principal, error) {
+	if s.tokens == nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims, err := s.tokens.Verify(ctx, token, time.Now())
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+		return nil, err
+	}
+
+	return claims.Principal(s.cfg.Auth.JWTAdminRole), nil
+}
+
+// tokenVerifier returns the verifier of JWTs, nil when no key set is configured
+func tokenVerifier(cfg config.AuthConfig) *auth.TokenVerifier {
+	if cfg.JWKS == "" {
+		return nil
+	}
+	return auth.NewTokenVerifier(auth.NewKeySet(cfg.JWKS, cfg.JWKSRefresh), cfg.JWTIssuer, cfg.JWTAudience)
+}
+
+// AuthorizeWallet checks that the caller may access the wallet. A caller restricted
+// by owner needs the owner of the wallet, so it is read; a wallet that does not
+// exist is not allowed, so restricted callers cannot probe for wallets.
+func (s *WalletService) AuthorizeWallet(ctx context.Context, principal *auth.Principal, walletID string) error {
+	if principal.AllowsWallet(walletID, nil) {
+		return nil
+	}
+	if len(principal.OwnerIDs) == 0 {
+		return ErrWalletNotAllowed
+	}
+
+	wallet, err := s.postgresRepo.GetWallet(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			return ErrWalletNotAllowed
+		}
+		return err
+	}
+	if !principal.AllowsWallet(walletID, wallet.OwnerID) {
+		return ErrWalletNotAllowed
+	}
+
+	return nil
+}
+
+// authorizeOperation checks that the caller of ctx, if any, may create operations of
+// the type. Operations created by the service itself, e.g. by standing orders, carry
+// no caller.
+func authorizeOperation(ctx context.Context, operationType string) error {
+	if principal := auth.FromContext(ctx); principal != nil && !principal.AllowsOperation(operationType) {
+		return fmt.Errorf("%w: %s", ErrOperationNotAllowed, operationType)
+	}
+	return nil
+}
+
+// CreateAPIKey creates an API key. The key is only returned in the response:
+// just its hash is stored.
+func (s *WalletService) CreateAPIKey(ctx context.Context, req models.APIKeyRequest) (*models.APIKeyResponse, error) {
+	restricted := len(req.WalletIDs) > 0 || len(req.OwnerIDs) > 0
+	if restricted && (slices.Contains(req.Scopes, auth.ScopeWebhooks) || slices.Contains(req.Scopes, auth.ScopeAdmin)) {
+		return nil, ErrUnrestrictedScope
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+
+	key, prefix, err := auth.NewKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := models.APIKey{
+		Name:      req.Name,
+		KeyPrefix: prefix,
+		KeyHash:   auth.HashKey(key),
+		Scopes:    req.Scopes,
+		WalletIDs: req.WalletIDs,
+		OwnerIDs:  req.OwnerIDs,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if apiKey.WalletIDs == nil {
+		apiKey.WalletIDs = []string{}
+	}
+	if apiKey.OwnerIDs == nil {
+		apiKey.OwnerIDs = []string{}
+	}
+
+	created, err := s.postgresRepo.CreateAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	response := apiKeyResponse(*created)
+	response.Key = key
+	return response, nil
+}
+
+// GetAPIKey returns an API key without the key itself
+func (s *WalletService) GetAPIKey(ctx context.Context, keyID string) (*models.APIKeyResponse, error) {
+	apiKey, err := s.postgresRepo.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKeyResponse(*apiKey), nil
+}
+
+// ListAPIKeys returns all API keys, revoked ones included
+func (s *WalletService) ListAPIKeys(ctx context.Context) (*models.APIKeyListResponse, error) {
+	apiKeys, err := s.postgresRepo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.APIKeyListResponse{APIKeys: make([]models.APIKeyResponse, 0, len(apiKeys))}
+	for _, apiKey := range apiKeys {
+		response.APIKeys = append(response.APIKeys, *apiKeyResponse(apiKey))
+	}
+
+	return response, nil
+}
+
+// RotateAPIKey issues a new key for an API key, keeping its ID, scopes and restrictions.
+// The replaced key keeps working for the configured grace period.
+func (s *WalletService) RotateAPIKey(ctx context.Context, keyID string) (*models.APIKeyResponse, error) {
+	apiKey, err := s.postgresRepo.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	key, prefix, err := auth.NewKey()
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.postgresRepo.RotateAPIKey(ctx, keyID, prefix, auth.HashKey(key), time.Now().Add(s.cfg.Auth.RotationGrace))
+	if err != nil {
+		// Revoked between the read and the update
+		if errors.Is(err, postgresrepo.ErrAPIKeyNotFound) {
+			return nil, ErrAPIKeyRevoked
+		}
+		return nil, err
+	}
+
+	response := apiKeyResponse(*rotated)
+	response.Key = key
+	return response, nil
+}
+
+// RevokeAPIKey revokes an API key for good; revoking it again changes nothing
+func (s *WalletService) RevokeAPIKey(ctx context.Context, keyID string) (*models.APIKeyResponse, error) {
+	revoked, err := s.postgresRepo.RevokeAPIKey(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	return apiKeyResponse(*revoked), nil
+}
+
+// apiKeyResponse builds the response of an API key without the key itself
+func apiKeyResponse(apiKey models.APIKey) *models.APIKeyResponse {
+	response := &models.APIKeyResponse{
+		APIKeyID:  apiKey.ID,
+		Name:      apiKey.Name,
+		KeyPrefix: apiKey.KeyPrefix,
+		Scopes:    apiKey.Scopes,
+		WalletIDs: apiKey.WalletIDs,
+		OwnerIDs:  apiKey.OwnerIDs,
+		ExpiresAt: apiKey.ExpiresAt,
+		CreatedAt: apiKey.CreatedAt,
+		RotatedAt: apiKey.RotatedAt,
+		RevokedAt: apiKey.RevokedAt,
+	}
+	// A previous key whose grace period is over is no longer worth reporting
+	if apiKey.PreviousKeyExpiresAt != nil && apiKey.PreviousKeyExpiresAt.After(time.Now()) {
+		response.PreviousKeyExpiresAt = apiKey.PreviousKeyExpiresAt
+	}
+
+	return response
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"wallet-service/internal/models"
+)
+
+var (
+	ErrBatchRejected = errors.New("another operation of the batch failed")
+)
+
+// OperationResult is the outcome of one operation of a batch. An operation that was
+// created but could not be queued has both an OperationID and an Err.
+type OperationResult struct {
+	OperationID string
+	Replayed    bool
+	Err         error
+}
+
+// CreateOperations creates a batch of operations with one insert and one Kafka write and
+// returns the result of every request in request order. Each request is checked and
+// deduplicated by its idempotency key like in CreateOperation. With atomic set nothing
+// is created when any request fails its checks; the other requests fail with ErrBatchRejected.
+func (s *WalletService) CreateOperations(ctx context.Context, reqs []models.WalletOperationRequest, atomic bool) ([]OperationResult, error) {
+	results := make([]OperationResult, len(reqs))
+	hashes := make([]string, len(reqs))
+
+	var (
+		operations []models.WalletOperation
+		indexes    []int // request index of each operation
+		failed     bool
+	)
+	for i, req := range reqs {
+		if req.IdempotencyKey != "" {
+			hashes[i] = hashRequest(req)
+			operationID, found, err := s.replayOperation(ctx, req.WalletID, req.IdempotencyKey, hashes[i])
+			if err != nil {
+				results[i].Err = err
+				failed = true
+				continue
+			}
+			if found {
+				results[i] = OperationResult{OperationID: operationID, Replayed: true}
+				continue
+			}
+		}
+
+		operation, err := s.buildOperation(ctx, req)
+		if err != nil {
+			results[i].Err = err
+			failed = true
+			continue
+		}
+		if req.IdempotencyKey != "" {
+			operation.IdempotencyKey = &reqs[i].IdempotencyKey
+			operation.RequestHash = &hashes[i]
+		}
+
+		operations = append(operations, *operation)
+		indexes = append(indexes, i)
+	}
+
+	if atomic && failed {
+		for _, i := range indexes {
+			results[i].Err = ErrBatchRejected
+		}
+		return results, nil
+	}
+
+	inserted, err := s.postgresRepo.CreateOperations(ctx, operations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create operations: %w", err)
+	}
+
+	var (
+		kafkaMsgs []models.KafkaMessage
+		published []int // request index of each message
+	)
+	for j, operation := range operations {
+		i := indexes[j]
+
+		if !inserted[operation.ID] {
+			// A concurrent request, or an earlier item of the batch, used the same key
+			operationID, found, err := s.replayOperation(ctx, operation.WalletID, *operation.IdempotencyKey, hashes[i])
+			if err == nil && !found {
+				err = fmt.Errorf("operation with idempotency key %q not found", *operation.IdempotencyKey)
+			}
+			results[i] = OperationResult{OperationID: operationID, Replayed: found, Err: err}
+			continue
+		}
+
+		results[i].OperationID = operation.ID
+		// Scheduled operations are published by the scheduler when they are due
+		if operation.ExecuteAt == nil {
+			kafkaMsgs = append(kafkaMsgs, kafkaMessageFor(operation))
+			published = append(published, i)
+		}
+	}
+
+	// In case of Kafka error, mark the operations that were not written as FAILED
+	for k, err := range s.kafkaRepo.SendOperations(ctx, kafkaMsgs) {
+		if err == nil {
+			continue
+		}
+		i := published[k]
+		if updateErr := s.postgresRepo.UpdateOperationStatus(ctx, results[i].OperationID, "FAILED", fmt.Sprintf("Kafka error: %v", err)); updateErr != nil {
+			fmt.Printf("Failed to update operation status after Kafka error: %v\n", updateErr)
+		}
+		results[i].Err = fmt.Errorf("failed to send operation to queue: %w", err)
+	}
+
+	return results, nil
+}
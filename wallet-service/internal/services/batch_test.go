@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"wallet-service/internal/config"
+	"wallet-service/internal/models"
+	"wallet-service/internal/repositories/postgresrepo"
+)
+
+// returnedID matches the ID of an inserted operation and returns it from the insert,
+// as RETURNING id does, unless skip marks it as a conflicting idempotency key
+type returnedID struct {
+	rows *sqlmock.Rows
+	skip bool
+}
+
+func (r returnedID) Match(v driver.Value) bool {
+	id, ok := v.(string)
+	if ok && !r.skip {
+		r.rows.AddRow(id)
+	}
+	return ok
+}
+
+// expectBatchInsert expects the operations to be inserted by one statement, each with
+// its 18 values; the inserted IDs are returned except those skipped
+func expectBatchInsert(mock sqlmock.Sqlmock, skip ...bool) {
+	rows := sqlmock.NewRows([]string{"id"})
+	var args []driver.Value
+	for _, skipped := range skip {
+		args = append(args, returnedID{rows: rows, skip: skipped})
+		for range 17 {
+			args = append(args, sqlmock.AnyArg())
+		}
+	}
+	mock.ExpectQuery(`INSERT INTO wallet_operations .* ON CONFLICT \(wallet_id, idempotency_key\) DO NOTHING RETURNING id`).
+		WithArgs(args...).
+		WillReturnRows(rows)
+}
+
+func expectWallet(mock sqlmock.Sqlmock, wallet models.Wallet) {
+	mock.ExpectQuery(`SELECT .* FROM wallets WHERE id = \$1`).
+		WithArgs(wallet.ID).
+		WillReturnRows(walletRows(wallet))
+}
+
+func expectNoWallet(mock sqlmock.Sqlmock, walletID string) {
+	mock.ExpectQuery(`SELECT .* FROM wallets WHERE id = \$1`).
+		WithArgs(walletID).
+		WillReturnError(sql.ErrNoRows)
+}
+
+func depositRequest(walletID string) models.WalletOperationRequest {
+	return models.WalletOperationRequest{WalletID: walletID, OperationType: models.OperationTypeDeposit, Amount: 100}
+}
+
+func TestCreateOperationsAtomic(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+
+	expectWallet(mock, activeWallet("w-1", "EUR"))
+	expectNoWallet(mock, "w-2")
+
+	results, err := s.CreateOperations(context.Background(), []models.WalletOperationRequest{
+		depositRequest("w-1"),
+		depositRequest("w-2"),
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is created: the valid operation is rejected along with the invalid one
+	if !errors.Is(results[0].Err, ErrBatchRejected) || results[0].OperationID != "" {
+		t.Errorf("result 0 = %+v, want %v", results[0], ErrBatchRejected)
+	}
+	if !errors.Is(results[1].Err, postgresrepo.ErrWalletNotFound) {
+		t.Errorf("result 1 = %+v, want %v", results[1], postgresrepo.ErrWalletNotFound)
+	}
+	if sent := fake.sent(); len(sent) != 0 {
+		t.Errorf("sent %+v, want nothing", sent)
+	}
+}
+
+func TestCreateOperationsPerItem(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+
+	expectWallet(mock, activeWallet("w-1", "EUR"))
+	expectNoWallet(mock, "w-2")
+	expectWallet(mock, activeWallet("w-3", "EUR"))
+	expectBatchInsert(mock, false, false)
+
+	results, err := s.CreateOperations(context.Background(), []models.WalletOperationRequest{
+		depositRequest("w-1"),
+		depositRequest("w-2"),
+		depositRequest("w-3"),
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The invalid operation fails alone, the others are created and queued together
+	if results[0].Err != nil || results[0].OperationID == "" || results[2].Err != nil || results[2].OperationID == "" {
+		t.Errorf("results = %+v, want operations 0 and 2 created", results)
+	}
+	if !errors.Is(results[1].Err, postgresrepo.ErrWalletNotFound) {
+		t.Errorf("result 1 = %+v, want %v", results[1], postgresrepo.ErrWalletNotFound)
+	}
+
+	sent := fake.sent()
+	if len(sent) != 2 || sent[0].OperationID != results[0].OperationID || sent[1].OperationID != results[2].OperationID {
+		t.Errorf("sent %+v, want operations 0 and 2", sent)
+	}
+	if fake.requests != 1 {
+		t.Errorf("sent %d Kafka requests, want 1", fake.requests)
+	}
+}
+
+func TestCreateOperationsReplay(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	replayed := withdrawRequest("key-1", 100)
+	duplicate := withdrawRequest("key-2", 100)
+
+	// key-1 was used before the batch, key-2 by a concurrent request during it
+	expectIdempotencyLookup(mock, "key-1", "op-1", hashRequest(replayed))
+	expectIdempotencyLookup(mock, "key-2", "", "")
+	expectWallet(mock, activeWallet("w-1", "EUR"))
+	expectBatchInsert(mock, true)
+	expectIdempotencyLookup(mock, "key-2", "op-2", hashRequest(duplicate))
+
+	results, err := s.CreateOperations(context.Background(), []models.WalletOperationRequest{replayed, duplicate}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []string{"op-1", "op-2"} {
+		if results[i].Err != nil || results[i].OperationID != want || !results[i].Replayed {
+			t.Errorf("result %d = %+v, want %s replayed", i, results[i], want)
+		}
+	}
+	if sent := fake.sent(); len(sent) != 0 {
+		t.Errorf("sent %+v, want nothing", sent)
+	}
+}
+
+func TestCreateOperationsKafkaFailure(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	fake.err = errors.New("broker down")
+
+	expectWallet(mock, activeWallet("w-1", "EUR"))
+	expectBatchInsert(mock, false)
+	mock.ExpectExec(`UPDATE wallet_operations SET status = \$1`).
+		WithArgs("FAILED", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	results, err := s.CreateOperations(context.Background(), []models.WalletOperationRequest{depositRequest("w-1")}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The operation was created, so its ID is returned with the error
+	if results[0].OperationID == "" || results[0].Err == nil {
+		t.Errorf("result = %+v, want the created operation with the Kafka error", results[0])
+	}
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"wallet-service/internal/models"
+	"wallet-service/internal/repositories/postgresrepo"
+)
+
+// SubscribeWalletEvents subscribes to the events of a wallet. With lastEventID it also
+// returns the events committed after it, read once the subscription is in place so
+// nothing is lost in between; live events up to the last replayed one are duplicates.
+// The returned function ends the subscription.
+func (s *WalletService) SubscribeWalletEvents(ctx context.Context, walletID, lastEventID string) ([]models.WalletEvent, <-chan models.WalletEvent, func(), error) {
+	walletExists, err := s.postgresRepo.WalletExists(ctx, walletID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to check wallet existence: %w", err)
+	}
+	if !walletExists {
+		return nil, nil, nil, postgresrepo.ErrWalletNotFound
+	}
+
+	events, cancel := s.eventHub.Subscribe(walletID)
+	if lastEventID == "" {
+		return nil, events, cancel, nil
+	}
+
+	missed, err := s.redisRepo.GetEventsAfter(ctx, walletID, lastEventID)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+
+	return missed, events, cancel, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"wallet-service/internal/models"
+	"wallet-service/internal/repositories/postgresrepo"
+)
+
+var (
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different request")
+)
+
+// hashRequest returns the SHA-256 of an operation request without its idempotency key
+func hashRequest(req models.WalletOperationRequest) string {
+	req.IdempotencyKey = ""
+
+	// Marshalling a struct is deterministic, so equal requests hash equally
+	payload, _ := json.Marshal(req)
+	sum := sha256.Sum256(payload)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// replayOperation looks up the operation created with the idempotency key on the wallet.
+// found is false when the key has not been used or has expired.
+func (s *WalletService) replayOperation(ctx context.Context, walletID, idempotencyKey, requestHash string) (string, bool, error) {
+	operation, err := s.postgresRepo.GetOperationByIdempotencyKey(ctx, walletID, idempotencyKey)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrOperationNotFound) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	if operation.RequestHash == nil || *operation.RequestHash != requestHash {
+		return "", false, ErrIdempotencyKeyReused
+	}
+
+	return operation.ID, true, nil
+}
+
+// ExpireIdempotencyKeys releases up to limit idempotency keys older than the retention
+// period and returns how many were released
+func (s *WalletService) ExpireIdempotencyKeys(ctx context.Context, now time.Time, limit int) (int, error) {
+	if s.cfg.Idempotency.Retention <= 0 {
+		return 0, nil
+	}
+
+	expired, err := s.postgresRepo.ExpireIdempotencyKeys(ctx, now.Add(-s.cfg.Idempotency.Retention), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire idempotency keys: %w", err)
+	}
+
+	return expired, nil
+}
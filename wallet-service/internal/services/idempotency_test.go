@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"wallet-service/internal/config"
+	"wallet-service/internal/models"
+)
+
+// expectIdempotencyLookup expects the operation created with the key on w-1 to be
+// looked up; an empty operationID means the key is unused
+func expectIdempotencyLookup(mock sqlmock.Sqlmock, key, operationID, requestHash string) {
+	rows := sqlmock.NewRows([]string{
+		"id", "wallet_id", "operation_type", "amount", "currency", "status", "execute_at", "idempotency_key", "request_hash", "created_at",
+	})
+	if operationID != "" {
+		rows.AddRow(operationID, "w-1", models.OperationTypeWithdraw, 100, "EUR", models.OperationStatusPending, nil, key, requestHash, time.Now())
+	}
+	mock.ExpectQuery(`FROM wallet_operations WHERE wallet_id = \$1 AND idempotency_key = \$2`).
+		WithArgs("w-1", key).
+		WillReturnRows(rows)
+}
+
+func withdrawRequest(key string, amount int64) models.WalletOperationRequest {
+	return models.WalletOperationRequest{
+		WalletID:       "w-1",
+		OperationType:  models.OperationTypeWithdraw,
+		Amount:         amount,
+		IdempotencyKey: key,
+	}
+}
+
+func TestHashRequest(t *testing.T) {
+	if hashRequest(withdrawRequest("key-1", 100)) != hashRequest(withdrawRequest("key-2", 100)) {
+		t.Error("the hash depends on the idempotency key")
+	}
+	if hashRequest(withdrawRequest("key-1", 100)) == hashRequest(withdrawRequest("key-1", 200)) {
+		t.Error("requests with different amounts hash equally")
+	}
+}
+
+func TestCreateOperationStoresIdempotencyKey(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	req := withdrawRequest("key-1", 100)
+
+	expectIdempotencyLookup(mock, "key-1", "", "")
+	mock.ExpectQuery(`SELECT .* FROM wallets WHERE id = \$1`).
+		WithArgs("w-1").
+		WillReturnRows(walletRows(activeWallet("w-1", "EUR")))
+	mock.ExpectExec(`INSERT INTO wallet_operations`).
+		WithArgs(sqlmock.AnyArg(), "w-1", nil, nil, models.OperationTypeWithdraw, int64(100), "EUR",
+			models.OperationStatusPending, nil, nil, nil, nil, "key-1", hashRequest(req), nil, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	operationID, replayed, err := s.CreateOperation(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed {
+		t.Error("a new operation is reported as replayed")
+	}
+	if sent := fake.sent(); len(sent) != 1 || sent[0].OperationID != operationID {
+		t.Errorf("sent %+v, want operation %s", sent, operationID)
+	}
+}
+
+func TestCreateOperationReplaysIdempotentRequest(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	req := withdrawRequest("key-1", 100)
+
+	// The original operation is returned without reading the wallet or creating anything
+	expectIdempotencyLookup(mock, "key-1", "op-1", hashRequest(req))
+
+	operationID, replayed, err := s.CreateOperation(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if operationID != "op-1" || !replayed {
+		t.Errorf("got %q replayed=%v, want op-1 replayed", operationID, replayed)
+	}
+	if sent := fake.sent(); len(sent) != 0 {
+		t.Errorf("sent %+v, want nothing", sent)
+	}
+}
+
+func TestCreateOperationIdempotencyKeyReused(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+
+	expectIdempotencyLookup(mock, "key-1", "op-1", hashRequest(withdrawRequest("key-1", 100)))
+
+	_, _, err := s.CreateOperation(context.Background(), withdrawRequest("key-1", 200))
+	if !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Fatalf("err = %v, want %v", err, ErrIdempotencyKeyReused)
+	}
+	if sent := fake.sent(); len(sent) != 0 {
+		t.Errorf("sent %+v, want nothing", sent)
+	}
+}
+
+func TestCreateOperationConcurrentIdempotentRequest(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	req := withdrawRequest("key-1", 100)
+
+	// A concurrent request inserts the key between the lookup and the insert
+	expectIdempotencyLookup(mock, "key-1", "", "")
+	mock.ExpectQuery(`SELECT .* FROM wallets WHERE id = \$1`).
+		WithArgs("w-1").
+		WillReturnRows(walletRows(activeWallet("w-1", "EUR")))
+	mock.ExpectExec(`INSERT INTO wallet_operations .* ON CONFLICT \(wallet_id, idempotency_key\) DO NOTHING`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectIdempotencyLookup(mock, "key-1", "op-1", hashRequest(req))
+
+	operationID, replayed, err := s.CreateOperation(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if operationID != "op-1" || !replayed {
+		t.Errorf("got %q replayed=%v, want the operation of the concurrent request", operationID, replayed)
+	}
+	if sent := fake.sent(); len(sent) != 0 {
+		t.Errorf("sent %+v, want nothing", sent)
+	}
+}
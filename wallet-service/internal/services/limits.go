@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wallet-service/internal/models"
+)
+
+// Withdraw limits cap the outflows of a wallet within a rolling window.
+// They are enforced by the operation worker; the API only reports usage.
+const (
+	limitNameDailyWithdraw   = "daily withdraw"
+	limitNameMonthlyWithdraw = "monthly withdraw"
+
+	dailyWindow   = 24 * time.Hour
+	monthlyWindow = 30 * 24 * time.Hour
+)
+
+// limitedOperationTypes are the operation types that count against withdraw limits
+var limitedOperationTypes = []string{
+	models.OperationTypeWithdraw,
+	models.OperationTypeTransfer,
+	models.OperationTypeCapture,
+}
+
+// GetWithdrawLimits returns the withdraw limits of a wallet and their current usage
+func (s *WalletService) GetWithdrawLimits(ctx context.Context, walletID string) (*models.WalletLimitsResponse, error) {
+	wallet, err := s.postgresRepo.GetWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := []struct {
+		name         string
+		window       time.Duration
+		walletLimit  *int64
+		defaultLimit int64
+	}{
+		{limitNameDailyWithdraw, dailyWindow, wallet.DailyWithdrawLimit, s.cfg.Limits.DailyWithdraw},
+		{limitNameMonthlyWithdraw, monthlyWindow, wallet.MonthlyWithdrawLimit, s.cfg.Limits.MonthlyWithdraw},
+	}
+
+	now := time.Now()
+	response := &models.WalletLimitsResponse{
+		WalletID: wallet.ID,
+		Currency: wallet.Currency,
+		Limits:   make([]models.LimitResponse, 0, len(limits)),
+	}
+
+	for _, l := range limits {
+		used, err := s.postgresRepo.SumOperationsSince(ctx, walletID, limitedOperationTypes, now.Add(-l.window))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s usage: %w", l.name, err)
+		}
+
+		limit := models.LimitResponse{
+			Name:    l.name,
+			Window:  l.window.String(),
+			Limit:   l.defaultLimit,
+			Used:    used,
+			Default: l.walletLimit == nil,
+		}
+		if l.walletLimit != nil {
+			limit.Limit = *l.walletLimit
+		}
+		if limit.Limit > 0 && used < limit.Limit {
+			limit.Remaining = limit.Limit - used
+		}
+
+		response.Limits = append(response.Limits, limit)
+	}
+
+	return response, nil
+}
+
+// SetWithdrawLimits replaces the withdraw limits of a wallet and returns the resulting usage
+func (s *WalletService) SetWithdrawLimits(ctx context.Context, walletID string, req models.WithdrawLimitsRequest) (*models.WalletLimitsResponse, error) {
+	if err := s.postgresRepo.UpdateWithdrawLimits(ctx, walletID, req.DailyWithdraw, req.MonthlyWithdraw); err != nil {
+		return nil, err
+	}
+
+	return s.GetWithdrawLimits(ctx, walletID)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"wallet-service/internal/models"
+	"wallet-service/internal/pagination"
+	"wallet-service/internal/repositories/postgresrepo"
+)
+
+// ListWalletOperations returns a page of the operations of a wallet, including transfers
+// to it, that match the filter, newest first
+func (s *WalletService) ListWalletOperations(ctx context.Context, walletID string, filter models.OperationFilter, cursor string) (*models.OperationListResponse, error) {
+	walletExists, err := s.postgresRepo.WalletExists(ctx, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check wallet existence: %w", err)
+	}
+	if !walletExists {
+		return nil, postgresrepo.ErrWalletNotFound
+	}
+
+	filter.WalletID = walletID
+	return s.ListOperations(ctx, filter, cursor)
+}
+
+// ListOperations returns a page of the operations matching the filter, newest first.
+// cursor is the nextCursor of the previous page, empty for the first page.
+func (s *WalletService) ListOperations(ctx context.Context, filter models.OperationFilter, cursor string) (*models.OperationListResponse, error) {
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		filter.AfterCreatedAt = &after.CreatedAt
+		filter.AfterID = after.ID
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	// One more operation than asked tells whether there is a next page
+	filter.Limit = limit + 1
+	operations, err := s.postgresRepo.ListOperations(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.OperationListResponse{Operations: make([]models.OperationStatusResponse, 0, len(operations))}
+	if len(operations) > limit {
+		operations = operations[:limit]
+		last := operations[limit-1]
+		response.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	for _, operation := range operations {
+		response.Operations = append(response.Operations, *operationStatusResponse(operation))
+	}
+
+	return response, nil
+}
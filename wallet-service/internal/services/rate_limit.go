@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"wallet-service/internal/auth"
+	"wallet-service/internal/ratelimit"
+)
+
+var (
+	ErrRateLimited            = errors.New("rate limit exceeded")
+	ErrRateLimiterUnavailable = errors.New("rate limiter unavailable")
+)
+
+// LimitClient takes a token from the bucket of the caller. The result is nil when
+// clients are not limited or, failing open, when Redis is unavailable; it is
+// returned with ErrRateLimited when the bucket is empty.
+func (s *WalletService) LimitClient(ctx context.Context, principal *auth.Principal) (*ratelimit.Result, error) {
+	cfg := s.cfg.RateLimit
+	return s.takeToken(ctx, "client:"+principal.ID, ratelimit.NewLimit(cfg.ClientRate, cfg.ClientBurst))
+}
+
+// LimitWallet takes a token from the bucket of the wallet, like LimitClient
+func (s *WalletService) LimitWallet(ctx context.Context, walletID string) (*ratelimit.Result, error) {
+	cfg := s.cfg.RateLimit
+	return s.takeToken(ctx, "wallet:"+walletID, ratelimit.NewLimit(cfg.WalletRate, cfg.WalletBurst))
+}
+
+func (s *WalletService) takeToken(ctx context.Context, key string, limit ratelimit.Limit) (*ratelimit.Result, error) {
+	if !limit.Enabled() {
+		return nil, nil
+	}
+
+	allowed, tokens, err := s.redisRepo.TakeToken(ctx, key, limit.Rate, limit.Burst)
+	if err != nil {
+		if s.cfg.RateLimit.FailOpen {
+			fmt.Printf("Rate limiter unavailable, letting the request through: %v\n", err)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRateLimiterUnavailable, err)
+	}
+
+	result := ratelimit.NewResult(limit, allowed, tokens)
+	if !allowed {
+		return &result, ErrRateLimited
+	}
+
+	return &result, nil
+}
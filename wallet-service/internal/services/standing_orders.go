@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"wallet-service/internal/auth"
+	"wallet-service/internal/currency"
+	"wallet-service/internal/models"
+	"wallet-service/internal/recurrence"
+)
+
+var (
+	ErrStandingOrderNoOccurrences = errors.New("standing order has no occurrences")
+	ErrStandingOrderNotActive     = errors.New("standing order is not active")
+	ErrStandingOrderNotPaused     = errors.New("standing order is not paused")
+)
+
+// CreateStandingOrder creates a standing order of a wallet. Every occurrence becomes
+// a regular operation that the scheduler queues for the worker; balance, limits and
+// fees are checked when the occurrence is processed.
+func (s *WalletService) CreateStandingOrder(ctx context.Context, walletID string, req models.StandingOrderRequest) (*models.StandingOrderResponse, error) {
+	if err := authorizeOperation(ctx, req.OperationType); err != nil {
+		return nil, err
+	}
+
+	rule, err := recurrence.Parse(req.Schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.postgresRepo.GetWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkActive(wallet); err != nil {
+		return nil, err
+	}
+
+	// The order is always denominated in the wallet currency
+	if req.Currency != "" && req.Currency != wallet.Currency {
+		return nil, fmt.Errorf("%w: order in %s, wallet in %s", ErrCurrencyMismatch, req.Currency, wallet.Currency)
+	}
+
+	order := models.StandingOrder{
+		WalletID:       walletID,
+		OperationType:  req.OperationType,
+		Amount:         req.Amount,
+		Currency:       wallet.Currency,
+		Schedule:       req.Schedule,
+		Status:         models.StandingOrderStatusActive,
+		StartAt:        time.Now(),
+		EndAt:          req.EndAt,
+		MaxOccurrences: req.MaxOccurrences,
+		CreatedBy:      auth.CallerID(ctx),
+	}
+	if req.StartAt != nil {
+		order.StartAt = *req.StartAt
+	}
+
+	if req.OperationType == models.OperationTypeTransfer {
+		if err := s.checkDestination(ctx, wallet, req.DestinationWalletID); err != nil {
+			return nil, err
+		}
+		order.DestinationWalletID = &req.DestinationWalletID
+	}
+
+	// Occurrences before now are skipped when the order starts in the past
+	after := order.StartAt
+	if now := time.Now(); after.Before(now) {
+		after = now
+	}
+	order.NextRunAt = nextRunAfter(order, rule, rule.First(order.StartAt), after.Add(-time.Nanosecond))
+	if order.NextRunAt == nil {
+		return nil, ErrStandingOrderNoOccurrences
+	}
+
+	created, err := s.postgresRepo.CreateStandingOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return standingOrderResponse(*created), nil
+}
+
+// GetStandingOrder returns a standing order of a wallet
+func (s *WalletService) GetStandingOrder(ctx context.Context, walletID, orderID string) (*models.StandingOrderResponse, error) {
+	order, err := s.postgresRepo.GetStandingOrder(ctx, walletID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return standingOrderResponse(*order), nil
+}
+
+// PauseStandingOrder stops an active standing order from firing until it is resumed
+func (s *WalletService) PauseStandingOrder(ctx context.Context, walletID, orderID string) (*models.StandingOrderResponse, error) {
+	order, err := s.postgresRepo.GetStandingOrder(ctx, walletID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	paused, err := s.postgresRepo.UpdateStandingOrderStatus(ctx, walletID, orderID,
+		[]string{models.StandingOrderStatusActive}, models.StandingOrderStatusPaused, order.NextRunAt)
+	if err != nil {
+		return nil, err
+	}
+	if !paused {
+		return nil, ErrStandingOrderNotActive
+	}
+
+	return s.GetStandingOrder(ctx, walletID, orderID)
+}
+
+// ResumeStandingOrder reactivates a paused standing order.
+// Occurrences that fell due while it was paused are skipped.
+func (s *WalletService) ResumeStandingOrder(ctx context.Context, walletID, orderID string) (*models.StandingOrderResponse, error) {
+	order, err := s.postgresRepo.GetStandingOrder(ctx, walletID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.StandingOrderStatusPaused || order.NextRunAt == nil {
+		return nil, ErrStandingOrderNotPaused
+	}
+
+	rule, err := recurrence.Parse(order.Schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.StandingOrderStatusActive
+	nextRunAt := nextRunAfter(*order, rule, *order.NextRunAt, time.Now())
+	if nextRunAt == nil {
+		status = models.StandingOrderStatusCompleted
+	}
+
+	resumed, err := s.postgresRepo.UpdateStandingOrderStatus(ctx, walletID, orderID,
+		[]string{models.StandingOrderStatusPaused}, status, nextRunAt)
+	if err != nil {
+		return nil, err
+	}
+	if !resumed {
+		return nil, ErrStandingOrderNotPaused
+	}
+
+	return s.GetStandingOrder(ctx, walletID, orderID)
+}
+
+// DeleteStandingOrder stops a standing order for good. The order and its
+// execution history stay readable; deleting it again is a no-op.
+func (s *WalletService) DeleteStandingOrder(ctx context.Context, walletID, orderID string) error {
+	if _, err := s.postgresRepo.GetStandingOrder(ctx, walletID, orderID); err != nil {
+		return err
+	}
+
+	_, err := s.postgresRepo.UpdateStandingOrderStatus(ctx, walletID, orderID,
+		[]string{models.StandingOrderStatusActive, models.StandingOrderStatusPaused, models.StandingOrderStatusCompleted},
+		models.StandingOrderStatusDeleted, nil)
+
+	return err
+}
+
+// GetStandingOrderHistory returns the operations created by a standing order, newest first
+func (s *WalletService) GetStandingOrderHistory(ctx context.Context, walletID, orderID string) (*models.StandingOrderHistoryResponse, error) {
+	if _, err := s.postgresRepo.GetStandingOrder(ctx, walletID, orderID); err != nil {
+		return nil, err
+	}
+
+	operations, err := s.postgresRepo.GetStandingOrderOperations(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.StandingOrderHistoryResponse{
+		StandingOrderID: orderID,
+		Executions:      make([]models.StandingOrderExecution, 0, len(operations)),
+	}
+	for _, operation := range operations {
+		response.Executions = append(response.Executions, models.StandingOrderExecution{
+			OperationID: operation.ID,
+			Amount:      operation.Amount,
+			Fee:         operation.Fee,
+			Status:      operation.Status,
+			CreatedAt:   operation.CreatedAt,
+			ProcessedAt: operation.ProcessedAt,
+			Error:       operation.Error,
+		})
+	}
+
+	return response, nil
+}
+
+// FireDueStandingOrders creates and publishes the operations of up to limit standing
+// orders whose next run has come and returns how many were fired
+func (s *WalletService) FireDueStandingOrders(ctx context.Context, now time.Time, limit int) (int, error) {
+	operations, err := s.postgresRepo.FireDueStandingOrders(ctx, now, limit, func(order *models.StandingOrder) {
+		advanceStandingOrder(order, now)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fire standing orders: %w", err)
+	}
+
+	// An operation that fails to publish is marked FAILED, the others are still sent
+	for _, operation := range operations {
+		if err := s.publishOperation(ctx, kafkaMessageFor(operation)); err != nil {
+			fmt.Printf("Failed to publish operation %s of standing order %s: %v\n", operation.ID, *operation.StandingOrderID, err)
+		}
+	}
+
+	return len(operations), nil
+}
+
+// advanceStandingOrder records a fired occurrence and moves the order to its next
+// occurrence after now. Occurrences missed while the scheduler was not running are
+// collapsed into the one just fired.
+func advanceStandingOrder(order *models.StandingOrder, now time.Time) {
+	order.Occurrences++
+
+	rule, err := recurrence.Parse(order.Schedule)
+	if err != nil {
+		// Schedules are validated on creation, so this only guards against manual edits
+		fmt.Printf("Standing order %s has an invalid schedule, completing it: %v\n", order.ID, err)
+		order.NextRunAt = nil
+	} else {
+		order.NextRunAt = nextRunAfter(*order, rule, *order.NextRunAt, now)
+	}
+
+	if order.NextRunAt == nil {
+		order.Status = models.StandingOrderStatusCompleted
+	}
+}
+
+// nextRunAfter returns the first occurrence of the order after t, stepping the rule
+// forward from the occurrence from, or nil when the order has no occurrences left
+func nextRunAfter(order models.StandingOrder, rule recurrence.Rule, from, t time.Time) *time.Time {
+	next := from
+	for !next.IsZero() && !next.After(t) {
+		next = rule.Next(next)
+	}
+
+	if next.IsZero() ||
+		order.EndAt != nil && next.After(*order.EndAt) ||
+		order.MaxOccurrences != nil && order.Occurrences >= *order.MaxOccurrences {
+		return nil
+	}
+
+	return &next
+}
+
+// standingOrderResponse builds a standing order response with the amount formatted in major units
+func standingOrderResponse(order models.StandingOrder) *models.StandingOrderResponse {
+	response := &models.StandingOrderResponse{
+		StandingOrderID:     order.ID,
+		WalletID:            order.WalletID,
+		DestinationWalletID: order.DestinationWalletID,
+		OperationType:       order.OperationType,
+		Amount:              order.Amount,
+		Currency:            order.Currency,
+		Schedule:            order.Schedule,
+		Status:              order.Status,
+		StartAt:             order.StartAt,
+		EndAt:               order.EndAt,
+		MaxOccurrences:      order.MaxOccurrences,
+		Occurrences:         order.Occurrences,
+		NextRunAt:           order.NextRunAt,
+		CreatedBy:           order.CreatedBy,
+		CreatedAt:           order.CreatedAt,
+		UpdatedAt:           order.UpdatedAt,
+	}
+
+	if c, err := currency.Lookup(order.Currency); err == nil {
+		response.FormattedAmount = c.Format(order.Amount)
+	}
+
+	return response
+}
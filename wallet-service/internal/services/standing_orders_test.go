@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"wallet-service/internal/config"
+	"wallet-service/internal/models"
+)
+
+func intptr(i int) *int { return &i }
+
+func TestFireDueStandingOrders(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	dueAt := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	// Orders locked by another replica are skipped, so each occurrence fires once
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM standing_orders WHERE status = 'ACTIVE' AND next_run_at <= \$1 ORDER BY next_run_at LIMIT \$2 FOR UPDATE SKIP LOCKED`).
+		WithArgs(now, 10).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "wallet_id", "destination_wallet_id", "operation_type", "amount", "currency", "schedule", "status",
+			"start_at", "end_at", "max_occurrences", "occurrences", "next_run_at", "created_by", "created_at", "updated_at",
+		}).AddRow(
+			"order-1", "w-1", "w-2", models.OperationTypeTransfer, 500, "EUR", "@daily", models.StandingOrderStatusActive,
+			dueAt, nil, nil, 3, dueAt, "key-1", dueAt, dueAt,
+		))
+	mock.ExpectExec(`INSERT INTO wallet_operations`).
+		WithArgs(sqlmock.AnyArg(), "w-1", "w-2", nil, models.OperationTypeTransfer, int64(500), "EUR",
+			models.OperationStatusPending, nil, nil, "order-1", nil, nil, nil, nil, nil, nil, "key-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The occurrences missed since the 15th collapse into the one fired now
+	mock.ExpectExec(`UPDATE standing_orders SET occurrences = \$1, next_run_at = \$2, status = \$3`).
+		WithArgs(4, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), models.StandingOrderStatusActive, "order-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	fired, err := s.FireDueStandingOrders(context.Background(), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired != 1 {
+		t.Errorf("fired %d standing orders, want 1", fired)
+	}
+
+	sent := fake.sent()
+	if len(sent) != 1 || sent[0].WalletID != "w-1" || sent[0].DestinationWalletID != "w-2" || sent[0].Amount != 500 {
+		t.Errorf("sent %+v, want the transfer of the order", sent)
+	}
+}
+
+func TestAdvanceStandingOrder(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	dueAt := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	endAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	nextDay := dueAt.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name       string
+		order      models.StandingOrder
+		wantNext   *time.Time
+		wantStatus string
+	}{
+		{
+			name:       "next day",
+			order:      models.StandingOrder{Schedule: "@daily", Status: models.StandingOrderStatusActive, NextRunAt: &dueAt},
+			wantNext:   &nextDay,
+			wantStatus: models.StandingOrderStatusActive,
+		},
+		{
+			name:       "last occurrence",
+			order:      models.StandingOrder{Schedule: "@daily", Status: models.StandingOrderStatusActive, NextRunAt: &dueAt, MaxOccurrences: intptr(1)},
+			wantStatus: models.StandingOrderStatusCompleted,
+		},
+		{
+			name:       "past the end",
+			order:      models.StandingOrder{Schedule: "@daily", Status: models.StandingOrderStatusActive, NextRunAt: &dueAt, EndAt: &endAt},
+			wantStatus: models.StandingOrderStatusCompleted,
+		},
+		{
+			name:       "invalid schedule",
+			order:      models.StandingOrder{Schedule: "every day", Status: models.StandingOrderStatusActive, NextRunAt: &dueAt},
+			wantStatus: models.StandingOrderStatusCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := tt.order
+			advanceStandingOrder(&order, now)
+
+			if order.Occurrences != 1 || order.Status != tt.wantStatus {
+				t.Errorf("got %d occurrences, status %s, want 1, %s", order.Occurrences, order.Status, tt.wantStatus)
+			}
+			if (order.NextRunAt == nil) != (tt.wantNext == nil) || order.NextRunAt != nil && !order.NextRunAt.Equal(*tt.wantNext) {
+				t.Errorf("next run at %v, want %v", order.NextRunAt, tt.wantNext)
+			}
+		})
+	}
+}
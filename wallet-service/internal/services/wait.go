@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"wallet-service/internal/models"
+)
+
+// WaitForOperation returns the status of an operation once the worker has processed or
+// failed it, or its current status when the timeout elapses first; done reports which.
+// It waits for the events of the wallet rather than polling, so no database connection
+// is held while waiting. The status is read after subscribing, so an operation that
+// finishes in between is not missed.
+func (s *WalletService) WaitForOperation(ctx context.Context, walletID, operationID string, timeout time.Duration) (*models.OperationStatusResponse, bool, error) {
+	events, cancel := s.eventHub.Subscribe(walletID)
+	defer cancel()
+
+	status, err := s.GetOperation(ctx, walletID, operationID)
+	if err != nil {
+		return nil, false, err
+	}
+	if operationFinished(status.Status) {
+		return status, true, nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			return status, false, ctx.Err()
+		case <-timer.C:
+			break wait
+		case event, ok := <-events:
+			// A dropped subscription ends the wait early, the status is read below
+			if !ok || finishesOperation(event, operationID) {
+				break wait
+			}
+		}
+	}
+
+	// Events carry no reversals or metadata, so the final status is read again.
+	// The event may also have been missed, which this read catches as well.
+	status, err = s.GetOperation(ctx, walletID, operationID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return status, operationFinished(status.Status), nil
+}
+
+// operationFinished reports whether an operation will not change its status any more
+func operationFinished(status string) bool {
+	switch status {
+	case models.OperationStatusProcessed, models.OperationStatusFailed, models.OperationStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// finishesOperation reports whether event is the final status of the operation
+func finishesOperation(event models.WalletEvent, operationID string) bool {
+	if event.Type != models.EventTypeOperation {
+		return false
+	}
+
+	var data models.OperationEvent
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return false
+	}
+
+	return data.OperationID == operationID && operationFinished(data.Status)
+}
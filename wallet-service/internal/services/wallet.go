@@ -2,44 +2,86 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	"wallet-service/internal/auth"
+	"wallet-service/internal/config"
+	"wallet-service/internal/currency"
+	"wallet-service/internal/events"
 	"wallet-service/internal/models"
+	"wallet-service/internal/problem"
 	"wallet-service/internal/repositories/kafkarepo"
 	"wallet-service/internal/repositories/postgresrepo"
 	"wallet-service/internal/repositories/redisrepo"
+	"wallet-service/internal/webhook"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrDestinationWalletNotFound = errors.New("destination wallet not found")
+	ErrCurrencyMismatch          = errors.New("currency mismatch")
+	ErrHoldNotActive             = errors.New("hold is not active")
+	ErrAmountExceedsHold         = errors.New("amount exceeds held amount")
+	ErrInvalidHoldExpiry         = errors.New("hold expiry must be in the future")
+	ErrOperationNotReversible    = errors.New("operation cannot be reversed")
+	ErrOperationNotProcessed     = errors.New("operation is not processed")
+	ErrAmountExceedsReversible   = errors.New("amount exceeds reversible amount")
+	ErrInvalidExecuteAt          = errors.New("execution time must be in the future")
+	ErrNotSchedulable            = errors.New("only DEPOSIT, WITHDRAW and TRANSFER can be scheduled")
+	ErrOperationNotCancellable   = errors.New("operation is not scheduled")
+	ErrWalletFrozen              = errors.New("wallet is frozen")
+	ErrWalletClosed              = errors.New("wallet is closed")
+	ErrDestinationWalletInactive = errors.New("destination wallet is not active")
+)
+
+// scheduledClaimLease is how long a claimed scheduled operation may stay unpublished
+// before another scheduler run claims and publishes it again
+const scheduledClaimLease = time.Minute
+
+// reversibleTypes are the operation types that can be undone by a REVERSAL
+var reversibleTypes = map[string]bool{
+	models.OperationTypeDeposit:  true,
+	models.OperationTypeWithdraw: true,
+	models.OperationTypeTransfer: true,
+	models.OperationTypeCapture:  true,
+}
+
 type WalletService struct {
+	cfg          *config.Config
 	postgresRepo *postgresrepo.WalletRepository
 	kafkaRepo    *kafkarepo.OperationRepository
 	redisRepo    *redisrepo.WalletRepository
+	eventHub     *events.Hub
+	webhooks     *webhook.Client
+	tokens       *auth.TokenVerifier // nil when JWTs are disabled
 }
 
-func NewWalletService(postgresRepo *postgresrepo.WalletRepository, redisRepo *redisrepo.WalletRepository, kafkaRepo *kafkarepo.OperationRepository) *WalletService {
+func NewWalletService(cfg *config.Config, postgresRepo *postgresrepo.WalletRepository, redisRepo *redisrepo.WalletRepository, kafkaRepo *kafkarepo.OperationRepository, eventHub *events.Hub) *WalletService {
 	return &WalletService{
+		cfg:          cfg,
 		postgresRepo: postgresRepo,
 		kafkaRepo:    kafkaRepo,
 		redisRepo:    redisRepo,
+		eventHub:     eventHub,
+		webhooks:     webhook.NewClient(cfg.Webhook.Timeout),
+		tokens:       tokenVerifier(cfg.Auth),
 	}
 }
 
 func (s *WalletService) GetWalletBalance(ctx context.Context, walletID string) (*models.WalletBalanceResponse, error) {
-	// Try to get balance from Redis cache first
-	balance, err := s.redisRepo.GetBalance(ctx, walletID)
+	// Try to get balance, currency and credit limit from Redis cache first
+	cached, err := s.getCachedWallet(ctx, walletID)
 	if err == nil {
-		return &models.WalletBalanceResponse{
-			WalletID: walletID,
-			Balance:  balance,
-		}, nil
+		return balanceResponse(*cached)
 	}
 
-	// If Redis error is not "balance not found", log it but continue to PostgreSQL
-	if !errors.Is(err, redisrepo.ErrBalanceNotFound) {
+	// If Redis error is not a cache miss, log it but continue to PostgreSQL
+	if !errors.Is(err, redisrepo.ErrBalanceNotFound) && !errors.Is(err, redisrepo.ErrCurrencyNotFound) &&
+		!errors.Is(err, redisrepo.ErrCreditLimitNotFound) && !errors.Is(err, redisrepo.ErrStatusNotFound) {
 		fmt.Printf("Redis cache error (non-critical): %v\n", err)
 	}
 
@@ -54,30 +96,117 @@ func (s *WalletService) GetWalletBalance(ctx context.Context, walletID string) (
 		cacheCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		if err := s.redisRepo.SetBalance(cacheCtx, walletID, wallet.Balance); err != nil {
+		if err := s.redisRepo.SetBalance(cacheCtx, walletID, wallet.Balance, wallet.HeldAmount); err != nil {
+			fmt.Printf("Failed to update redis cache for wallet %s: %v\n", walletID, err)
+		}
+		if err := s.redisRepo.SetCurrency(cacheCtx, walletID, wallet.Currency); err != nil {
+			fmt.Printf("Failed to update redis cache for wallet %s: %v\n", walletID, err)
+		}
+		if err := s.redisRepo.SetCreditLimit(cacheCtx, walletID, wallet.CreditLimit); err != nil {
+			fmt.Printf("Failed to update redis cache for wallet %s: %v\n", walletID, err)
+		}
+		if err := s.redisRepo.SetStatus(cacheCtx, walletID, wallet.Status); err != nil {
 			fmt.Printf("Failed to update redis cache for wallet %s: %v\n", walletID, err)
 		}
 	}()
 
 	// Return balance from PostgreSQL
-	return &models.WalletBalanceResponse{
-		WalletID: walletID,
-		Balance:  wallet.Balance,
+	return balanceResponse(*wallet)
+}
+
+// getCachedWallet assembles the cached parts of a wallet
+func (s *WalletService) getCachedWallet(ctx context.Context, walletID string) (*models.Wallet, error) {
+	balance, heldAmount, err := s.redisRepo.GetBalance(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := s.redisRepo.GetCurrency(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	creditLimit, err := s.redisRepo.GetCreditLimit(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.redisRepo.GetStatus(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Wallet{
+		ID:          walletID,
+		Balance:     balance,
+		HeldAmount:  heldAmount,
+		CreditLimit: creditLimit,
+		Currency:    code,
+		Status:      status,
 	}, nil
 }
 
-func (s *WalletService) CreateWallet(ctx context.Context) (*models.WalletBalanceResponse, error) {
-	walletID := uuid.New().String()
+// SetCreditLimit sets how far below zero the wallet may go.
+// Lowering the limit under the current debt only blocks further debits.
+func (s *WalletService) SetCreditLimit(ctx context.Context, walletID string, creditLimit int64) (*models.WalletBalanceResponse, error) {
+	wallet, err := s.postgresRepo.UpdateCreditLimit(ctx, walletID, creditLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.redisRepo.SetCreditLimit(ctx, walletID, wallet.CreditLimit); err != nil {
+		fmt.Printf("Failed to update redis cache for wallet %s: %v\n", walletID, err)
+	}
+
+	return balanceResponse(*wallet)
+}
+
+// CreateWallet creates a wallet. When the owner already has a wallet with the same
+// external reference, that wallet is returned with status "exists" instead.
+func (s *WalletService) CreateWallet(ctx context.Context, req models.WalletCreateRequest) (*models.WalletCreateResponse, error) {
+	code := req.Currency
+	if code == "" {
+		code = currency.Default
+	}
+	if _, err := currency.Lookup(code); err != nil {
+		return nil, err
+	}
+
+	wallet := models.Wallet{
+		ID:       uuid.New().String(),
+		Currency: code,
+		Status:   models.WalletStatusActive,
+		Labels:   req.Labels,
+	}
+	if req.OwnerID != "" {
+		wallet.OwnerID = &req.OwnerID
+	}
+	if req.ExternalRef != "" {
+		wallet.ExternalRef = &req.ExternalRef
+	}
+	if req.DisplayName != "" {
+		wallet.DisplayName = &req.DisplayName
+	}
 
 	// Create wallet in PostgreSQL
-	if err := s.postgresRepo.CreateWallet(ctx, walletID); err != nil {
+	created, err := s.postgresRepo.CreateWallet(ctx, wallet)
+	if err != nil {
 		return nil, fmt.Errorf("failed to create wallet: %w", err)
 	}
+	if created {
+		return walletCreateResponse(wallet, "created", models.MessageWalletCreated), nil
+	}
 
-	return &models.WalletBalanceResponse{
-		WalletID: walletID,
-		Balance:  0,
-	}, nil
+	// A retried creation: return the wallet created by the first attempt
+	existing, err := s.postgresRepo.GetWalletByExternalRef(ctx, req.OwnerID, req.ExternalRef)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Currency != code {
+		return nil, fmt.Errorf("%w: existing wallet in %s", ErrExternalRefConflict, existing.Currency)
+	}
+
+	return walletCreateResponse(*existing, "exists", models.MessageWalletExists), nil
 }
 
 func (s *WalletService) GetOperation(ctx context.Context, walletID, operationID string) (*models.OperationStatusResponse, error) {
@@ -97,53 +226,503 @@ func (s *WalletService) GetOperation(ctx context.Context, walletID, operationID
 	}
 
 	// Convert to response model
-	response := &models.OperationStatusResponse{
-		OperationID:   operation.ID,
-		WalletID:      operation.WalletID,
-		OperationType: operation.OperationType,
-		Amount:        operation.Amount,
-		Status:        operation.Status,
-		ProcessedAt:   operation.ProcessedAt,
-		Error:         operation.Error,
+	response := operationStatusResponse(*operation)
+
+	// An operation that can be reversed lists its reversals
+	if reversibleTypes[operation.OperationType] {
+		reversals, err := s.postgresRepo.GetReversals(ctx, operation.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reversals: %w", err)
+		}
+		response.ReversedAmount = operation.ReversedAmount
+		for _, reversal := range reversals {
+			response.Reversals = append(response.Reversals, models.ReversalResponse{
+				OperationID: reversal.ID,
+				Amount:      reversal.Amount,
+				Status:      reversal.Status,
+				CreatedAt:   reversal.CreatedAt,
+				ProcessedAt: reversal.ProcessedAt,
+				Error:       reversal.Error,
+			})
+		}
+	}
+
+	// A processed HOLD reports how much of it is still reserved
+	if operation.OperationType == models.OperationTypeHold && operation.Status == models.OperationStatusProcessed {
+		hold, err := s.postgresRepo.GetHold(ctx, operation.WalletID, operation.ID)
+		if err != nil && !errors.Is(err, postgresrepo.ErrHoldNotFound) {
+			return nil, fmt.Errorf("failed to get hold: %w", err)
+		}
+		if hold != nil {
+			response.Hold = &models.HoldResponse{
+				Amount:          hold.Amount,
+				RemainingAmount: hold.RemainingAmount,
+				Status:          hold.Status,
+				ExpiresAt:       hold.ExpiresAt,
+			}
+		}
 	}
 
 	return response, nil
 }
 
-// CreateOperation creates an operation and sends it to Kafka
-func (s *WalletService) CreateOperation(ctx context.Context, req models.WalletOperationRequest) (string, error) {
-	// Check if wallet exists
-	exists, err := s.postgresRepo.WalletExists(ctx, req.WalletID)
+// operationStatusResponse builds the status of an operation from its own row,
+// without the reversals and holds it refers to
+func operationStatusResponse(operation models.WalletOperation) *models.OperationStatusResponse {
+	response := &models.OperationStatusResponse{
+		OperationID:     operation.ID,
+		WalletID:        operation.WalletID,
+		OperationType:   operation.OperationType,
+		Amount:          operation.Amount,
+		Fee:             operation.Fee,
+		Currency:        operation.Currency,
+		Status:          operation.Status,
+		ProcessedAt:     operation.ProcessedAt,
+		ExecuteAt:       operation.ExecuteAt,
+		Error:           operation.Error,
+		StandingOrderID: operation.StandingOrderID,
+		Description:     operation.Description,
+		ExternalRef:     operation.ExternalRef,
+		CreatedBy:       operation.CreatedBy,
+		CreatedAt:       operation.CreatedAt,
+	}
+	if operation.Metadata != nil {
+		response.Metadata = json.RawMessage(*operation.Metadata)
+	}
+	if operation.Status == models.OperationStatusFailed && operation.Error != nil {
+		code := string(problem.FailureCode(*operation.Error))
+		response.ErrorCode = &code
+	}
+
+	if c, err := currency.Lookup(operation.Currency); err == nil {
+		response.FormattedAmount = c.Format(operation.Amount)
+		response.FormattedFee = c.Format(operation.Fee)
+	}
+
+	// A transfer is settled as a debit of the source and a credit of the destination
+	if operation.OperationType == models.OperationTypeTransfer && operation.DestinationWalletID != nil {
+		response.DestinationWalletID = operation.DestinationWalletID
+		response.Legs = []models.OperationLeg{
+			{WalletID: operation.WalletID, Direction: models.LegDirectionDebit, Amount: operation.Amount},
+			{WalletID: *operation.DestinationWalletID, Direction: models.LegDirectionCredit, Amount: operation.Amount},
+		}
+	}
+
+	switch operation.OperationType {
+	case models.OperationTypeCapture, models.OperationTypeRelease:
+		response.HoldID = operation.ReferenceOperationID
+	case models.OperationTypeReversal:
+		response.ReversedOperationID = operation.ReferenceOperationID
+	}
+	response.ExpiresAt = operation.ExpiresAt
+
+	return response
+}
+
+// CreateOperation creates an operation and sends it to Kafka. A request with an idempotency
+// key already used on the wallet creates nothing: the original operation ID is returned
+// with replayed set to true, or ErrIdempotencyKeyReused when the requests differ.
+func (s *WalletService) CreateOperation(ctx context.Context, req models.WalletOperationRequest) (operationID string, replayed bool, err error) {
+	var requestHash string
+	if req.IdempotencyKey != "" {
+		requestHash = hashRequest(req)
+		operationID, found, err := s.replayOperation(ctx, req.WalletID, req.IdempotencyKey, requestHash)
+		if err != nil || found {
+			return operationID, found, err
+		}
+	}
+
+	operation, err := s.buildOperation(ctx, req)
 	if err != nil {
-		return "", fmt.Errorf("failed to check wallet existence: %w", err)
+		return "", false, err
+	}
+
+	if req.IdempotencyKey != "" {
+		operation.IdempotencyKey = &req.IdempotencyKey
+		operation.RequestHash = &requestHash
+	}
+
+	// A transfer is keyed by the source wallet: the worker of its partition
+	// settles both legs in one transaction.
+	operationID, err = s.enqueueOperation(ctx, *operation, kafkaMessageFor(*operation))
+	if errors.Is(err, postgresrepo.ErrIdempotencyKeyExists) {
+		// A concurrent request with the same key created the operation first
+		operationID, found, err := s.replayOperation(ctx, req.WalletID, req.IdempotencyKey, requestHash)
+		if err == nil && !found {
+			err = fmt.Errorf("operation with idempotency key %q not found", req.IdempotencyKey)
+		}
+		return operationID, found, err
 	}
-	if !exists {
-		return "", postgresrepo.ErrWalletNotFound
+
+	return operationID, false, err
+}
+
+// buildOperation checks an operation request against the wallet and returns the operation to store
+func (s *WalletService) buildOperation(ctx context.Context, req models.WalletOperationRequest) (*models.WalletOperation, error) {
+	if err := authorizeOperation(ctx, req.OperationType); err != nil {
+		return nil, err
 	}
 
-	// Create operation in PostgreSQL with PENDING status
-	operationID, err := s.postgresRepo.CreateOperation(ctx, req.WalletID, req.OperationType, req.Amount)
+	// Check if wallet exists
+	wallet, err := s.postgresRepo.GetWallet(ctx, req.WalletID)
 	if err != nil {
-		return "", fmt.Errorf("failed to create operation: %w", err)
+		return nil, err
+	}
+	if err := checkActive(wallet); err != nil {
+		return nil, err
 	}
 
-	// Send operation to Kafka for worker processing
-	kafkaMsg := models.KafkaMessage{
-		OperationID:   operationID,
+	// The operation is always denominated in the wallet currency
+	if req.Currency != "" && req.Currency != wallet.Currency {
+		return nil, fmt.Errorf("%w: operation in %s, wallet in %s", ErrCurrencyMismatch, req.Currency, wallet.Currency)
+	}
+
+	operation := models.WalletOperation{
 		WalletID:      req.WalletID,
 		OperationType: req.OperationType,
 		Amount:        req.Amount,
+		Currency:      wallet.Currency,
+		CreatedBy:     auth.CallerID(ctx),
+	}
+	if req.Description != "" {
+		operation.Description = &req.Description
+	}
+	if req.ExternalRef != "" {
+		operation.ExternalRef = &req.ExternalRef
+	}
+	if len(req.Metadata) > 0 {
+		metadata := string(req.Metadata)
+		operation.Metadata = &metadata
+	}
+
+	if req.OperationType == models.OperationTypeTransfer {
+		if err := s.checkDestination(ctx, wallet, req.DestinationWalletID); err != nil {
+			return nil, err
+		}
+		operation.DestinationWalletID = &req.DestinationWalletID
+	}
+
+	if req.ExecuteAt != nil {
+		// A hold expires counting from its creation and captures and releases need
+		// a hold that is active now, so none of them can wait for a later time
+		switch req.OperationType {
+		case models.OperationTypeHold, models.OperationTypeCapture, models.OperationTypeRelease:
+			return nil, ErrNotSchedulable
+		}
+		if !req.ExecuteAt.After(time.Now()) {
+			return nil, ErrInvalidExecuteAt
+		}
+		operation.ExecuteAt = req.ExecuteAt
+	}
+
+	switch req.OperationType {
+	case models.OperationTypeHold:
+		expiresAt := time.Now().Add(s.cfg.Hold.DefaultTTL)
+		if req.ExpiresAt != nil {
+			if !req.ExpiresAt.After(time.Now()) {
+				return nil, ErrInvalidHoldExpiry
+			}
+			expiresAt = *req.ExpiresAt
+		}
+		operation.ExpiresAt = &expiresAt
+
+	case models.OperationTypeCapture, models.OperationTypeRelease:
+		heldAmount, err := s.getHeldAmount(ctx, req.WalletID, req.HoldID)
+		if err != nil {
+			return nil, err
+		}
+
+		// RELEASE without an amount frees everything that is still held
+		if operation.Amount == 0 {
+			operation.Amount = heldAmount
+		}
+		if operation.Amount > heldAmount {
+			return nil, ErrAmountExceedsHold
+		}
+		operation.ReferenceOperationID = &req.HoldID
+	}
+
+	return &operation, nil
+}
+
+// checkDestination checks that the transfer destination exists and holds the same currency
+func (s *WalletService) checkDestination(ctx context.Context, wallet *models.Wallet, destinationWalletID string) error {
+	destination, err := s.postgresRepo.GetWallet(ctx, destinationWalletID)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			return ErrDestinationWalletNotFound
+		}
+		return fmt.Errorf("failed to get destination wallet: %w", err)
+	}
+	if destination.Currency != wallet.Currency {
+		return fmt.Errorf("%w: source wallet in %s, destination wallet in %s", ErrCurrencyMismatch, wallet.Currency, destination.Currency)
+	}
+	if destination.Status != models.WalletStatusActive {
+		return ErrDestinationWalletInactive
+	}
+
+	return nil
+}
+
+// CreateReversal creates a REVERSAL of a processed operation and sends it to Kafka.
+// An amount of 0 reverses everything that has not been reversed yet.
+// The worker validates the reversal again against the original when it is processed.
+func (s *WalletService) CreateReversal(ctx context.Context, walletID, operationID string, req models.ReversalRequest) (string, error) {
+	if err := authorizeOperation(ctx, models.OperationTypeReversal); err != nil {
+		return "", err
 	}
 
+	wallet, err := s.postgresRepo.GetWallet(ctx, walletID)
+	if err != nil {
+		return "", err
+	}
+	if err := checkActive(wallet); err != nil {
+		return "", err
+	}
+
+	original, err := s.postgresRepo.GetOperation(ctx, walletID, operationID)
+	if err != nil {
+		return "", err
+	}
+
+	// A transfer is reversed from its source wallet, whose worker owns the original
+	if !reversibleTypes[original.OperationType] || original.WalletID != walletID {
+		return "", ErrOperationNotReversible
+	}
+	if original.Status != models.OperationStatusProcessed {
+		return "", ErrOperationNotProcessed
+	}
+
+	reversals, err := s.postgresRepo.GetReversals(ctx, operationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get reversals: %w", err)
+	}
+
+	// Reversals that are still pending count against the reversible amount
+	reversible := original.Amount
+	for _, reversal := range reversals {
+		if reversal.Status != models.OperationStatusFailed {
+			reversible -= reversal.Amount
+		}
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = reversible
+	}
+	if amount <= 0 || amount > reversible {
+		return "", ErrAmountExceedsReversible
+	}
+
+	operation := models.WalletOperation{
+		WalletID:             walletID,
+		ReferenceOperationID: &operationID,
+		OperationType:        models.OperationTypeReversal,
+		Amount:               amount,
+		Currency:             original.Currency,
+		CreatedBy:            auth.CallerID(ctx),
+	}
+
+	// Reversing a transfer debits its destination, which the worker has to lock
+	kafkaMsg := models.KafkaMessage{
+		WalletID:             walletID,
+		ReferenceOperationID: operationID,
+		OperationType:        models.OperationTypeReversal,
+		Amount:               amount,
+		Currency:             original.Currency,
+	}
+	if original.DestinationWalletID != nil {
+		kafkaMsg.DestinationWalletID = *original.DestinationWalletID
+	}
+
+	return s.enqueueOperation(ctx, operation, kafkaMsg)
+}
+
+// enqueueOperation stores the operation with PENDING status and sends it to Kafka
+// for worker processing. A scheduled operation is only stored: the scheduler
+// publishes it when it is due.
+func (s *WalletService) enqueueOperation(ctx context.Context, operation models.WalletOperation, kafkaMsg models.KafkaMessage) (string, error) {
+	// Create operation in PostgreSQL with PENDING or SCHEDULED status
+	operationID, err := s.postgresRepo.CreateOperation(ctx, operation)
+	if err != nil {
+		return "", fmt.Errorf("failed to create operation: %w", err)
+	}
+
+	if operation.ExecuteAt != nil {
+		return operationID, nil
+	}
+
+	kafkaMsg.OperationID = operationID
+	if err := s.publishOperation(ctx, kafkaMsg); err != nil {
+		return "", err
+	}
+
+	return operationID, nil
+}
+
+// publishOperation sends a PENDING operation to Kafka for worker processing
+func (s *WalletService) publishOperation(ctx context.Context, kafkaMsg models.KafkaMessage) error {
 	if err := s.kafkaRepo.SendOperation(ctx, kafkaMsg); err != nil {
 		// In case of Kafka error, mark operation as FAILED
-		updateErr := s.postgresRepo.UpdateOperationStatus(ctx, operationID, "FAILED", fmt.Sprintf("Kafka error: %v", err))
+		updateErr := s.postgresRepo.UpdateOperationStatus(ctx, kafkaMsg.OperationID, "FAILED", fmt.Sprintf("Kafka error: %v", err))
 		if updateErr != nil {
 			// Log status update error, but return original Kafka error
 			fmt.Printf("Failed to update operation status after Kafka error: %v\n", updateErr)
 		}
-		return "", fmt.Errorf("failed to send operation to queue: %w", err)
+		return fmt.Errorf("failed to send operation to queue: %w", err)
 	}
 
-	return operationID, nil
+	return nil
+}
+
+// FireDueOperations publishes up to limit scheduled operations whose execution time
+// has come, or whose claim lapsed unpublished, and returns how many were claimed
+func (s *WalletService) FireDueOperations(ctx context.Context, now time.Time, limit int) (int, error) {
+	operations, err := s.postgresRepo.ClaimDueOperations(ctx, now, now.Add(-scheduledClaimLease), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due operations: %w", err)
+	}
+	if len(operations) == 0 {
+		return 0, nil
+	}
+
+	kafkaMsgs := make([]models.KafkaMessage, len(operations))
+	for i, operation := range operations {
+		kafkaMsgs[i] = kafkaMessageFor(operation)
+	}
+
+	// A claimed operation that fails to publish is marked FAILED, the others are still sent
+	errs := s.kafkaRepo.SendOperations(ctx, kafkaMsgs)
+	published := make([]string, 0, len(operations))
+	for i, operation := range operations {
+		if errs == nil || errs[i] == nil {
+			published = append(published, operation.ID)
+			continue
+		}
+		if updateErr := s.postgresRepo.UpdateOperationStatus(ctx, operation.ID, "FAILED", fmt.Sprintf("Kafka error: %v", errs[i])); updateErr != nil {
+			fmt.Printf("Failed to update operation status after Kafka error: %v\n", updateErr)
+		}
+		fmt.Printf("Failed to publish scheduled operation %s: %v\n", operation.ID, errs[i])
+	}
+
+	// Left claimed, the operations are published again after the lease; the worker
+	// skips those it has already processed
+	if err := s.postgresRepo.MarkOperationsPublished(ctx, published); err != nil {
+		fmt.Printf("Failed to mark scheduled operations published: %v\n", err)
+	}
+
+	return len(operations), nil
+}
+
+// CancelOperation cancels an operation that is still scheduled
+func (s *WalletService) CancelOperation(ctx context.Context, walletID, operationID string) (*models.OperationStatusResponse, error) {
+	cancelled, err := s.postgresRepo.CancelScheduledOperation(ctx, walletID, operationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cancelled {
+		operation, err := s.postgresRepo.GetOperation(ctx, walletID, operationID)
+		if err != nil {
+			return nil, err
+		}
+		// A transfer is visible from its destination, but only the source can cancel it
+		if operation.WalletID != walletID {
+			return nil, postgresrepo.ErrOperationNotFound
+		}
+		return nil, ErrOperationNotCancellable
+	}
+
+	return s.GetOperation(ctx, walletID, operationID)
+}
+
+// kafkaMessageFor builds the Kafka message of a stored operation
+func kafkaMessageFor(operation models.WalletOperation) models.KafkaMessage {
+	kafkaMsg := models.KafkaMessage{
+		OperationID:   operation.ID,
+		WalletID:      operation.WalletID,
+		OperationType: operation.OperationType,
+		Amount:        operation.Amount,
+		Currency:      operation.Currency,
+		ExpiresAt:     operation.ExpiresAt,
+	}
+	if operation.DestinationWalletID != nil {
+		kafkaMsg.DestinationWalletID = *operation.DestinationWalletID
+	}
+	if operation.ReferenceOperationID != nil {
+		kafkaMsg.ReferenceOperationID = *operation.ReferenceOperationID
+	}
+	if operation.Description != nil {
+		kafkaMsg.Description = *operation.Description
+	}
+	if operation.ExternalRef != nil {
+		kafkaMsg.ExternalRef = *operation.ExternalRef
+	}
+	if operation.Metadata != nil {
+		kafkaMsg.Metadata = json.RawMessage(*operation.Metadata)
+	}
+
+	return kafkaMsg
+}
+
+// getHeldAmount returns how much of a hold can still be captured or released.
+// A HOLD that the worker has not processed yet is accepted: Kafka ordering
+// guarantees it is settled before the operations that reference it.
+func (s *WalletService) getHeldAmount(ctx context.Context, walletID, holdID string) (int64, error) {
+	operation, err := s.postgresRepo.GetOperation(ctx, walletID, holdID)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrOperationNotFound) {
+			return 0, postgresrepo.ErrHoldNotFound
+		}
+		return 0, fmt.Errorf("failed to get hold operation: %w", err)
+	}
+	if operation.OperationType != models.OperationTypeHold || operation.WalletID != walletID {
+		return 0, postgresrepo.ErrHoldNotFound
+	}
+
+	switch operation.Status {
+	case models.OperationStatusPending:
+		return operation.Amount, nil
+	case models.OperationStatusFailed:
+		return 0, ErrHoldNotActive
+	}
+
+	hold, err := s.postgresRepo.GetHold(ctx, walletID, holdID)
+	if err != nil {
+		return 0, err
+	}
+	if hold.Status != models.HoldStatusActive || !hold.ExpiresAt.After(time.Now()) {
+		return 0, ErrHoldNotActive
+	}
+
+	return hold.RemainingAmount, nil
+}
+
+// balanceResponse builds a balance response with the amounts formatted in major units
+func balanceResponse(wallet models.Wallet) (*models.WalletBalanceResponse, error) {
+	c, err := currency.Lookup(wallet.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("wallet %s: %w: %s", wallet.ID, err, wallet.Currency)
+	}
+
+	available := wallet.Balance - wallet.HeldAmount
+
+	// A wallet whose debt exceeds a lowered limit has no headroom left
+	headroom := available + wallet.CreditLimit
+	if headroom < 0 {
+		headroom = 0
+	}
+
+	return &models.WalletBalanceResponse{
+		WalletID:                  wallet.ID,
+		Balance:                   wallet.Balance,
+		AvailableBalance:          available,
+		CreditLimit:               wallet.CreditLimit,
+		Headroom:                  headroom,
+		Currency:                  c.Code,
+		Status:                    wallet.Status,
+		FormattedBalance:          c.Format(wallet.Balance),
+		FormattedAvailableBalance: c.Format(available),
+		FormattedHeadroom:         c.Format(headroom),
+	}, nil
 }
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"wallet-service/internal/currency"
+	"wallet-service/internal/models"
+	"wallet-service/internal/pagination"
+)
+
+var (
+	ErrExternalRefConflict = errors.New("external reference is used by a wallet in another currency")
+)
+
+// ListWallets returns a page of the wallets matching the filter in creation order.
+// cursor is the nextCursor of the previous page, empty for the first page.
+func (s *WalletService) ListWallets(ctx context.Context, filter models.WalletFilter, cursor string) (*models.WalletListResponse, error) {
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		filter.AfterCreatedAt = &after.CreatedAt
+		filter.AfterID = after.ID
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	// One more wallet than asked tells whether there is a next page
+	filter.Limit = limit + 1
+	wallets, err := s.postgresRepo.ListWallets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.WalletListResponse{Wallets: make([]models.WalletResponse, 0, len(wallets))}
+	if len(wallets) > limit {
+		wallets = wallets[:limit]
+		last := wallets[limit-1]
+		response.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	for _, wallet := range wallets {
+		item := models.WalletResponse{
+			WalletID:    wallet.ID,
+			OwnerID:     wallet.OwnerID,
+			ExternalRef: wallet.ExternalRef,
+			DisplayName: wallet.DisplayName,
+			Labels:      wallet.Labels,
+			Balance:     wallet.Balance,
+			Currency:    wallet.Currency,
+			Status:      wallet.Status,
+			CreatedAt:   wallet.CreatedAt,
+		}
+		if item.Labels == nil {
+			item.Labels = models.Labels{}
+		}
+		if c, err := currency.Lookup(wallet.Currency); err == nil {
+			item.FormattedBalance = c.Format(wallet.Balance)
+		}
+		response.Wallets = append(response.Wallets, item)
+	}
+
+	return response, nil
+}
+
+// walletCreateResponse builds the response of a created or already existing wallet
+func walletCreateResponse(wallet models.Wallet, status, message string) *models.WalletCreateResponse {
+	return &models.WalletCreateResponse{
+		WalletID:    wallet.ID,
+		Balance:     wallet.Balance,
+		Currency:    wallet.Currency,
+		OwnerID:     wallet.OwnerID,
+		ExternalRef: wallet.ExternalRef,
+		DisplayName: wallet.DisplayName,
+		Labels:      wallet.Labels,
+		Status:      status,
+		Message:     message,
+	}
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"wallet-service/internal/auth"
+	"wallet-service/internal/models"
+)
+
+var (
+	ErrWalletNotFrozen = errors.New("wallet is not frozen")
+	ErrWalletHasHolds  = errors.New("wallet has active holds")
+	ErrWalletHasDebt   = errors.New("wallet balance is negative")
+	ErrWalletNotEmpty  = errors.New("wallet balance is not zero")
+	ErrSweepFailed     = errors.New("sweep of the wallet balance failed")
+)
+
+// checkActive rejects new operations of a frozen or closed wallet
+func checkActive(wallet *models.Wallet) error {
+	switch wallet.Status {
+	case models.WalletStatusFrozen:
+		return ErrWalletFrozen
+	case models.WalletStatusClosed:
+		return ErrWalletClosed
+	}
+	return nil
+}
+
+// FreezeWallet blocks new operations of an active wallet. Operations already
+// queued for it are failed by the worker.
+func (s *WalletService) FreezeWallet(ctx context.Context, walletID, reason string) (*models.WalletStatusResponse, error) {
+	return s.changeWalletStatus(ctx, walletID, models.WalletStatusActive, models.WalletStatusFrozen, reason)
+}
+
+// UnfreezeWallet makes a frozen wallet active again
+func (s *WalletService) UnfreezeWallet(ctx context.Context, walletID, reason string) (*models.WalletStatusResponse, error) {
+	return s.changeWalletStatus(ctx, walletID, models.WalletStatusFrozen, models.WalletStatusActive, reason)
+}
+
+func (s *WalletService) changeWalletStatus(ctx context.Context, walletID, from, to, reason string) (*models.WalletStatusResponse, error) {
+	changed, err := s.postgresRepo.UpdateWalletStatus(ctx, walletID, []string{from}, to, reason)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		// Either the wallet does not exist or it is not in the expected status
+		wallet, err := s.postgresRepo.GetWallet(ctx, walletID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkActive(wallet); err != nil {
+			return nil, err
+		}
+		return nil, ErrWalletNotFrozen
+	}
+
+	return s.walletStatus(ctx, walletID)
+}
+
+// CloseWallet closes an active or frozen wallet for good. The wallet must have no
+// active holds and no debt; a positive balance is swept to sweepDestinationID,
+// which must be an active wallet in the same currency. The sweep is a TRANSFER
+// processed by the worker like any other, which closes the wallet once it is
+// posted; until then the wallet is frozen. With a positive wait the call waits
+// for the sweep and returns ErrSweepFailed when the worker fails it.
+func (s *WalletService) CloseWallet(ctx context.Context, walletID string, req models.CloseWalletRequest, wait time.Duration) (*models.WalletStatusResponse, error) {
+	var sweepDestinationID *string
+	if req.SweepDestinationWalletID != "" {
+		sweepDestinationID = &req.SweepDestinationWalletID
+	}
+
+	// Checked under the lock of both wallets, so concurrent batches of the worker
+	// cannot change the balance between the check and the freeze
+	check := func(wallet models.Wallet, destination *models.Wallet) error {
+		if wallet.Status == models.WalletStatusClosed {
+			return ErrWalletClosed
+		}
+		if wallet.HeldAmount > 0 {
+			return ErrWalletHasHolds
+		}
+		if wallet.Balance < 0 {
+			return ErrWalletHasDebt
+		}
+		if sweepDestinationID == nil {
+			if wallet.Balance > 0 {
+				return ErrWalletNotEmpty
+			}
+			return nil
+		}
+		if destination == nil {
+			return ErrDestinationWalletNotFound
+		}
+		if destination.Currency != wallet.Currency {
+			return fmt.Errorf("%w: wallet in %s, sweep destination in %s", ErrCurrencyMismatch, wallet.Currency, destination.Currency)
+		}
+		if destination.Status != models.WalletStatusActive {
+			return ErrDestinationWalletInactive
+		}
+		return nil
+	}
+
+	sweep, err := s.postgresRepo.CloseWallet(ctx, walletID, sweepDestinationID, req.Reason, auth.CallerID(ctx), check)
+	if err != nil {
+		return nil, err
+	}
+
+	if sweep != nil {
+		// A sweep that cannot be queued is failed and the wallet stays frozen,
+		// closing it again creates a new one
+		kafkaMsg := kafkaMessageFor(*sweep)
+		kafkaMsg.CloseWallet = true
+		if err := s.publishOperation(ctx, kafkaMsg); err != nil {
+			return nil, err
+		}
+
+		if wait > 0 {
+			status, done, err := s.WaitForOperation(ctx, walletID, sweep.ID, wait)
+			if err != nil {
+				return nil, err
+			}
+			if done && status.Status != models.OperationStatusProcessed {
+				reason := status.Status
+				if status.Error != nil {
+					reason = *status.Error
+				}
+				return nil, fmt.Errorf("%w: operation %s: %s", ErrSweepFailed, sweep.ID, reason)
+			}
+		}
+	}
+
+	response, err := s.walletStatus(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if sweep != nil {
+		response.SweepOperationID = &sweep.ID
+		response.SweptAmount = sweep.Amount
+	}
+
+	return response, nil
+}
+
+// walletStatus reads the status of a wallet after a change and refreshes its cached status
+func (s *WalletService) walletStatus(ctx context.Context, walletID string) (*models.WalletStatusResponse, error) {
+	wallet, err := s.postgresRepo.GetWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.redisRepo.SetStatus(ctx, walletID, wallet.Status); err != nil {
+		fmt.Printf("Failed to update redis cache for wallet %s: %v\n", walletID, err)
+	}
+
+	return &models.WalletStatusResponse{
+		WalletID:        wallet.ID,
+		Status:          wallet.Status,
+		Reason:          wallet.StatusReason,
+		StatusChangedAt: wallet.StatusChangedAt,
+	}, nil
+}
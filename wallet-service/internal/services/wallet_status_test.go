@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"wallet-service/internal/config"
+	"wallet-service/internal/models"
+)
+
+// lockedWalletRows returns the wallets as locked by CloseWallet
+func lockedWalletRows(wallets ...models.Wallet) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "balance", "held_amount", "credit_limit", "daily_withdraw_limit", "monthly_withdraw_limit", "currency",
+		"status", "status_reason", "status_changed_at", "created_at", "updated_at",
+	})
+	for _, wallet := range wallets {
+		rows.AddRow(
+			wallet.ID, wallet.Balance, wallet.HeldAmount, wallet.CreditLimit, nil, nil, wallet.Currency,
+			wallet.Status, nullable(wallet.StatusReason), nil, time.Now(), time.Now(),
+		)
+	}
+	return rows
+}
+
+// expectClose expects the wallet to be locked with the destination and to get the status
+func expectClose(mock sqlmock.Sqlmock, wallet, destination models.Wallet, status string) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT .* FROM wallets WHERE id IN \(\$1, \$2\) ORDER BY id FOR UPDATE`).
+		WithArgs(wallet.ID, destination.ID).
+		WillReturnRows(lockedWalletRows(wallet, destination))
+	if status == models.WalletStatusFrozen {
+		mock.ExpectExec(`INSERT INTO wallet_operations`).
+			WithArgs(sqlmock.AnyArg(), wallet.ID, destination.ID, nil, models.OperationTypeTransfer, wallet.Balance, wallet.Currency,
+				models.OperationStatusPending, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(`UPDATE wallets SET status = \$1`).
+		WithArgs(status, "customer left", wallet.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE wallet_operations SET status = 'CANCELLED'`).
+		WithArgs(wallet.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE standing_orders SET status = 'COMPLETED'`).
+		WithArgs(wallet.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+}
+
+func TestCloseWalletQueuesTheSweep(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	wallet := activeWallet("w-1", "EUR")
+	destination := activeWallet("w-2", "EUR")
+
+	expectClose(mock, wallet, destination, models.WalletStatusFrozen)
+	frozen := wallet
+	frozen.Status = models.WalletStatusFrozen
+	mock.ExpectQuery(`SELECT .* FROM wallets WHERE id = \$1`).
+		WithArgs("w-1").
+		WillReturnRows(walletRows(frozen))
+
+	response, err := s.CloseWallet(context.Background(), "w-1", models.CloseWalletRequest{
+		Reason:                   "customer left",
+		SweepDestinationWalletID: "w-2",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The wallet stays frozen until the worker processes the sweep and closes it
+	if response.Status != models.WalletStatusFrozen || response.SweepOperationID == nil || response.SweptAmount != 1000 {
+		t.Errorf("response = %+v, want the frozen wallet with its sweep", response)
+	}
+
+	sent := fake.sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	msg := sent[0]
+	if msg.OperationID != *response.SweepOperationID || !msg.CloseWallet || msg.OperationType != models.OperationTypeTransfer ||
+		msg.WalletID != "w-1" || msg.DestinationWalletID != "w-2" || msg.Amount != 1000 {
+		t.Errorf("message = %+v, want the sweep of the whole balance", msg)
+	}
+
+	if status, err := s.redisRepo.GetStatus(context.Background(), "w-1"); err != nil || status != models.WalletStatusFrozen {
+		t.Errorf("cached status = %q, %v, want %s", status, err, models.WalletStatusFrozen)
+	}
+}
+
+func TestCloseWalletWithoutBalance(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	wallet := activeWallet("w-1", "EUR")
+	wallet.Balance = 0
+	destination := activeWallet("w-2", "EUR")
+
+	expectClose(mock, wallet, destination, models.WalletStatusClosed)
+	closed := wallet
+	closed.Status = models.WalletStatusClosed
+	mock.ExpectQuery(`SELECT .* FROM wallets WHERE id = \$1`).
+		WithArgs("w-1").
+		WillReturnRows(walletRows(closed))
+
+	response, err := s.CloseWallet(context.Background(), "w-1", models.CloseWalletRequest{
+		Reason:                   "customer left",
+		SweepDestinationWalletID: "w-2",
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Status != models.WalletStatusClosed || response.SweepOperationID != nil {
+		t.Errorf("response = %+v, want the wallet closed without a sweep", response)
+	}
+	if sent := fake.sent(); len(sent) != 0 {
+		t.Errorf("sent %+v, want nothing", sent)
+	}
+}
+
+func TestCloseWalletSweepNotQueued(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	fake.err = errors.New("broker down")
+	wallet := activeWallet("w-1", "EUR")
+	destination := activeWallet("w-2", "EUR")
+
+	expectClose(mock, wallet, destination, models.WalletStatusFrozen)
+	mock.ExpectExec(`UPDATE wallet_operations SET status = \$1`).
+		WithArgs("FAILED", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err := s.CloseWallet(context.Background(), "w-1", models.CloseWalletRequest{
+		Reason:                   "customer left",
+		SweepDestinationWalletID: "w-2",
+	}, 0)
+	if err == nil {
+		t.Fatal("the close succeeded without queueing the sweep")
+	}
+}
+
+func TestCloseWalletChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		wallet  func(*models.Wallet)
+		dest    func(*models.Wallet)
+		wantErr error
+	}{
+		{"closed wallet", func(w *models.Wallet) { w.Status = models.WalletStatusClosed }, nil, ErrWalletClosed},
+		{"active holds", func(w *models.Wallet) { w.HeldAmount = 100 }, nil, ErrWalletHasHolds},
+		{"debt", func(w *models.Wallet) { w.Balance = -100 }, nil, ErrWalletHasDebt},
+		{"destination in another currency", nil, func(w *models.Wallet) { w.Currency = "USD" }, ErrCurrencyMismatch},
+		{"frozen destination", nil, func(w *models.Wallet) { w.Status = models.WalletStatusFrozen }, ErrDestinationWalletInactive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock, _ := newTestService(t, &config.Config{})
+			wallet := activeWallet("w-1", "EUR")
+			destination := activeWallet("w-2", "EUR")
+			if tt.wallet != nil {
+				tt.wallet(&wallet)
+			}
+			if tt.dest != nil {
+				tt.dest(&destination)
+			}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`SELECT .* FROM wallets WHERE id IN`).
+				WillReturnRows(lockedWalletRows(wallet, destination))
+			mock.ExpectRollback()
+
+			_, err := s.CloseWallet(context.Background(), "w-1", models.CloseWalletRequest{
+				Reason:                   "customer left",
+				SweepDestinationWalletID: "w-2",
+			}, 0)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
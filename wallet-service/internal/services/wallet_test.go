@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+	"github.com/segmentio/kafka-go/protocol/produce"
+
+	"wallet-service/internal/config"
+	"wallet-service/internal/models"
+	"wallet-service/internal/repositories/kafkarepo"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/repositories/redisrepo"
+)
+
+func strptr(s string) *string { return &s }
+
+// fakeKafka is the transport of a Kafka writer that keeps the messages it is sent
+// instead of writing them to a broker
+type fakeKafka struct {
+	mu       sync.Mutex
+	messages []models.KafkaMessage
+	requests int   // produce requests received
+	err      error // fails every produce request when set
+}
+
+func (f *fakeKafka) RoundTrip(ctx context.Context, addr net.Addr, req protocol.Message) (protocol.Message, error) {
+	switch req := req.(type) {
+	case *metadata.Request:
+		topics := make([]metadata.ResponseTopic, len(req.TopicNames))
+		for i, name := range req.TopicNames {
+			topics[i] = metadata.ResponseTopic{Name: name, Partitions: []metadata.ResponsePartition{{}}}
+		}
+		return &metadata.Response{Topics: topics}, nil
+
+	case *produce.Request:
+		if f.err != nil {
+			return nil, f.err
+		}
+
+		response := &produce.Response{}
+		for _, topic := range req.Topics {
+			responseTopic := produce.ResponseTopic{Topic: topic.Topic}
+			for _, partition := range topic.Partitions {
+				if err := f.read(partition.RecordSet.Records); err != nil {
+					return nil, err
+				}
+				responseTopic.Partitions = append(responseTopic.Partitions, produce.ResponsePartition{Partition: partition.Partition})
+			}
+			response.Topics = append(response.Topics, responseTopic)
+		}
+		return response, nil
+	}
+
+	return nil, errors.New("unexpected kafka request")
+}
+
+func (f *fakeKafka) read(records protocol.RecordReader) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests++
+	for {
+		record, err := records.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		value, err := protocol.ReadAll(record.Value)
+		if err != nil {
+			return err
+		}
+		var msg models.KafkaMessage
+		if err := json.Unmarshal(value, &msg); err != nil {
+			return err
+		}
+		f.messages = append(f.messages, msg)
+	}
+}
+
+func (f *fakeKafka) sent() []models.KafkaMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.messages
+}
+
+// newTestService returns a service on a mocked database and an in-memory Redis,
+// whose Kafka messages are kept by the returned fake
+func newTestService(t *testing.T, cfg *config.Config) (*WalletService, sqlmock.Sqlmock, *fakeKafka) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	fake := &fakeKafka{}
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP("kafka:9092"),
+		Topic:        "wallet-operations",
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		BatchTimeout: time.Millisecond,
+		MaxAttempts:  1,
+		Transport:    fake,
+	}
+	t.Cleanup(func() { writer.Close() })
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	s := NewWalletService(
+		cfg,
+		postgresrepo.NewWalletRepository(sqlx.NewDb(db, "postgres")),
+		redisrepo.NewWalletRepository(client),
+		kafkarepo.NewOperationRepository(writer),
+		nil,
+	)
+	return s, mock, fake
+}
+
+// operationRows returns the rows of the operations as read with every column
+func operationRows(operations ...models.WalletOperation) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "wallet_id", "destination_wallet_id", "reference_operation_id", "operation_type", "amount", "reversed_amount",
+		"fee", "currency", "status", "expires_at", "execute_at", "standing_order_id", "description", "external_ref", "metadata",
+		"created_by", "created_at", "processed_at", "error",
+	})
+	for _, op := range operations {
+		rows.AddRow(
+			op.ID, op.WalletID, nullable(op.DestinationWalletID), nullable(op.ReferenceOperationID), op.OperationType, op.Amount, op.ReversedAmount,
+			op.Fee, op.Currency, op.Status, nullable(op.ExpiresAt), nullable(op.ExecuteAt), nullable(op.StandingOrderID), nullable(op.Description), nullable(op.ExternalRef), nullable(op.Metadata),
+			nullable(op.CreatedBy), op.CreatedAt, nullable(op.ProcessedAt), nullable(op.Error),
+		)
+	}
+	return rows
+}
+
+func nullable[T any](v *T) driver.Value {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func TestFireDueOperationsPublishesEveryField(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	staleBefore := now.Add(-scheduledClaimLease)
+
+	operation := models.WalletOperation{
+		ID:                  "op-1",
+		WalletID:            "w-1",
+		DestinationWalletID: strptr("w-2"),
+		OperationType:       models.OperationTypeTransfer,
+		Amount:              500,
+		Currency:            "EUR",
+		Status:              models.OperationStatusScheduled,
+		ExecuteAt:           &now,
+		StandingOrderID:     strptr("order-1"),
+		Description:         strptr("Rent"),
+		ExternalRef:         strptr("invoice-42"),
+		Metadata:            strptr(`{"month":"october"}`),
+		CreatedBy:           strptr("key-1"),
+		CreatedAt:           now.Add(-time.Hour),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT .*standing_order_id, description, external_ref, metadata, created_by.* FROM wallet_operations WHERE \(status = 'SCHEDULED'`).
+		WithArgs(now, staleBefore, 10).
+		WillReturnRows(operationRows(operation))
+	mock.ExpectExec(`UPDATE wallet_operations SET status = 'PENDING', claimed_at = \$1`).
+		WithArgs(now, "op-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`UPDATE wallet_operations SET claimed_at = NULL`).
+		WithArgs("op-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	fired, err := s.FireDueOperations(context.Background(), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired != 1 {
+		t.Errorf("fired %d operations, want 1", fired)
+	}
+
+	sent := fake.sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	msg := sent[0]
+	if msg.OperationID != "op-1" || msg.DestinationWalletID != "w-2" || msg.Currency != "EUR" {
+		t.Errorf("message = %+v", msg)
+	}
+	if msg.Description != "Rent" || msg.ExternalRef != "invoice-42" || string(msg.Metadata) != `{"month":"october"}` {
+		t.Errorf("message lacks the reconciliation fields: %+v", msg)
+	}
+}
+
+// walletRows returns the row of a wallet as read by GetWallet
+func walletRows(wallet models.Wallet) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{
+		"id", "balance", "held_amount", "credit_limit", "daily_withdraw_limit", "monthly_withdraw_limit", "currency",
+		"status", "status_reason", "status_changed_at", "owner_id", "external_ref", "display_name", "labels", "created_at", "updated_at",
+	}).AddRow(
+		wallet.ID, wallet.Balance, wallet.HeldAmount, wallet.CreditLimit, nil, nil, wallet.Currency,
+		wallet.Status, nullable(wallet.StatusReason), nil, nil, nil, nil, []byte(`{}`), time.Now(), time.Now(),
+	)
+}
+
+// activeWallet returns an active wallet with a balance of 1000
+func activeWallet(walletID, currency string) models.Wallet {
+	return models.Wallet{ID: walletID, Balance: 1000, Currency: currency, Status: models.WalletStatusActive}
+}
+
+func TestBuildOperationSchedule(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		operationType string
+		executeAt     *time.Time
+		wantErr       error
+	}{
+		{"scheduled withdraw", models.OperationTypeWithdraw, &future, nil},
+		{"withdraw in the past", models.OperationTypeWithdraw, &past, ErrInvalidExecuteAt},
+		{"scheduled hold", models.OperationTypeHold, &future, ErrNotSchedulable},
+		{"scheduled capture", models.OperationTypeCapture, &future, ErrNotSchedulable},
+		{"scheduled release", models.OperationTypeRelease, &future, ErrNotSchedulable},
+		{"hold now", models.OperationTypeHold, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock, _ := newTestService(t, &config.Config{Hold: config.HoldConfig{DefaultTTL: time.Hour}})
+			mock.ExpectQuery(`SELECT .* FROM wallets WHERE id = \$1`).
+				WithArgs("w-1").
+				WillReturnRows(walletRows(activeWallet("w-1", "EUR")))
+
+			operation, err := s.buildOperation(context.Background(), models.WalletOperationRequest{
+				WalletID:      "w-1",
+				OperationType: tt.operationType,
+				Amount:        100,
+				ExecuteAt:     tt.executeAt,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if operation.ExecuteAt != tt.executeAt {
+				t.Errorf("ExecuteAt = %v, want %v", operation.ExecuteAt, tt.executeAt)
+			}
+			if tt.operationType == models.OperationTypeHold {
+				if operation.ExpiresAt == nil || operation.ExpiresAt.Before(time.Now().Add(59*time.Minute)) {
+					t.Errorf("ExpiresAt = %v, want the default TTL from now", operation.ExpiresAt)
+				}
+			}
+		})
+	}
+}
+
+// expectClaim expects a claim of due operations returning the operations. Rows locked
+// by another replica are skipped, so each operation is claimed by one replica only.
+func expectClaim(mock sqlmock.Sqlmock, now time.Time, operations ...models.WalletOperation) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM wallet_operations WHERE \(status = 'SCHEDULED' AND execute_at <= \$1\) OR \(status = 'PENDING' AND claimed_at <= \$2\) .* FOR UPDATE SKIP LOCKED`).
+		WithArgs(now, now.Add(-scheduledClaimLease), sqlmock.AnyArg()).
+		WillReturnRows(operationRows(operations...))
+	mock.ExpectExec(`UPDATE wallet_operations SET status = 'PENDING', claimed_at = \$1`).
+		WillReturnResult(sqlmock.NewResult(0, int64(len(operations))))
+	mock.ExpectCommit()
+}
+
+func scheduledOperation(id, walletID string, executeAt time.Time) models.WalletOperation {
+	return models.WalletOperation{
+		ID:            id,
+		WalletID:      walletID,
+		OperationType: models.OperationTypeDeposit,
+		Amount:        100,
+		Currency:      "EUR",
+		Status:        models.OperationStatusScheduled,
+		ExecuteAt:     &executeAt,
+		CreatedAt:     executeAt.Add(-time.Hour),
+	}
+}
+
+func TestFireDueOperationsPublishesInOneWrite(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	expectClaim(mock, now, scheduledOperation("op-1", "w-1", now), scheduledOperation("op-2", "w-2", now))
+	mock.ExpectExec(`UPDATE wallet_operations SET claimed_at = NULL WHERE id IN \(\$1, \$2\)`).
+		WithArgs("op-1", "op-2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	fired, err := s.FireDueOperations(context.Background(), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired != 2 || len(fake.sent()) != 2 {
+		t.Errorf("fired %d operations and sent %d messages, want 2", fired, len(fake.sent()))
+	}
+	if fake.requests != 1 {
+		t.Errorf("sent the messages in %d requests, want 1", fake.requests)
+	}
+}
+
+func TestFireDueOperationsKafkaFailure(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	fake.err = errors.New("broker down")
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	// An operation that could not be published is FAILED, not left claimed
+	expectClaim(mock, now, scheduledOperation("op-1", "w-1", now))
+	mock.ExpectExec(`UPDATE wallet_operations SET status = \$1, processed_at = NOW\(\), error = \$2`).
+		WithArgs("FAILED", sqlmock.AnyArg(), "op-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	fired, err := s.FireDueOperations(context.Background(), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fired != 1 || len(fake.sent()) != 0 {
+		t.Errorf("fired %d operations and sent %d messages, want 1 and 0", fired, len(fake.sent()))
+	}
+}
+
+func TestFireDueOperationsNothingDue(t *testing.T) {
+	s, mock, fake := newTestService(t, &config.Config{})
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM wallet_operations`).WillReturnRows(operationRows())
+	mock.ExpectCommit()
+
+	fired, err := s.FireDueOperations(context.Background(), now, 10)
+	if err != nil || fired != 0 || fake.requests != 0 {
+		t.Errorf("fired %d operations in %d requests, err %v", fired, fake.requests, err)
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"wallet-service/internal/models"
+	"wallet-service/internal/pagination"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/webhook"
+)
+
+const (
+	// webhookConcurrency is the number of deliveries sent at the same time by a replica
+	webhookConcurrency = 10
+	// webhookLeaseSlack is added to the attempt timeout to get the lease of a claimed delivery
+	webhookLeaseSlack = 30 * time.Second
+	// maxWebhookError is the length of the error recorded for a failed attempt
+	maxWebhookError = 1000
+)
+
+var (
+	ErrDeliveryNotDead = errors.New("webhook delivery is not dead")
+)
+
+// CreateWebhook subscribes a URL to the events of a wallet, or of all wallets when the
+// request has no walletId. A secret is generated when the request has none; it is only
+// returned here.
+func (s *WalletService) CreateWebhook(ctx context.Context, req models.WebhookRequest) (*models.WebhookResponse, error) {
+	subscription := models.WebhookSubscription{
+		URL:    req.URL,
+		Secret: req.Secret,
+	}
+
+	if req.WalletID != "" {
+		walletExists, err := s.postgresRepo.WalletExists(ctx, req.WalletID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check wallet existence: %w", err)
+		}
+		if !walletExists {
+			return nil, postgresrepo.ErrWalletNotFound
+		}
+		subscription.WalletID = &req.WalletID
+	}
+
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(subscription.EventTypes, eventType) {
+			subscription.EventTypes = append(subscription.EventTypes, eventType)
+		}
+	}
+
+	if subscription.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+
+	created, err := s.postgresRepo.CreateWebhook(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	response := webhookResponse(*created)
+	response.Secret = created.Secret
+
+	return response, nil
+}
+
+// GetWebhook returns a webhook subscription without its secret
+func (s *WalletService) GetWebhook(ctx context.Context, webhookID string) (*models.WebhookResponse, error) {
+	subscription, err := s.postgresRepo.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookResponse(*subscription), nil
+}
+
+// ListWebhooks returns all webhook subscriptions without their secrets
+func (s *WalletService) ListWebhooks(ctx context.Context) (*models.WebhookListResponse, error) {
+	subscriptions, err := s.postgresRepo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.WebhookListResponse{Webhooks: make([]models.WebhookResponse, 0, len(subscriptions))}
+	for _, subscription := range subscriptions {
+		response.Webhooks = append(response.Webhooks, *webhookResponse(subscription))
+	}
+
+	return response, nil
+}
+
+// DeleteWebhook removes a webhook subscription; its pending and dead deliveries are dropped
+func (s *WalletService) DeleteWebhook(ctx context.Context, webhookID string) error {
+	return s.postgresRepo.DeleteWebhook(ctx, webhookID)
+}
+
+// DeliverWebhooks sends up to limit webhook deliveries that are due and returns how many
+// were attempted. A failed delivery is retried with exponential backoff until it has
+// used all its attempts, then it is dead-lettered. Delivery is at least once: receivers
+// deduplicate by Webhook-Id.
+func (s *WalletService) DeliverWebhooks(ctx context.Context, now time.Time, limit int) (int, error) {
+	if s.cfg.Webhook.MaxAttempts <= 0 {
+		return 0, nil
+	}
+
+	deliveries, err := s.postgresRepo.ClaimWebhookDeliveries(ctx, now, now.Add(s.cfg.Webhook.Timeout+webhookLeaseSlack), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, webhookConcurrency)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(delivery models.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := s.deliverWebhook(ctx, delivery); err != nil {
+				fmt.Printf("Failed to record webhook delivery %d: %v\n", delivery.ID, err)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// deliverWebhook makes one attempt of a delivery and records its result
+func (s *WalletService) deliverWebhook(ctx context.Context, delivery models.WebhookDelivery) error {
+	deliveryID := strconv.FormatInt(delivery.ID, 10)
+	body, err := json.Marshal(models.WebhookPayload{
+		ID:        deliveryID,
+		Type:      delivery.EventType,
+		WalletID:  delivery.WalletID,
+		CreatedAt: delivery.CreatedAt,
+		Data:      json.RawMessage(delivery.Data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	statusCode, sendErr := s.webhooks.Send(ctx, delivery.URL, delivery.Secret, deliveryID, body)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastStatusCode = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = models.WebhookDeliveryStatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+	case delivery.Attempts >= s.cfg.Webhook.MaxAttempts:
+		delivery.Status = models.WebhookDeliveryStatusDead
+	default:
+		delivery.NextAttemptAt = now.Add(webhook.Backoff(delivery.Attempts, s.cfg.Webhook.Backoff, s.cfg.Webhook.MaxBackoff))
+	}
+	if sendErr != nil {
+		message := sendErr.Error()
+		if len(message) > maxWebhookError {
+			message = message[:maxWebhookError]
+		}
+		delivery.LastError = &message
+	}
+
+	return s.postgresRepo.UpdateWebhookDelivery(ctx, delivery)
+}
+
+// ListDeadLetters returns a page of the dead webhook deliveries, newest first.
+// cursor is the nextCursor of the previous page, empty for the first page.
+func (s *WalletService) ListDeadLetters(ctx context.Context, filter models.WebhookDeliveryFilter, cursor string) (*models.WebhookDeliveryListResponse, error) {
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		afterID, err := strconv.ParseInt(after.ID, 10, 64)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		filter.AfterCreatedAt = &after.CreatedAt
+		filter.AfterID = afterID
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	// One more delivery than asked tells whether there is a next page
+	filter.Limit = limit + 1
+	deliveries, err := s.postgresRepo.ListDeadWebhookDeliveries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.WebhookDeliveryListResponse{Deliveries: make([]models.WebhookDeliveryResponse, 0, len(deliveries))}
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+		last := deliveries[limit-1]
+		response.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: strconv.FormatInt(last.ID, 10)}.Encode()
+	}
+
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, models.WebhookDeliveryResponse{
+			DeliveryID:     delivery.ID,
+			WebhookID:      delivery.SubscriptionID,
+			EventType:      delivery.EventType,
+			WalletID:       delivery.WalletID,
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			LastStatusCode: delivery.LastStatusCode,
+			LastError:      delivery.LastError,
+			CreatedAt:      delivery.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+// ReplayDeadLetter queues a dead delivery again with a fresh set of attempts
+func (s *WalletService) ReplayDeadLetter(ctx context.Context, deliveryID int64) (*models.WebhookReplayResponse, error) {
+	replayed, err := s.postgresRepo.ReplayWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if !replayed {
+		return nil, ErrDeliveryNotDead
+	}
+
+	return &models.WebhookReplayResponse{Replayed: 1}, nil
+}
+
+// ReplayDeadLetters queues all dead deliveries of a webhook again
+func (s *WalletService) ReplayDeadLetters(ctx context.Context, webhookID string) (*models.WebhookReplayResponse, error) {
+	if _, err := s.postgresRepo.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	replayed, err := s.postgresRepo.ReplayDeadWebhookDeliveries(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookReplayResponse{Replayed: replayed}, nil
+}
+
+// webhookResponse builds the response of a webhook subscription without its secret
+func webhookResponse(subscription models.WebhookSubscription) *models.WebhookResponse {
+	return &models.WebhookResponse{
+		WebhookID:  subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: subscription.EventTypes,
+		WalletID:   subscription.WalletID,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
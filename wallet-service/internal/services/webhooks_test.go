@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"wallet-service/internal/config"
+	"wallet-service/internal/models"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/webhook"
+)
+
+// timeWithin matches a time between from and to
+type timeWithin struct {
+	from, to time.Time
+}
+
+func (w timeWithin) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	return ok && !t.Before(w.from) && !t.After(w.to)
+}
+
+func webhookConfig() *config.Config {
+	return &config.Config{Webhook: config.WebhookConfig{
+		Timeout:     time.Second,
+		MaxAttempts: 5,
+		Backoff:     time.Second,
+		MaxBackoff:  time.Minute,
+	}}
+}
+
+// expectDeliveryClaim expects a pending delivery to the URL to be claimed, with
+// the attempts it has already used
+func expectDeliveryClaim(mock sqlmock.Sqlmock, cfg *config.Config, now time.Time, url string, attempts int) {
+	mock.ExpectQuery(`UPDATE webhook_deliveries d SET next_attempt_at = \$2 .* FOR UPDATE SKIP LOCKED`).
+		WithArgs(now, now.Add(cfg.Webhook.Timeout+webhookLeaseSlack), 10).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "subscription_id", "event_type", "wallet_id", "data", "status", "attempts", "next_attempt_at",
+			"last_status_code", "last_error", "created_at", "delivered_at", "url", "secret",
+		}).AddRow(
+			7, "hook-1", models.WebhookEventOperationProcessed, "w-1", `{"operationId":"op-1"}`, models.WebhookDeliveryStatusPending, attempts, now,
+			nil, nil, now, nil, url, "whsec_test",
+		))
+}
+
+func TestDeliverWebhooksSignsTheDelivery(t *testing.T) {
+	cfg := webhookConfig()
+	s, mock, _ := newTestService(t, cfg)
+
+	var signatureErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seconds, _ := strconv.ParseInt(r.Header.Get("Webhook-Timestamp"), 10, 64)
+
+		var payload models.WebhookPayload
+		json.Unmarshal(body, &payload)
+		switch {
+		case r.Header.Get("Webhook-Signature") != webhook.Sign("whsec_test", time.Unix(seconds, 0), body):
+			signatureErr = errors.New("signature does not match the body")
+		case r.Header.Get("Webhook-Id") != "7" || payload.ID != "7" || string(payload.Data) != `{"operationId":"op-1"}`:
+			signatureErr = errors.New("unexpected delivery " + string(body))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	expectDeliveryClaim(mock, cfg, now, server.URL, 0)
+	mock.ExpectExec(`UPDATE webhook_deliveries SET status = \$1`).
+		WithArgs(models.WebhookDeliveryStatusDelivered, 1, sqlmock.AnyArg(), int64(http.StatusNoContent), nil, sqlmock.AnyArg(), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	attempted, err := s.DeliverWebhooks(context.Background(), now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempted != 1 {
+		t.Errorf("attempted %d deliveries, want 1", attempted)
+	}
+	if signatureErr != nil {
+		t.Error(signatureErr)
+	}
+}
+
+func TestDeliverWebhooksFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempts   int // used before this one
+		wantStatus string
+		wantDelay  time.Duration // until the next attempt
+	}{
+		{"retried with backoff", 2, models.WebhookDeliveryStatusPending, 4 * time.Second},
+		{"dead after the last attempt", 4, models.WebhookDeliveryStatusDead, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := webhookConfig()
+			s, mock, _ := newTestService(t, cfg)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+
+			now := time.Now()
+			var nextAttemptAt interface{} = now
+			if tt.wantDelay > 0 {
+				// The delay counts from the end of the attempt
+				nextAttemptAt = timeWithin{now.Add(tt.wantDelay), now.Add(tt.wantDelay + cfg.Webhook.Timeout + time.Second)}
+			}
+
+			expectDeliveryClaim(mock, cfg, now, server.URL, tt.attempts)
+			mock.ExpectExec(`UPDATE webhook_deliveries SET status = \$1`).
+				WithArgs(tt.wantStatus, tt.attempts+1, nextAttemptAt, int64(http.StatusInternalServerError), sqlmock.AnyArg(), nil, int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			if _, err := s.DeliverWebhooks(context.Background(), now, 10); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDeliverWebhooksDisabled(t *testing.T) {
+	// Without attempts nothing is claimed
+	s, _, _ := newTestService(t, &config.Config{})
+
+	attempted, err := s.DeliverWebhooks(context.Background(), time.Now(), 10)
+	if err != nil || attempted != 0 {
+		t.Errorf("got %d, %v, want nothing attempted", attempted, err)
+	}
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	tests := []struct {
+		name     string
+		replayed int64
+		exists   bool
+		wantErr  error
+	}{
+		{"dead delivery", 1, true, nil},
+		{"delivery not dead", 0, true, ErrDeliveryNotDead},
+		{"unknown delivery", 0, false, postgresrepo.ErrDeliveryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mock, _ := newTestService(t, &config.Config{})
+
+			// Only a dead delivery is queued again, with a fresh set of attempts
+			mock.ExpectExec(`UPDATE webhook_deliveries SET status = 'PENDING', attempts = 0, next_attempt_at = NOW\(\) WHERE id = \$1 AND status = 'DEAD'`).
+				WithArgs(int64(7)).
+				WillReturnResult(sqlmock.NewResult(0, tt.replayed))
+			if tt.replayed == 0 {
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs(int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.exists))
+			}
+
+			response, err := s.ReplayDeadLetter(context.Background(), 7)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && response.Replayed != 1 {
+				t.Errorf("replayed %d, want 1", response.Replayed)
+			}
+		})
+	}
+}
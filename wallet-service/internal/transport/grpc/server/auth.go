@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"wallet-service/internal/auth"
+	"wallet-service/internal/ratelimit"
+	"wallet-service/internal/services"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// metadataKey carries the API key of a call, like the X-API-Key header of the REST API
+	metadataKey = "x-api-key"
+	// authorizationKey carries a bearer token, like the Authorization header
+	authorizationKey = "authorization"
+)
+
+// UnaryAuthenticator authenticates every unary call with its API key or bearer token and
+// passes its principal on in the context. What the caller may do is checked by each method.
+func UnaryAuthenticator(walletService *services.WalletService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, walletService)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthenticator authenticates every streaming call like UnaryAuthenticator
+func StreamAuthenticator(walletService *services.WalletService) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), walletService)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticatedStream is a server stream whose context carries the principal
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticate(ctx context.Context, walletService *services.WalletService) (context.Context, error) {
+	var key, authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(metadataKey); len(values) > 0 {
+			key = values[0]
+		}
+		if values := md.Get(authorizationKey); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+
+	var (
+		principal *auth.Principal
+		err       error
+	)
+	if token, ok := auth.BearerToken(authorization); ok {
+		principal, err = walletService.AuthenticateToken(ctx, token)
+	} else {
+		principal, err = walletService.Authenticate(ctx, key)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) {
+			return nil, status.Error(codes.Unauthenticated, "Invalid bearer token")
+		}
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			return nil, status.Error(codes.Unauthenticated, "Missing or invalid API key")
+		}
+		return nil, internalError("Failed to authenticate", err)
+	}
+
+	result, err := walletService.LimitClient(ctx, principal)
+	if err != nil {
+		return nil, rateLimitError(result, err)
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// rateLimitError maps the errors of a rate limit check to statuses
+func rateLimitError(result *ratelimit.Result, err error) error {
+	if errors.Is(err, services.ErrRateLimited) {
+		return status.Errorf(codes.ResourceExhausted, "Rate limit exceeded, retry after %d seconds", int(result.RetryAfter.Seconds()))
+	}
+	if errors.Is(err, services.ErrRateLimiterUnavailable) {
+		return status.Error(codes.Unavailable, "Rate limiter unavailable")
+	}
+	return internalError("Failed to check rate limit", err)
+}
+
+// authorize checks that the caller has scope and may access the wallet, if one is given,
+// and takes a token from the bucket of the wallet
+func (s *Wallet) authorize(ctx context.Context, scope, walletID string) error {
+	principal := auth.FromContext(ctx)
+	if principal == nil {
+		return status.Error(codes.Unauthenticated, "Missing or invalid API key")
+	}
+	if !principal.HasScope(scope) {
+		return status.Errorf(codes.PermissionDenied, "Caller lacks the %s scope", scope)
+	}
+
+	if walletID == "" {
+		return nil
+	}
+	if err := s.walletService.AuthorizeWallet(ctx, principal, walletID); err != nil {
+		if errors.Is(err, services.ErrWalletNotAllowed) {
+			return status.Error(codes.PermissionDenied, "Caller is not allowed to access this wallet")
+		}
+		return internalError("Failed to authorize wallet", err)
+	}
+	if result, err := s.walletService.LimitWallet(ctx, walletID); err != nil {
+		return rateLimitError(result, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"time"
+	"wallet-service/internal/models"
+	"wallet-service/internal/transport/grpc/walletpb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// operationMessage converts an operation status to its protobuf message.
+// The hold and the reversals of an operation are only served by the REST API.
+func operationMessage(operation *models.OperationStatusResponse) *walletpb.Operation {
+	message := &walletpb.Operation{
+		OperationId:         operation.OperationID,
+		WalletId:            operation.WalletID,
+		DestinationWalletId: operation.DestinationWalletID,
+		OperationType:       operation.OperationType,
+		Amount:              operation.Amount,
+		Currency:            operation.Currency,
+		FormattedAmount:     operation.FormattedAmount,
+		Fee:                 operation.Fee,
+		FormattedFee:        operation.FormattedFee,
+		Status:              operation.Status,
+		ProcessedAt:         timestamp(operation.ProcessedAt),
+		ExecuteAt:           timestamp(operation.ExecuteAt),
+		Error:               operation.Error,
+		HoldId:              operation.HoldID,
+		ExpiresAt:           timestamp(operation.ExpiresAt),
+		ReversedOperationId: operation.ReversedOperationID,
+		ReversedAmount:      operation.ReversedAmount,
+		StandingOrderId:     operation.StandingOrderID,
+		Description:         operation.Description,
+		ExternalRef:         operation.ExternalRef,
+		CreatedAt:           timestamppb.New(operation.CreatedAt),
+		CreatedBy:           operation.CreatedBy,
+	}
+
+	if len(operation.Metadata) > 0 {
+		metadata := string(operation.Metadata)
+		message.Metadata = &metadata
+	}
+
+	for _, leg := range operation.Legs {
+		message.Legs = append(message.Legs, &walletpb.OperationLeg{
+			WalletId:  leg.WalletID,
+			Direction: leg.Direction,
+			Amount:    leg.Amount,
+		})
+	}
+
+	return message
+}
+
+// timestamp converts an optional time, nil stays unset
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// optionalTime converts an optional timestamp of a request
+func optionalTime(ts *timestamppb.Timestamp) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return nil, err
+	}
+
+	t := ts.AsTime()
+	return &t, nil
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"wallet-service/internal/auth"
+	"wallet-service/internal/events"
+	"wallet-service/internal/models"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/transport/grpc/walletpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WatchOperations streams the operation events of a wallet. It follows the SSE stream
+// of the REST API, minus the balance events: missed events are sent first when the
+// client resumes with last_event_id, then the live ones.
+func (s *Wallet) WatchOperations(req *walletpb.WatchOperationsRequest, stream grpc.ServerStreamingServer[walletpb.OperationEvent]) error {
+	walletID := req.GetWalletId()
+	if err := s.validate.Var(walletID, "required,uuid4"); err != nil {
+		return status.Error(codes.InvalidArgument, "Invalid wallet ID format")
+	}
+
+	lastEventID := req.GetLastEventId()
+	if lastEventID != "" && !events.ValidID(lastEventID) {
+		return status.Error(codes.InvalidArgument, "Invalid last_event_id")
+	}
+
+	ctx := stream.Context()
+	if err := s.authorize(ctx, auth.ScopeOperationsRead, walletID); err != nil {
+		return err
+	}
+
+	missed, live, cancel, err := s.walletService.SubscribeWalletEvents(ctx, walletID, lastEventID)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			return status.Error(codes.NotFound, "Wallet not found")
+		}
+		return internalError("Failed to subscribe to wallet events", err)
+	}
+	defer cancel()
+
+	for _, event := range missed {
+		if err := sendOperationEvent(stream, event); err != nil {
+			return err
+		}
+		lastEventID = event.ID
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-live:
+			if !ok {
+				return status.Error(codes.Unavailable, "Stream fell behind, resume with last_event_id")
+			}
+			// Skip events already sent from the stream
+			if lastEventID != "" && !events.After(event.ID, lastEventID) {
+				continue
+			}
+			if err := sendOperationEvent(stream, event); err != nil {
+				return err
+			}
+			lastEventID = event.ID
+		}
+	}
+}
+
+// sendOperationEvent sends a wallet event to the stream unless it is a balance event
+func sendOperationEvent(stream grpc.ServerStreamingServer[walletpb.OperationEvent], event models.WalletEvent) error {
+	if event.Type != models.EventTypeOperation {
+		return nil
+	}
+
+	var data models.OperationEvent
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		fmt.Printf("Failed to decode operation event %s: %v\n", event.ID, err)
+		return nil
+	}
+
+	return stream.Send(&walletpb.OperationEvent{
+		EventId:       event.ID,
+		WalletId:      event.WalletID,
+		OperationId:   data.OperationID,
+		OperationType: data.OperationType,
+		Status:        data.Status,
+		Amount:        data.Amount,
+		Fee:           data.Fee,
+		Currency:      data.Currency,
+		Error:         data.Error,
+		ProcessedAt:   timestamp(data.ProcessedAt),
+	})
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"wallet-service/internal/auth"
+	"wallet-service/internal/models"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/services"
+	"wallet-service/internal/transport/grpc/walletpb"
+	"wallet-service/internal/validation"
+
+	"github.com/go-playground/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Wallet serves the gRPC API with the same service and validation as the REST handlers
+type Wallet struct {
+	walletpb.UnimplementedWalletServiceServer
+
+	walletService *services.WalletService
+	validate      *validator.Validate
+}
+
+func NewWallet(server *grpc.Server, walletService *services.WalletService) *Wallet {
+	s := &Wallet{
+		walletService: walletService,
+		validate:      validation.New(),
+	}
+
+	walletpb.RegisterWalletServiceServer(server, s)
+
+	return s
+}
+
+func (s *Wallet) CreateWallet(ctx context.Context, req *walletpb.CreateWalletRequest) (*walletpb.CreateWalletResponse, error) {
+	walletReq := models.WalletCreateRequest{
+		Currency:    req.GetCurrency(),
+		OwnerID:     req.GetOwnerId(),
+		ExternalRef: req.GetExternalRef(),
+		DisplayName: req.GetDisplayName(),
+		Labels:      req.GetLabels(),
+	}
+
+	if violations := validation.Wallet(s.validate, walletReq); violations != nil {
+		return nil, status.Error(codes.InvalidArgument, violations.Error())
+	}
+
+	if err := s.authorize(ctx, auth.ScopeWalletsWrite, ""); err != nil {
+		return nil, err
+	}
+	if !auth.FromContext(ctx).AllowsOwner(walletReq.OwnerID) {
+		return nil, status.Error(codes.PermissionDenied, "Caller is not allowed to create wallets of this owner")
+	}
+
+	response, err := s.walletService.CreateWallet(ctx, walletReq)
+	if err != nil {
+		if errors.Is(err, services.ErrExternalRefConflict) {
+			return nil, status.Error(codes.AlreadyExists, "ExternalRef is already used by a wallet in another currency")
+		}
+		return nil, internalError("Failed to create wallet", err)
+	}
+
+	return &walletpb.CreateWalletResponse{
+		WalletId:    response.WalletID,
+		Balance:     response.Balance,
+		Currency:    response.Currency,
+		OwnerId:     response.OwnerID,
+		ExternalRef: response.ExternalRef,
+		DisplayName: response.DisplayName,
+		Labels:      response.Labels,
+		Status:      response.Status,
+		Message:     response.Message,
+	}, nil
+}
+
+func (s *Wallet) GetWalletBalance(ctx context.Context, req *walletpb.GetWalletBalanceRequest) (*walletpb.GetWalletBalanceResponse, error) {
+	if err := s.validate.Var(req.GetWalletId(), "required,uuid4"); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid wallet ID format")
+	}
+
+	if err := s.authorize(ctx, auth.ScopeWalletsRead, req.GetWalletId()); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.walletService.GetWalletBalance(ctx, req.GetWalletId())
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			return nil, status.Error(codes.NotFound, "Wallet not found")
+		}
+		return nil, internalError("Failed to get wallet balance", err)
+	}
+
+	return &walletpb.GetWalletBalanceResponse{
+		WalletId:                  balance.WalletID,
+		Balance:                   balance.Balance,
+		AvailableBalance:          balance.AvailableBalance,
+		CreditLimit:               balance.CreditLimit,
+		Headroom:                  balance.Headroom,
+		Currency:                  balance.Currency,
+		Status:                    balance.Status,
+		FormattedBalance:          balance.FormattedBalance,
+		FormattedAvailableBalance: balance.FormattedAvailableBalance,
+		FormattedHeadroom:         balance.FormattedHeadroom,
+	}, nil
+}
+
+func (s *Wallet) CreateOperation(ctx context.Context, req *walletpb.CreateOperationRequest) (*walletpb.CreateOperationResponse, error) {
+	operationReq := models.WalletOperationRequest{
+		WalletID:            req.GetWalletId(),
+		OperationType:       req.GetOperationType(),
+		Amount:              req.GetAmount(),
+		DestinationWalletID: req.GetDestinationWalletId(),
+		HoldID:              req.GetHoldId(),
+		Currency:            req.GetCurrency(),
+		IdempotencyKey:      req.GetIdempotencyKey(),
+		Description:         req.GetDescription(),
+		ExternalRef:         req.GetExternalRef(),
+	}
+	if req.GetMetadata() != "" {
+		operationReq.Metadata = json.RawMessage(req.GetMetadata())
+	}
+
+	var err error
+	if operationReq.ExpiresAt, err = optionalTime(req.GetExpiresAt()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid expires_at")
+	}
+	if operationReq.ExecuteAt, err = optionalTime(req.GetExecuteAt()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid execute_at")
+	}
+
+	if violations := validation.Operation(s.validate, operationReq); violations != nil {
+		return nil, status.Error(codes.InvalidArgument, violations.Error())
+	}
+
+	if err := s.authorize(ctx, auth.ScopeOperationsWrite, operationReq.WalletID); err != nil {
+		return nil, err
+	}
+
+	operationID, replayed, err := s.walletService.CreateOperation(ctx, operationReq)
+	if err != nil {
+		return nil, operationError(err)
+	}
+
+	response := &walletpb.CreateOperationResponse{
+		OperationId: operationID,
+		Status:      models.OperationStatusAccepted,
+		Message:     models.MessageOperationQueued,
+		Replayed:    replayed,
+	}
+	if operationReq.ExecuteAt != nil {
+		response.Message = models.MessageOperationScheduled
+	}
+
+	return response, nil
+}
+
+// operationError maps an error of operation creation to a gRPC status
+func operationError(err error) error {
+	if errors.Is(err, services.ErrOperationNotAllowed) {
+		return status.Error(codes.PermissionDenied, "Caller is not allowed to create operations of this type")
+	}
+	if errors.Is(err, services.ErrIdempotencyKeyReused) {
+		return status.Error(codes.FailedPrecondition, "Idempotency key was already used for a different request")
+	}
+	if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+		return status.Error(codes.NotFound, "Wallet not found")
+	}
+	if errors.Is(err, services.ErrDestinationWalletNotFound) {
+		return status.Error(codes.NotFound, "Destination wallet not found")
+	}
+	if errors.Is(err, services.ErrWalletFrozen) {
+		return status.Error(codes.FailedPrecondition, "Wallet is frozen")
+	}
+	if errors.Is(err, services.ErrWalletClosed) {
+		return status.Error(codes.FailedPrecondition, "Wallet is closed")
+	}
+	if errors.Is(err, services.ErrDestinationWalletInactive) {
+		return status.Error(codes.FailedPrecondition, "Destination wallet is not active")
+	}
+	if errors.Is(err, services.ErrCurrencyMismatch) {
+		return status.Error(codes.FailedPrecondition, "Currency does not match wallet currency")
+	}
+	if errors.Is(err, postgresrepo.ErrHoldNotFound) {
+		return status.Error(codes.NotFound, "Hold not found")
+	}
+	if errors.Is(err, services.ErrHoldNotActive) {
+		return status.Error(codes.FailedPrecondition, "Hold is not active")
+	}
+	if errors.Is(err, services.ErrAmountExceedsHold) {
+		return status.Error(codes.FailedPrecondition, "Amount exceeds remaining held amount")
+	}
+	if errors.Is(err, services.ErrInvalidHoldExpiry) {
+		return status.Error(codes.InvalidArgument, "ExpiresAt must be in the future")
+	}
+	if errors.Is(err, services.ErrInvalidExecuteAt) {
+		return status.Error(codes.InvalidArgument, "ExecuteAt must be in the future")
+	}
+	if errors.Is(err, services.ErrNotSchedulable) {
+		return status.Error(codes.InvalidArgument, "ExecuteAt is only allowed for DEPOSIT, WITHDRAW and TRANSFER")
+	}
+	return internalError("Failed to create operation", err)
+}
+
+func (s *Wallet) GetOperation(ctx context.Context, req *walletpb.GetOperationRequest) (*walletpb.Operation, error) {
+	if err := s.validate.Var(req.GetWalletId(), "required,uuid4"); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid wallet ID format")
+	}
+	if err := s.validate.Var(req.GetOperationId(), "required,uuid4"); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid operation ID format")
+	}
+
+	if err := s.authorize(ctx, auth.ScopeOperationsRead, req.GetWalletId()); err != nil {
+		return nil, err
+	}
+
+	operation, err := s.walletService.GetOperation(ctx, req.GetWalletId(), req.GetOperationId())
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrOperationNotFound) {
+			return nil, status.Error(codes.NotFound, "Operation not found")
+		}
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			return nil, status.Error(codes.NotFound, "Wallet not found")
+		}
+		return nil, internalError("Failed to get operation status", err)
+	}
+
+	return operationMessage(operation), nil
+}
+
+// internalError logs an unexpected error with an error ID like the REST API and
+// returns a status that carries only the ID
+func internalError(message string, err error) error {
+	p := problem.Internal(message, err)
+	return status.Errorf(codes.Internal, "%s, error ID %s", p.Detail, p.ErrorID)
+}
@@ -0,0 +1,311 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"wallet-service/internal/auth"
+	"wallet-service/internal/config"
+	"wallet-service/internal/models"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/repositories/redisrepo"
+	"wallet-service/internal/services"
+	"wallet-service/internal/transport/grpc/walletpb"
+)
+
+const (
+	testWalletID      = "6f1c0b1e-4a5d-4a3b-9a4e-2f1d6c7b8a90"
+	otherWalletID     = "0b8e7f5a-3c2d-4e1f-8a9b-7c6d5e4f3a21"
+	testOperationID   = "1c9d0b7a-51f8-4f0e-9d5b-6c8a2e3f4b10"
+	bootstrapKey      = "bootstrap-key"
+	restrictedKey     = "restricted-key"
+	bufconnBufferSize = 1 << 20
+)
+
+// newTestClient serves the gRPC API in-process, with the interceptors of the app,
+// on a mocked database and an in-memory Redis
+func newTestClient(t *testing.T) (walletpb.WalletServiceClient, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.Config{Auth: config.AuthConfig{BootstrapKey: bootstrapKey}}
+	walletService := services.NewWalletService(
+		cfg,
+		postgresrepo.NewWalletRepository(sqlx.NewDb(db, "postgres")),
+		redisrepo.NewWalletRepository(client),
+		nil,
+		nil,
+	)
+
+	listener := bufconn.Listen(bufconnBufferSize)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryAuthenticator(walletService)),
+		grpc.StreamInterceptor(StreamAuthenticator(walletService)),
+	)
+	NewWallet(server, walletService)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return walletpb.NewWalletServiceClient(conn), mock
+}
+
+// withKey returns a context that sends the API key with the call
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), metadataKey, key)
+}
+
+// expectAPIKey expects the restricted key to be looked up, with its scopes and wallets
+func expectAPIKey(mock sqlmock.Sqlmock, scopes, walletIDs, ownerIDs string) {
+	mock.ExpectQuery(`SELECT .* FROM api_keys WHERE \(key_hash = \$1`).
+		WithArgs(auth.HashKey(restrictedKey), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "name", "key_prefix", "key_hash", "previous_key_hash", "previous_key_expires_at", "scopes", "wallet_ids", "owner_ids",
+			"expires_at", "created_at", "rotated_at", "revoked_at",
+		}).AddRow(
+			"key-1", "partner", "wk_test", auth.HashKey(restrictedKey), nil, nil, scopes, walletIDs, ownerIDs,
+			nil, time.Now(), nil, nil,
+		))
+}
+
+// expectWallet expects the wallet to be read with the status and owner
+func expectWallet(mock sqlmock.Sqlmock, walletID, status string, ownerID *string) {
+	mock.ExpectQuery(`SELECT .* FROM wallets WHERE id = \$1`).
+		WithArgs(walletID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "balance", "held_amount", "credit_limit", "daily_withdraw_limit", "monthly_withdraw_limit", "currency",
+			"status", "status_reason", "status_changed_at", "owner_id", "external_ref", "display_name", "labels", "created_at", "updated_at",
+		}).AddRow(
+			walletID, 1000, 0, 0, nil, nil, "EUR",
+			status, nil, nil, ownerID, nil, nil, []byte(`{}`), time.Now(), time.Now(),
+		))
+}
+
+func withdraw(walletID string) *walletpb.CreateOperationRequest {
+	return &walletpb.CreateOperationRequest{WalletId: walletID, OperationType: models.OperationTypeWithdraw, Amount: 100}
+}
+
+func TestAuthentication(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		expect func(sqlmock.Sqlmock)
+	}{
+		{"no credentials", context.Background(), nil},
+		{"unknown API key", withKey(restrictedKey), func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery(`FROM api_keys`).WillReturnError(sql.ErrNoRows)
+		}},
+		{"bearer token without a key set", metadata.AppendToOutgoingContext(context.Background(), authorizationKey, "Bearer abc"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := newTestClient(t)
+			if tt.expect != nil {
+				tt.expect(mock)
+			}
+
+			_, err := client.GetWalletBalance(tt.ctx, &walletpb.GetWalletBalanceRequest{WalletId: testWalletID})
+			if status.Code(err) != codes.Unauthenticated {
+				t.Errorf("got %v, want %s", err, codes.Unauthenticated)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	owner := "owner-2"
+
+	tests := []struct {
+		name   string
+		expect func(sqlmock.Sqlmock)
+		call   func(walletpb.WalletServiceClient) error
+	}{
+		{
+			name:   "missing scope",
+			expect: func(mock sqlmock.Sqlmock) { expectAPIKey(mock, "{wallets:read}", "{}", "{}") },
+			call: func(client walletpb.WalletServiceClient) error {
+				_, err := client.CreateOperation(withKey(restrictedKey), withdraw(testWalletID))
+				return err
+			},
+		},
+		{
+			name:   "foreign wallet",
+			expect: func(mock sqlmock.Sqlmock) { expectAPIKey(mock, "{wallets:read}", "{"+testWalletID+"}", "{}") },
+			call: func(client walletpb.WalletServiceClient) error {
+				_, err := client.GetWalletBalance(withKey(restrictedKey), &walletpb.GetWalletBalanceRequest{WalletId: otherWalletID})
+				return err
+			},
+		},
+		{
+			name: "wallet of another owner",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectAPIKey(mock, "{operations:read}", "{}", "{owner-1}")
+				expectWallet(mock, otherWalletID, models.WalletStatusActive, &owner)
+			},
+			call: func(client walletpb.WalletServiceClient) error {
+				_, err := client.GetOperation(withKey(restrictedKey), &walletpb.GetOperationRequest{WalletId: otherWalletID, OperationId: testOperationID})
+				return err
+			},
+		},
+		{
+			name: "unknown wallet of an owner key",
+			expect: func(mock sqlmock.Sqlmock) {
+				expectAPIKey(mock, "{operations:write}", "{}", "{owner-1}")
+				mock.ExpectQuery(`FROM wallets WHERE id = \$1`).WithArgs(otherWalletID).WillReturnError(sql.ErrNoRows)
+			},
+			call: func(client walletpb.WalletServiceClient) error {
+				_, err := client.CreateOperation(withKey(restrictedKey), withdraw(otherWalletID))
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := newTestClient(t)
+			tt.expect(mock)
+
+			if err := tt.call(client); status.Code(err) != codes.PermissionDenied {
+				t.Errorf("got %v, want %s", err, codes.PermissionDenied)
+			}
+		})
+	}
+}
+
+func TestCreateOperationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(sqlmock.Sqlmock)
+		req    *walletpb.CreateOperationRequest
+		want   codes.Code
+	}{
+		{
+			name:   "invalid request",
+			expect: func(sqlmock.Sqlmock) {},
+			req:    &walletpb.CreateOperationRequest{WalletId: "not-a-uuid", OperationType: models.OperationTypeWithdraw, Amount: 100},
+			want:   codes.InvalidArgument,
+		},
+		{
+			name: "wallet not found",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM wallets WHERE id = \$1`).WithArgs(testWalletID).WillReturnError(sql.ErrNoRows)
+			},
+			req:  withdraw(testWalletID),
+			want: codes.NotFound,
+		},
+		{
+			name:   "frozen wallet",
+			expect: func(mock sqlmock.Sqlmock) { expectWallet(mock, testWalletID, models.WalletStatusFrozen, nil) },
+			req:    withdraw(testWalletID),
+			want:   codes.FailedPrecondition,
+		},
+		{
+			name: "idempotency key reused",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM wallet_operations WHERE wallet_id = \$1 AND idempotency_key = \$2`).
+					WithArgs(testWalletID, "key-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "request_hash"}).AddRow(testOperationID, "another request"))
+			},
+			req: func() *walletpb.CreateOperationRequest {
+				req := withdraw(testWalletID)
+				req.IdempotencyKey = "key-1"
+				return req
+			}(),
+			want: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := newTestClient(t)
+			tt.expect(mock)
+
+			_, err := client.CreateOperation(withKey(bootstrapKey), tt.req)
+			if status.Code(err) != tt.want {
+				t.Errorf("got %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOperationError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{services.ErrOperationNotAllowed, codes.PermissionDenied},
+		{services.ErrIdempotencyKeyReused, codes.FailedPrecondition},
+		{postgresrepo.ErrWalletNotFound, codes.NotFound},
+		{services.ErrDestinationWalletNotFound, codes.NotFound},
+		{postgresrepo.ErrHoldNotFound, codes.NotFound},
+		{services.ErrWalletFrozen, codes.FailedPrecondition},
+		{services.ErrWalletClosed, codes.FailedPrecondition},
+		{services.ErrDestinationWalletInactive, codes.FailedPrecondition},
+		{fmt.Errorf("%w: operation in USD, wallet in EUR", services.ErrCurrencyMismatch), codes.FailedPrecondition},
+		{services.ErrHoldNotActive, codes.FailedPrecondition},
+		{services.ErrAmountExceedsHold, codes.FailedPrecondition},
+		{services.ErrInvalidHoldExpiry, codes.InvalidArgument},
+		{services.ErrInvalidExecuteAt, codes.InvalidArgument},
+		{services.ErrNotSchedulable, codes.InvalidArgument},
+		{errors.New("connection refused"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := status.Code(operationError(tt.err)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetOperationNotFound(t *testing.T) {
+	client, mock := newTestClient(t)
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM wallets WHERE id = \$1\)`).
+		WithArgs(testWalletID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`FROM wallet_operations`).WillReturnError(sql.ErrNoRows)
+
+	_, err := client.GetOperation(withKey(bootstrapKey), &walletpb.GetOperationRequest{WalletId: testWalletID, OperationId: testOperationID})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("got %v, want %s", err, codes.NotFound)
+	}
+}
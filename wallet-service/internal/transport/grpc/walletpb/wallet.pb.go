@@ -0,0 +1,1301 @@
+// gRPC API of the wallet service. It is backed by the same service layer as the
+// REST API under /api/v1 and follows its semantics: amounts are in minor units,
+// operations are accepted asynchronously and processed by the operation worker.
+// Every call carries an API key in the x-api-key metadata, with the same scopes
+// and wallet restrictions as over REST.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: wallet/v1/wallet.proto
+
+package walletpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateWalletRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Currency      string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"` // ISO 4217 code, defaults to USD
+	OwnerId       string                 `protobuf:"bytes,2,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	ExternalRef   string                 `protobuf:"bytes,3,opt,name=external_ref,json=externalRef,proto3" json:"external_ref,omitempty"` // the client's own ID of the wallet, requires owner_id
+	DisplayName   string                 `protobuf:"bytes,4,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWalletRequest) Reset() {
+	*x = CreateWalletRequest{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWalletRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWalletRequest) ProtoMessage() {}
+
+func (x *CreateWalletRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWalletRequest.ProtoReflect.Descriptor instead.
+func (*CreateWalletRequest) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateWalletRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CreateWalletRequest) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+func (x *CreateWalletRequest) GetExternalRef() string {
+	if x != nil {
+		return x.ExternalRef
+	}
+	return ""
+}
+
+func (x *CreateWalletRequest) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *CreateWalletRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type CreateWalletResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WalletId      string                 `protobuf:"bytes,1,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	Balance       int64                  `protobuf:"varint,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	OwnerId       *string                `protobuf:"bytes,4,opt,name=owner_id,json=ownerId,proto3,oneof" json:"owner_id,omitempty"`
+	ExternalRef   *string                `protobuf:"bytes,5,opt,name=external_ref,json=externalRef,proto3,oneof" json:"external_ref,omitempty"`
+	DisplayName   *string                `protobuf:"bytes,6,opt,name=display_name,json=displayName,proto3,oneof" json:"display_name,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"` // created, exists
+	Message       string                 `protobuf:"bytes,9,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWalletResponse) Reset() {
+	*x = CreateWalletResponse{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWalletResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWalletResponse) ProtoMessage() {}
+
+func (x *CreateWalletResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWalletResponse.ProtoReflect.Descriptor instead.
+func (*CreateWalletResponse) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateWalletResponse) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+func (x *CreateWalletResponse) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *CreateWalletResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CreateWalletResponse) GetOwnerId() string {
+	if x != nil && x.OwnerId != nil {
+		return *x.OwnerId
+	}
+	return ""
+}
+
+func (x *CreateWalletResponse) GetExternalRef() string {
+	if x != nil && x.ExternalRef != nil {
+		return *x.ExternalRef
+	}
+	return ""
+}
+
+func (x *CreateWalletResponse) GetDisplayName() string {
+	if x != nil && x.DisplayName != nil {
+		return *x.DisplayName
+	}
+	return ""
+}
+
+func (x *CreateWalletResponse) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *CreateWalletResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CreateWalletResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type GetWalletBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WalletId      string                 `protobuf:"bytes,1,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWalletBalanceRequest) Reset() {
+	*x = GetWalletBalanceRequest{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWalletBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWalletBalanceRequest) ProtoMessage() {}
+
+func (x *GetWalletBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWalletBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetWalletBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetWalletBalanceRequest) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+type GetWalletBalanceResponse struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	WalletId                  string                 `protobuf:"bytes,1,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	Balance                   int64                  `protobuf:"varint,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	AvailableBalance          int64                  `protobuf:"varint,3,opt,name=available_balance,json=availableBalance,proto3" json:"available_balance,omitempty"` // balance minus funds reserved by holds
+	CreditLimit               int64                  `protobuf:"varint,4,opt,name=credit_limit,json=creditLimit,proto3" json:"credit_limit,omitempty"`                // how far below zero the wallet may go
+	Headroom                  int64                  `protobuf:"varint,5,opt,name=headroom,proto3" json:"headroom,omitempty"`                                         // available balance plus credit limit
+	Currency                  string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	Status                    string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`                                             // ACTIVE, FROZEN, CLOSED
+	FormattedBalance          string                 `protobuf:"bytes,8,opt,name=formatted_balance,json=formattedBalance,proto3" json:"formatted_balance,omitempty"` // decimal amount in major units, e.g. "12.34"
+	FormattedAvailableBalance string                 `protobuf:"bytes,9,opt,name=formatted_available_balance,json=formattedAvailableBalance,proto3" json:"formatted_available_balance,omitempty"`
+	FormattedHeadroom         string                 `protobuf:"bytes,10,opt,name=formatted_headroom,json=formattedHeadroom,proto3" json:"formatted_headroom,omitempty"`
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *GetWalletBalanceResponse) Reset() {
+	*x = GetWalletBalanceResponse{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWalletBalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWalletBalanceResponse) ProtoMessage() {}
+
+func (x *GetWalletBalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWalletBalanceResponse.ProtoReflect.Descriptor instead.
+func (*GetWalletBalanceResponse) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetWalletBalanceResponse) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+func (x *GetWalletBalanceResponse) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *GetWalletBalanceResponse) GetAvailableBalance() int64 {
+	if x != nil {
+		return x.AvailableBalance
+	}
+	return 0
+}
+
+func (x *GetWalletBalanceResponse) GetCreditLimit() int64 {
+	if x != nil {
+		return x.CreditLimit
+	}
+	return 0
+}
+
+func (x *GetWalletBalanceResponse) GetHeadroom() int64 {
+	if x != nil {
+		return x.Headroom
+	}
+	return 0
+}
+
+func (x *GetWalletBalanceResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GetWalletBalanceResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetWalletBalanceResponse) GetFormattedBalance() string {
+	if x != nil {
+		return x.FormattedBalance
+	}
+	return ""
+}
+
+func (x *GetWalletBalanceResponse) GetFormattedAvailableBalance() string {
+	if x != nil {
+		return x.FormattedAvailableBalance
+	}
+	return ""
+}
+
+func (x *GetWalletBalanceResponse) GetFormattedHeadroom() string {
+	if x != nil {
+		return x.FormattedHeadroom
+	}
+	return ""
+}
+
+type CreateOperationRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	WalletId            string                 `protobuf:"bytes,1,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	OperationType       string                 `protobuf:"bytes,2,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`                     // DEPOSIT, WITHDRAW, TRANSFER, HOLD, CAPTURE, RELEASE
+	Amount              int64                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`                                                       // may be 0 only for RELEASE: the whole remaining hold is released
+	DestinationWalletId string                 `protobuf:"bytes,4,opt,name=destination_wallet_id,json=destinationWalletId,proto3" json:"destination_wallet_id,omitempty"` // required for TRANSFER
+	HoldId              string                 `protobuf:"bytes,5,opt,name=hold_id,json=holdId,proto3" json:"hold_id,omitempty"`                                          // required for CAPTURE and RELEASE
+	ExpiresAt           *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`                                 // only for HOLD
+	ExecuteAt           *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=execute_at,json=executeAt,proto3" json:"execute_at,omitempty"`                                 // schedules a DEPOSIT, WITHDRAW or TRANSFER
+	Currency            string                 `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`                                                    // defaults to the wallet currency
+	IdempotencyKey      string                 `protobuf:"bytes,9,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	Description         string                 `protobuf:"bytes,10,opt,name=description,proto3" json:"description,omitempty"`
+	ExternalRef         string                 `protobuf:"bytes,11,opt,name=external_ref,json=externalRef,proto3" json:"external_ref,omitempty"`
+	Metadata            string                 `protobuf:"bytes,12,opt,name=metadata,proto3" json:"metadata,omitempty"` // JSON object of at most 4 KB
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CreateOperationRequest) Reset() {
+	*x = CreateOperationRequest{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOperationRequest) ProtoMessage() {}
+
+func (x *CreateOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOperationRequest.ProtoReflect.Descriptor instead.
+func (*CreateOperationRequest) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateOperationRequest) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+func (x *CreateOperationRequest) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+func (x *CreateOperationRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CreateOperationRequest) GetDestinationWalletId() string {
+	if x != nil {
+		return x.DestinationWalletId
+	}
+	return ""
+}
+
+func (x *CreateOperationRequest) GetHoldId() string {
+	if x != nil {
+		return x.HoldId
+	}
+	return ""
+}
+
+func (x *CreateOperationRequest) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *CreateOperationRequest) GetExecuteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExecuteAt
+	}
+	return nil
+}
+
+func (x *CreateOperationRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CreateOperationRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateOperationRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateOperationRequest) GetExternalRef() string {
+	if x != nil {
+		return x.ExternalRef
+	}
+	return ""
+}
+
+func (x *CreateOperationRequest) GetMetadata() string {
+	if x != nil {
+		return x.Metadata
+	}
+	return ""
+}
+
+type CreateOperationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationId   string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // accepted
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Replayed      bool                   `protobuf:"varint,4,opt,name=replayed,proto3" json:"replayed,omitempty"` // created by an earlier request with the same idempotency_key
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOperationResponse) Reset() {
+	*x = CreateOperationResponse{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOperationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOperationResponse) ProtoMessage() {}
+
+func (x *CreateOperationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOperationResponse.ProtoReflect.Descriptor instead.
+func (*CreateOperationResponse) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateOperationResponse) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+func (x *CreateOperationResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CreateOperationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CreateOperationResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
+type GetOperationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WalletId      string                 `protobuf:"bytes,1,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	OperationId   string                 `protobuf:"bytes,2,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOperationRequest) Reset() {
+	*x = GetOperationRequest{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOperationRequest) ProtoMessage() {}
+
+func (x *GetOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOperationRequest.ProtoReflect.Descriptor instead.
+func (*GetOperationRequest) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetOperationRequest) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+func (x *GetOperationRequest) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+// OperationLeg is one side of a transfer
+type OperationLeg struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WalletId      string                 `protobuf:"bytes,1,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	Direction     string                 `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"` // DEBIT, CREDIT
+	Amount        int64                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OperationLeg) Reset() {
+	*x = OperationLeg{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OperationLeg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperationLeg) ProtoMessage() {}
+
+func (x *OperationLeg) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperationLeg.ProtoReflect.Descriptor instead.
+func (*OperationLeg) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *OperationLeg) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+func (x *OperationLeg) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *OperationLeg) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type Operation struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	OperationId         string                 `protobuf:"bytes,1,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	WalletId            string                 `protobuf:"bytes,2,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	DestinationWalletId *string                `protobuf:"bytes,3,opt,name=destination_wallet_id,json=destinationWalletId,proto3,oneof" json:"destination_wallet_id,omitempty"`
+	OperationType       string                 `protobuf:"bytes,4,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	Amount              int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency            string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	FormattedAmount     string                 `protobuf:"bytes,7,opt,name=formatted_amount,json=formattedAmount,proto3" json:"formatted_amount,omitempty"`
+	Fee                 int64                  `protobuf:"varint,8,opt,name=fee,proto3" json:"fee,omitempty"`
+	FormattedFee        string                 `protobuf:"bytes,9,opt,name=formatted_fee,json=formattedFee,proto3" json:"formatted_fee,omitempty"`
+	Status              string                 `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"` // SCHEDULED, PENDING, PROCESSED, FAILED, CANCELLED
+	ProcessedAt         *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=processed_at,json=processedAt,proto3" json:"processed_at,omitempty"`
+	ExecuteAt           *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=execute_at,json=executeAt,proto3" json:"execute_at,omitempty"`
+	Error               *string                `protobuf:"bytes,13,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	Legs                []*OperationLeg        `protobuf:"bytes,14,rep,name=legs,proto3" json:"legs,omitempty"`
+	HoldId              *string                `protobuf:"bytes,15,opt,name=hold_id,json=holdId,proto3,oneof" json:"hold_id,omitempty"`                                          // hold captured or released by this operation
+	ExpiresAt           *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`                                       // expiry of a HOLD
+	ReversedOperationId *string                `protobuf:"bytes,17,opt,name=reversed_operation_id,json=reversedOperationId,proto3,oneof" json:"reversed_operation_id,omitempty"` // operation undone by a REVERSAL
+	ReversedAmount      int64                  `protobuf:"varint,18,opt,name=reversed_amount,json=reversedAmount,proto3" json:"reversed_amount,omitempty"`
+	StandingOrderId     *string                `protobuf:"bytes,19,opt,name=standing_order_id,json=standingOrderId,proto3,oneof" json:"standing_order_id,omitempty"`
+	Description         *string                `protobuf:"bytes,20,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	ExternalRef         *string                `protobuf:"bytes,21,opt,name=external_ref,json=externalRef,proto3,oneof" json:"external_ref,omitempty"`
+	Metadata            *string                `protobuf:"bytes,22,opt,name=metadata,proto3,oneof" json:"metadata,omitempty"` // JSON object
+	CreatedAt           *timestamppb.Timestamp `protobuf:"bytes,23,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CreatedBy           *string                `protobuf:"bytes,24,opt,name=created_by,json=createdBy,proto3,oneof" json:"created_by,omitempty"` // ID of the API key that created the operation
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Operation) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+func (x *Operation) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+func (x *Operation) GetDestinationWalletId() string {
+	if x != nil && x.DestinationWalletId != nil {
+		return *x.DestinationWalletId
+	}
+	return ""
+}
+
+func (x *Operation) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+func (x *Operation) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Operation) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Operation) GetFormattedAmount() string {
+	if x != nil {
+		return x.FormattedAmount
+	}
+	return ""
+}
+
+func (x *Operation) GetFee() int64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *Operation) GetFormattedFee() string {
+	if x != nil {
+		return x.FormattedFee
+	}
+	return ""
+}
+
+func (x *Operation) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Operation) GetProcessedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ProcessedAt
+	}
+	return nil
+}
+
+func (x *Operation) GetExecuteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExecuteAt
+	}
+	return nil
+}
+
+func (x *Operation) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *Operation) GetLegs() []*OperationLeg {
+	if x != nil {
+		return x.Legs
+	}
+	return nil
+}
+
+func (x *Operation) GetHoldId() string {
+	if x != nil && x.HoldId != nil {
+		return *x.HoldId
+	}
+	return ""
+}
+
+func (x *Operation) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Operation) GetReversedOperationId() string {
+	if x != nil && x.ReversedOperationId != nil {
+		return *x.ReversedOperationId
+	}
+	return ""
+}
+
+func (x *Operation) GetReversedAmount() int64 {
+	if x != nil {
+		return x.ReversedAmount
+	}
+	return 0
+}
+
+func (x *Operation) GetStandingOrderId() string {
+	if x != nil && x.StandingOrderId != nil {
+		return *x.StandingOrderId
+	}
+	return ""
+}
+
+func (x *Operation) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *Operation) GetExternalRef() string {
+	if x != nil && x.ExternalRef != nil {
+		return *x.ExternalRef
+	}
+	return ""
+}
+
+func (x *Operation) GetMetadata() string {
+	if x != nil && x.Metadata != nil {
+		return *x.Metadata
+	}
+	return ""
+}
+
+func (x *Operation) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Operation) GetCreatedBy() string {
+	if x != nil && x.CreatedBy != nil {
+		return *x.CreatedBy
+	}
+	return ""
+}
+
+type WatchOperationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WalletId      string                 `protobuf:"bytes,1,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	LastEventId   string                 `protobuf:"bytes,2,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"` // resume after this event
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchOperationsRequest) Reset() {
+	*x = WatchOperationsRequest{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchOperationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchOperationsRequest) ProtoMessage() {}
+
+func (x *WatchOperationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchOperationsRequest.ProtoReflect.Descriptor instead.
+func (*WatchOperationsRequest) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchOperationsRequest) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+func (x *WatchOperationsRequest) GetLastEventId() string {
+	if x != nil {
+		return x.LastEventId
+	}
+	return ""
+}
+
+type OperationEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	WalletId      string                 `protobuf:"bytes,2,opt,name=wallet_id,json=walletId,proto3" json:"wallet_id,omitempty"`
+	OperationId   string                 `protobuf:"bytes,3,opt,name=operation_id,json=operationId,proto3" json:"operation_id,omitempty"`
+	OperationType string                 `protobuf:"bytes,4,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"` // PROCESSED, FAILED
+	Amount        int64                  `protobuf:"varint,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	Fee           int64                  `protobuf:"varint,7,opt,name=fee,proto3" json:"fee,omitempty"`
+	Currency      string                 `protobuf:"bytes,8,opt,name=currency,proto3" json:"currency,omitempty"`
+	Error         *string                `protobuf:"bytes,9,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	ProcessedAt   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=processed_at,json=processedAt,proto3" json:"processed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OperationEvent) Reset() {
+	*x = OperationEvent{}
+	mi := &file_wallet_v1_wallet_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OperationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperationEvent) ProtoMessage() {}
+
+func (x *OperationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_wallet_v1_wallet_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperationEvent.ProtoReflect.Descriptor instead.
+func (*OperationEvent) Descriptor() ([]byte, []int) {
+	return file_wallet_v1_wallet_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *OperationEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *OperationEvent) GetWalletId() string {
+	if x != nil {
+		return x.WalletId
+	}
+	return ""
+}
+
+func (x *OperationEvent) GetOperationId() string {
+	if x != nil {
+		return x.OperationId
+	}
+	return ""
+}
+
+func (x *OperationEvent) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+func (x *OperationEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *OperationEvent) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *OperationEvent) GetFee() int64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *OperationEvent) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *OperationEvent) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *OperationEvent) GetProcessedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ProcessedAt
+	}
+	return nil
+}
+
+var File_wallet_v1_wallet_proto protoreflect.FileDescriptor
+
+const file_wallet_v1_wallet_proto_rawDesc = "" +
+	"\n" +
+	"\x16wallet/v1/wallet.proto\x12\twallet.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x91\x02\n" +
+	"\x13CreateWalletRequest\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12\x19\n" +
+	"\bowner_id\x18\x02 \x01(\tR\aownerId\x12!\n" +
+	"\fexternal_ref\x18\x03 \x01(\tR\vexternalRef\x12!\n" +
+	"\fdisplay_name\x18\x04 \x01(\tR\vdisplayName\x12B\n" +
+	"\x06labels\x18\x05 \x03(\v2*.wallet.v1.CreateWalletRequest.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xba\x03\n" +
+	"\x14CreateWalletResponse\x12\x1b\n" +
+	"\twallet_id\x18\x01 \x01(\tR\bwalletId\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\x03R\abalance\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x1e\n" +
+	"\bowner_id\x18\x04 \x01(\tH\x00R\aownerId\x88\x01\x01\x12&\n" +
+	"\fexternal_ref\x18\x05 \x01(\tH\x01R\vexternalRef\x88\x01\x01\x12&\n" +
+	"\fdisplay_name\x18\x06 \x01(\tH\x02R\vdisplayName\x88\x01\x01\x12C\n" +
+	"\x06labels\x18\a \x03(\v2+.wallet.v1.CreateWalletResponse.LabelsEntryR\x06labels\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\t \x01(\tR\amessage\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\v\n" +
+	"\t_owner_idB\x0f\n" +
+	"\r_external_refB\x0f\n" +
+	"\r_display_name\"6\n" +
+	"\x17GetWalletBalanceRequest\x12\x1b\n" +
+	"\twallet_id\x18\x01 \x01(\tR\bwalletId\"\x8d\x03\n" +
+	"\x18GetWalletBalanceResponse\x12\x1b\n" +
+	"\twallet_id\x18\x01 \x01(\tR\bwalletId\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\x03R\abalance\x12+\n" +
+	"\x11available_balance\x18\x03 \x01(\x03R\x10availableBalance\x12!\n" +
+	"\fcredit_limit\x18\x04 \x01(\x03R\vcreditLimit\x12\x1a\n" +
+	"\bheadroom\x18\x05 \x01(\x03R\bheadroom\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12+\n" +
+	"\x11formatted_balance\x18\b \x01(\tR\x10formattedBalance\x12>\n" +
+	"\x1bformatted_available_balance\x18\t \x01(\tR\x19formattedAvailableBalance\x12-\n" +
+	"\x12formatted_headroom\x18\n" +
+	" \x01(\tR\x11formattedHeadroom\"\xdd\x03\n" +
+	"\x16CreateOperationRequest\x12\x1b\n" +
+	"\twallet_id\x18\x01 \x01(\tR\bwalletId\x12%\n" +
+	"\x0eoperation_type\x18\x02 \x01(\tR\roperationType\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x03R\x06amount\x122\n" +
+	"\x15destination_wallet_id\x18\x04 \x01(\tR\x13destinationWalletId\x12\x17\n" +
+	"\ahold_id\x18\x05 \x01(\tR\x06holdId\x129\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x129\n" +
+	"\n" +
+	"execute_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\texecuteAt\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12'\n" +
+	"\x0fidempotency_key\x18\t \x01(\tR\x0eidempotencyKey\x12 \n" +
+	"\vdescription\x18\n" +
+	" \x01(\tR\vdescription\x12!\n" +
+	"\fexternal_ref\x18\v \x01(\tR\vexternalRef\x12\x1a\n" +
+	"\bmetadata\x18\f \x01(\tR\bmetadata\"\x8a\x01\n" +
+	"\x17CreateOperationResponse\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1a\n" +
+	"\breplayed\x18\x04 \x01(\bR\breplayed\"U\n" +
+	"\x13GetOperationRequest\x12\x1b\n" +
+	"\twallet_id\x18\x01 \x01(\tR\bwalletId\x12!\n" +
+	"\foperation_id\x18\x02 \x01(\tR\voperationId\"a\n" +
+	"\fOperationLeg\x12\x1b\n" +
+	"\twallet_id\x18\x01 \x01(\tR\bwalletId\x12\x1c\n" +
+	"\tdirection\x18\x02 \x01(\tR\tdirection\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x03R\x06amount\"\xf3\b\n" +
+	"\tOperation\x12!\n" +
+	"\foperation_id\x18\x01 \x01(\tR\voperationId\x12\x1b\n" +
+	"\twallet_id\x18\x02 \x01(\tR\bwalletId\x127\n" +
+	"\x15destination_wallet_id\x18\x03 \x01(\tH\x00R\x13destinationWalletId\x88\x01\x01\x12%\n" +
+	"\x0eoperation_type\x18\x04 \x01(\tR\roperationType\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12)\n" +
+	"\x10formatted_amount\x18\a \x01(\tR\x0fformattedAmount\x12\x10\n" +
+	"\x03fee\x18\b \x01(\x03R\x03fee\x12#\n" +
+	"\rformatted_fee\x18\t \x01(\tR\fformattedFee\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x12=\n" +
+	"\fprocessed_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vprocessedAt\x129\n" +
+	"\n" +
+	"execute_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\texecuteAt\x12\x19\n" +
+	"\x05error\x18\r \x01(\tH\x01R\x05error\x88\x01\x01\x12+\n" +
+	"\x04legs\x18\x0e \x03(\v2\x17.wallet.v1.OperationLegR\x04legs\x12\x1c\n" +
+	"\ahold_id\x18\x0f \x01(\tH\x02R\x06holdId\x88\x01\x01\x129\n" +
+	"\n" +
+	"expires_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x127\n" +
+	"\x15reversed_operation_id\x18\x11 \x01(\tH\x03R\x13reversedOperationId\x88\x01\x01\x12'\n" +
+	"\x0freversed_amount\x18\x12 \x01(\x03R\x0ereversedAmount\x12/\n" +
+	"\x11standing_order_id\x18\x13 \x01(\tH\x04R\x0fstandingOrderId\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x14 \x01(\tH\x05R\vdescription\x88\x01\x01\x12&\n" +
+	"\fexternal_ref\x18\x15 \x01(\tH\x06R\vexternalRef\x88\x01\x01\x12\x1f\n" +
+	"\bmetadata\x18\x16 \x01(\tH\aR\bmetadata\x88\x01\x01\x129\n" +
+	"\n" +
+	"created_at\x18\x17 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\"\n" +
+	"\n" +
+	"created_by\x18\x18 \x01(\tH\bR\tcreatedBy\x88\x01\x01B\x18\n" +
+	"\x16_destination_wallet_idB\b\n" +
+	"\x06_errorB\n" +
+	"\n" +
+	"\b_hold_idB\x18\n" +
+	"\x16_reversed_operation_idB\x14\n" +
+	"\x12_standing_order_idB\x0e\n" +
+	"\f_descriptionB\x0f\n" +
+	"\r_external_refB\v\n" +
+	"\t_metadataB\r\n" +
+	"\v_created_by\"Y\n" +
+	"\x16WatchOperationsRequest\x12\x1b\n" +
+	"\twallet_id\x18\x01 \x01(\tR\bwalletId\x12\"\n" +
+	"\rlast_event_id\x18\x02 \x01(\tR\vlastEventId\"\xd4\x02\n" +
+	"\x0eOperationEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x1b\n" +
+	"\twallet_id\x18\x02 \x01(\tR\bwalletId\x12!\n" +
+	"\foperation_id\x18\x03 \x01(\tR\voperationId\x12%\n" +
+	"\x0eoperation_type\x18\x04 \x01(\tR\roperationType\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x16\n" +
+	"\x06amount\x18\x06 \x01(\x03R\x06amount\x12\x10\n" +
+	"\x03fee\x18\a \x01(\x03R\x03fee\x12\x1a\n" +
+	"\bcurrency\x18\b \x01(\tR\bcurrency\x12\x19\n" +
+	"\x05error\x18\t \x01(\tH\x00R\x05error\x88\x01\x01\x12=\n" +
+	"\fprocessed_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\vprocessedAtB\b\n" +
+	"\x06_error2\xb0\x03\n" +
+	"\rWalletService\x12O\n" +
+	"\fCreateWallet\x12\x1e.wallet.v1.CreateWalletRequest\x1a\x1f.wallet.v1.CreateWalletResponse\x12[\n" +
+	"\x10GetWalletBalance\x12\".wallet.v1.GetWalletBalanceRequest\x1a#.wallet.v1.GetWalletBalanceResponse\x12X\n" +
+	"\x0fCreateOperation\x12!.wallet.v1.CreateOperationRequest\x1a\".wallet.v1.CreateOperationResponse\x12D\n" +
+	"\fGetOperation\x12\x1e.wallet.v1.GetOperationRequest\x1a\x14.wallet.v1.Operation\x12Q\n" +
+	"\x0fWatchOperations\x12!.wallet.v1.WatchOperationsRequest\x1a\x19.wallet.v1.OperationEvent0\x01B1Z/wallet-service/internal/transport/grpc/walletpbb\x06proto3"
+
+var (
+	file_wallet_v1_wallet_proto_rawDescOnce sync.Once
+	file_wallet_v1_wallet_proto_rawDescData []byte
+)
+
+func file_wallet_v1_wallet_proto_rawDescGZIP() []byte {
+	file_wallet_v1_wallet_proto_rawDescOnce.Do(func() {
+		file_wallet_v1_wallet_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_wallet_v1_wallet_proto_rawDesc), len(file_wallet_v1_wallet_proto_rawDesc)))
+	})
+	return file_wallet_v1_wallet_proto_rawDescData
+}
+
+var file_wallet_v1_wallet_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_wallet_v1_wallet_proto_goTypes = []any{
+	(*CreateWalletRequest)(nil),      // 0: wallet.v1.CreateWalletRequest
+	(*CreateWalletResponse)(nil),     // 1: wallet.v1.CreateWalletResponse
+	(*GetWalletBalanceRequest)(nil),  // 2: wallet.v1.GetWalletBalanceRequest
+	(*GetWalletBalanceResponse)(nil), // 3: wallet.v1.GetWalletBalanceResponse
+	(*CreateOperationRequest)(nil),   // 4: wallet.v1.CreateOperationRequest
+	(*CreateOperationResponse)(nil),  // 5: wallet.v1.CreateOperationResponse
+	(*GetOperationRequest)(nil),      // 6: wallet.v1.GetOperationRequest
+	(*OperationLeg)(nil),             // 7: wallet.v1.OperationLeg
+	(*Operation)(nil),                // 8: wallet.v1.Operation
+	(*WatchOperationsRequest)(nil),   // 9: wallet.v1.WatchOperationsRequest
+	(*OperationEvent)(nil),           // 10: wallet.v1.OperationEvent
+	nil,                              // 11: wallet.v1.CreateWalletRequest.LabelsEntry
+	nil,                              // 12: wallet.v1.CreateWalletResponse.LabelsEntry
+	(*timestamppb.Timestamp)(nil),    // 13: google.protobuf.Timestamp
+}
+var file_wallet_v1_wallet_proto_depIdxs = []int32{
+	11, // 0: wallet.v1.CreateWalletRequest.labels:type_name -> wallet.v1.CreateWalletRequest.LabelsEntry
+	12, // 1: wallet.v1.CreateWalletResponse.labels:type_name -> wallet.v1.CreateWalletResponse.LabelsEntry
+	13, // 2: wallet.v1.CreateOperationRequest.expires_at:type_name -> google.protobuf.Timestamp
+	13, // 3: wallet.v1.CreateOperationRequest.execute_at:type_name -> google.protobuf.Timestamp
+	13, // 4: wallet.v1.Operation.processed_at:type_name -> google.protobuf.Timestamp
+	13, // 5: wallet.v1.Operation.execute_at:type_name -> google.protobuf.Timestamp
+	7,  // 6: wallet.v1.Operation.legs:type_name -> wallet.v1.OperationLeg
+	13, // 7: wallet.v1.Operation.expires_at:type_name -> google.protobuf.Timestamp
+	13, // 8: wallet.v1.Operation.created_at:type_name -> google.protobuf.Timestamp
+	13, // 9: wallet.v1.OperationEvent.processed_at:type_name -> google.protobuf.Timestamp
+	0,  // 10: wallet.v1.WalletService.CreateWallet:input_type -> wallet.v1.CreateWalletRequest
+	2,  // 11: wallet.v1.WalletService.GetWalletBalance:input_type -> wallet.v1.GetWalletBalanceRequest
+	4,  // 12: wallet.v1.WalletService.CreateOperation:input_type -> wallet.v1.CreateOperationRequest
+	6,  // 13: wallet.v1.WalletService.GetOperation:input_type -> wallet.v1.GetOperationRequest
+	9,  // 14: wallet.v1.WalletService.WatchOperations:input_type -> wallet.v1.WatchOperationsRequest
+	1,  // 15: wallet.v1.WalletService.CreateWallet:output_type -> wallet.v1.CreateWalletResponse
+	3,  // 16: wallet.v1.WalletService.GetWalletBalance:output_type -> wallet.v1.GetWalletBalanceResponse
+	5,  // 17: wallet.v1.WalletService.CreateOperation:output_type -> wallet.v1.CreateOperationResponse
+	8,  // 18: wallet.v1.WalletService.GetOperation:output_type -> wallet.v1.Operation
+	10, // 19: wallet.v1.WalletService.WatchOperations:output_type -> wallet.v1.OperationEvent
+	15, // [15:20] is the sub-list for method output_type
+	10, // [10:15] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_wallet_v1_wallet_proto_init() }
+func file_wallet_v1_wallet_proto_init() {
+	if File_wallet_v1_wallet_proto != nil {
+		return
+	}
+	file_wallet_v1_wallet_proto_msgTypes[1].OneofWrappers = []any{}
+	file_wallet_v1_wallet_proto_msgTypes[8].OneofWrappers = []any{}
+	file_wallet_v1_wallet_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_wallet_v1_wallet_proto_rawDesc), len(file_wallet_v1_wallet_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_wallet_v1_wallet_proto_goTypes,
+		DependencyIndexes: file_wallet_v1_wallet_proto_depIdxs,
+		MessageInfos:      file_wallet_v1_wallet_proto_msgTypes,
+	}.Build()
+	File_wallet_v1_wallet_proto = out.File
+	file_wallet_v1_wallet_proto_goTypes = nil
+	file_wallet_v1_wallet_proto_depIdxs = nil
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"wallet-service/internal/models"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/services"
+	"wallet-service/internal/validation"
+)
+
+// @Summary Freeze a wallet
+// @Description Blocks new operations of an ACTIVE wallet. Operations already queued for it fail with "wallet is frozen".
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param request body models.WalletStatusRequest true "Reason"
+// @Success 200 {object} models.WalletStatusResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/wallets/{walletId}/freeze [post]
+// @Router /v2/admin/wallets/{walletId}/freeze [post]
+func (h *Wallet) freezeWallet(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus(w, r, h.walletService.FreezeWallet)
+}
+
+// @Summary Unfreeze a wallet
+// @Description Makes a FROZEN wallet ACTIVE again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param request body models.WalletStatusRequest true "Reason"
+// @Success 200 {object} models.WalletStatusResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/wallets/{walletId}/unfreeze [post]
+// @Router /v2/admin/wallets/{walletId}/unfreeze [post]
+func (h *Wallet) unfreezeWallet(w http.ResponseWriter, r *http.Request) {
+	h.changeWalletStatus(w, r, h.walletService.UnfreezeWallet)
+}
+
+func (h *Wallet) changeWalletStatus(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, walletID, reason string) (*models.WalletStatusResponse, error)) {
+	walletID := r.PathValue("walletId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+
+	var req models.WalletStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeInvalid(w, r, validation.Fields(err, ""))
+		return
+	}
+
+	status, err := change(r.Context(), walletID, req.Reason)
+	if err != nil {
+		h.writeWalletStatusError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, status)
+}
+
+// @Summary Close a wallet
+// @Description Closes an ACTIVE or FROZEN wallet for good. The wallet must have no active holds and no debt.
+// @Description A positive balance requires sweepDestinationWalletId: the wallet is frozen and the balance is moved there
+// @Description by a TRANSFER (no fee, no limits, sweepOperationId) that the worker processes like any other and that closes
+// @Description the wallet once posted. Until then the response is 202 with the FROZEN status; with wait (or "Prefer: wait=<seconds>")
+// @Description the request blocks, up to 30s, for the sweep and returns 200 once the wallet is closed or 409 when the sweep failed.
+// @Description Scheduled operations of the wallet are cancelled and its standing orders completed.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param wait query string false "How long to wait for the sweep, e.g. 5s"
+// @Param Prefer header string false "wait=<seconds>, used when the wait parameter is absent"
+// @Param request body models.CloseWalletRequest true "Close Request"
+// @Success 200 {object} models.WalletStatusResponse
+// @Success 202 {object} models.WalletStatusResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/wallets/{walletId}/close [post]
+// @Router /v2/admin/wallets/{walletId}/close [post]
+func (h *Wallet) closeWallet(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+
+	var req models.CloseWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeInvalid(w, r, validation.Fields(err, ""))
+		return
+	}
+
+	if req.SweepDestinationWalletID == walletID {
+		h.writeInvalid(w, r, validation.Field("sweepDestinationWalletId", "nefield", "SweepDestinationWalletID must differ from WalletID"))
+		return
+	}
+
+	wait, ok := parseWait(w, r)
+	if !ok {
+		h.writeInvalid(w, r, validation.Field("wait", "duration", "Invalid wait duration"))
+		return
+	}
+	if wait > 0 {
+		extendWriteDeadline(w, wait)
+	}
+
+	status, err := h.walletService.CloseWallet(r.Context(), walletID, req, wait)
+	if err != nil {
+		h.writeWalletStatusError(w, r, err)
+		return
+	}
+
+	// The wallet stays frozen until the worker processes the sweep
+	statusCode := http.StatusOK
+	if status.Status != models.WalletStatusClosed {
+		statusCode = http.StatusAccepted
+	}
+	h.writeJSON(w, r, statusCode, status)
+}
+
+// writeWalletStatusError maps the errors of a wallet status change to responses
+func (h *Wallet) writeWalletStatusError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+		h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+		return
+	}
+	if errors.Is(err, services.ErrWalletFrozen) {
+		h.writeProblem(w, r, http.StatusConflict, problem.WalletFrozen, "Wallet is frozen")
+		return
+	}
+	if errors.Is(err, services.ErrWalletClosed) {
+		h.writeProblem(w, r, http.StatusConflict, problem.WalletClosed, "Wallet is closed")
+		return
+	}
+	if errors.Is(err, services.ErrWalletNotFrozen) {
+		h.writeProblem(w, r, http.StatusConflict, problem.WalletNotFrozen, "Wallet is not frozen")
+		return
+	}
+	if errors.Is(err, services.ErrWalletHasHolds) {
+		h.writeProblem(w, r, http.StatusConflict, problem.WalletHasHolds, "Wallet has active holds")
+		return
+	}
+	if errors.Is(err, services.ErrWalletHasDebt) {
+		h.writeProblem(w, r, http.StatusConflict, problem.NegativeBalance, "Wallet has a negative balance")
+		return
+	}
+	if errors.Is(err, services.ErrWalletNotEmpty) {
+		h.writeProblem(w, r, http.StatusConflict, problem.BalanceNotZero, "Wallet balance must be zero or a sweep destination must be given")
+		return
+	}
+	if errors.Is(err, services.ErrSweepFailed) {
+		h.writeProblem(w, r, http.StatusConflict, problem.SweepFailed, fmt.Sprintf("Wallet stays frozen, %v", err))
+		return
+	}
+	if errors.Is(err, services.ErrDestinationWalletNotFound) {
+		h.writeProblem(w, r, http.StatusNotFound, problem.DestinationWalletNotFound, "Sweep destination wallet not found")
+		return
+	}
+	if errors.Is(err, services.ErrCurrencyMismatch) {
+		h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.CurrencyMismatch, "Sweep destination currency does not match wallet currency")
+		return
+	}
+	if errors.Is(err, services.ErrDestinationWalletInactive) {
+		h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.DestinationWalletInactive, "Sweep destination wallet is not active")
+		return
+	}
+	h.writeInternalError(w, r, "Failed to change wallet status", err)
+}
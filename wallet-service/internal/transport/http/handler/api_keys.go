@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"wallet-service/internal/models"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/services"
+	"wallet-service/internal/validation"
+)
+
+// @Summary Create an API key
+// @Description Creates an API key with scopes: wallets:read, wallets:write, operations:read, operations:write, webhooks
+// @Description and admin (every scope, on every wallet). With walletIds or ownerIds the key only reaches those wallets,
+// @Description or the wallets of those owners; such a key cannot have the webhooks or admin scope.
+// @Description The key is only returned in this response, just its hash is stored. Send it in the X-API-Key header.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param apiKey body models.APIKeyRequest true "API Key Request"
+// @Success 201 {object} models.APIKeyResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/api-keys [post]
+// @Router /v2/admin/api-keys [post]
+func (h *Wallet) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req models.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeInvalid(w, r, validation.Fields(err, ""))
+		return
+	}
+
+	apiKey, err := h.walletService.CreateAPIKey(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, services.ErrUnrestrictedScope) {
+			h.writeInvalid(w, r, validation.Field("scopes", "unrestricted", "The webhooks and admin scopes cannot be restricted to wallets or owners"))
+			return
+		}
+		if errors.Is(err, services.ErrInvalidAPIKeyExpiry) {
+			h.writeInvalid(w, r, validation.Field("expiresAt", "future", "ExpiresAt must be in the future"))
+			return
+		}
+		h.writeInternalError(w, r, "Failed to create API key", err)
+		return
+	}
+
+	h.writeCreated(w, r, http.StatusCreated, "/admin/api-keys/"+apiKey.APIKeyID, apiKey)
+}
+
+// @Summary List API keys
+// @Description Lists all API keys in creation order, revoked ones included. Keys themselves are never returned.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.APIKeyListResponse
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/api-keys [get]
+// @Router /v2/admin/api-keys [get]
+func (h *Wallet) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	apiKeys, err := h.walletService.ListAPIKeys(r.Context())
+	if err != nil {
+		h.writeInternalError(w, r, "Failed to list API keys", err)
+		return
+	}
+
+	h.writeList(w, r, apiKeys, apiKeys.APIKeys, "")
+}
+
+// @Summary Get an API key
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param apiKeyId path string true "API key ID (UUIDv4)"
+// @Success 200 {object} models.APIKeyResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/api-keys/{apiKeyId} [get]
+// @Router /v2/admin/api-keys/{apiKeyId} [get]
+func (h *Wallet) getAPIKey(w http.ResponseWriter, r *http.Request) {
+	apiKeyID, ok := h.apiKeyPath(w, r)
+	if !ok {
+		return
+	}
+
+	apiKey, err := h.walletService.GetAPIKey(r.Context(), apiKeyID)
+	if err != nil {
+		h.writeAPIKeyError(w, r, err, "Failed to get API key")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, apiKey)
+}
+
+// @Summary Rotate an API key
+// @Description Issues a new key for the API key, keeping its ID, scopes and restrictions. The new key is only returned
+// @Description in this response; the replaced key keeps working until previousKeyExpiresAt (API_KEY_ROTATION_GRACE).
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param apiKeyId path string true "API key ID (UUIDv4)"
+// @Success 200 {object} models.APIKeyResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/api-keys/{apiKeyId}/rotate [post]
+// @Router /v2/admin/api-keys/{apiKeyId}/rotate [post]
+func (h *Wallet) rotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	apiKeyID, ok := h.apiKeyPath(w, r)
+	if !ok {
+		return
+	}
+
+	apiKey, err := h.walletService.RotateAPIKey(r.Context(), apiKeyID)
+	if err != nil {
+		h.writeAPIKeyError(w, r, err, "Failed to rotate API key")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, apiKey)
+}
+
+// @Summary Revoke an API key
+// @Description Revokes an API key for good, together with its key before the last rotation. The key stays listed.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param apiKeyId path string true "API key ID (UUIDv4)"
+// @Success 200 {object} models.APIKeyResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/api-keys/{apiKeyId}/revoke [post]
+// @Router /v2/admin/api-keys/{apiKeyId}/revoke [post]
+func (h *Wallet) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	apiKeyID, ok := h.apiKeyPath(w, r)
+	if !ok {
+		return
+	}
+
+	apiKey, err := h.walletService.RevokeAPIKey(r.Context(), apiKeyID)
+	if err != nil {
+		h.writeAPIKeyError(w, r, err, "Failed to revoke API key")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, apiKey)
+}
+
+// apiKeyPath validates the API key ID of the path
+func (h *Wallet) apiKeyPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	apiKeyID := r.PathValue("apiKeyId")
+
+	if err := h.validate.Var(apiKeyID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("apiKeyId", "uuid4", "Invalid API key ID format"))
+		return "", false
+	}
+
+	return apiKeyID, true
+}
+
+// writeAPIKeyError maps the errors of an existing API key to responses
+func (h *Wallet) writeAPIKeyError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, postgresrepo.ErrAPIKeyNotFound) {
+		h.writeProblem(w, r, http.StatusNotFound, problem.APIKeyNotFound, "API key not found")
+		return
+	}
+	if errors.Is(err, services.ErrAPIKeyRevoked) {
+		h.writeProblem(w, r, http.StatusConflict, problem.APIKeyRevoked, "API key is revoked")
+		return
+	}
+	h.writeInternalError(w, r, message, err)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"wallet-service/internal/auth"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/services"
+	"wallet-service/internal/validation"
+)
+
+// publicPathPrefix is served without an API key
+const publicPathPrefix = "/swagger/"
+
+// Authenticate wraps the mux of the handlers: every request except the Swagger UI
+// needs a valid API key or bearer token, whose principal is passed on in the request
+// context. What the caller may do is checked per route by require.
+func (h *Wallet) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, publicPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var (
+			principal *auth.Principal
+			err       error
+		)
+		if token, ok := auth.BearerToken(r.Header.Get("Authorization")); ok {
+			principal, err = h.walletService.AuthenticateToken(r.Context(), token)
+		} else {
+			principal, err = h.walletService.Authenticate(r.Context(), r.Header.Get(auth.Header))
+		}
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", "Bearer error=\"invalid_token\"")
+				h.writeProblem(w, r, http.StatusUnauthorized, problem.Unauthenticated, "Invalid bearer token")
+				return
+			}
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				w.Header().Add("WWW-Authenticate", "ApiKey header=\""+auth.Header+"\"")
+				w.Header().Add("WWW-Authenticate", "Bearer")
+				h.writeProblem(w, r, http.StatusUnauthorized, problem.Unauthenticated, "Missing or invalid API key")
+				return
+			}
+			h.writeInternalError(w, r, "Failed to authenticate", err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// require lets a request through when its principal has scope and, on routes of a
+// wallet, may access that wallet. Wallets named in the body are checked by the handlers.
+func (h *Wallet) require(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := auth.FromContext(r.Context())
+		if principal == nil {
+			h.writeProblem(w, r, http.StatusUnauthorized, problem.Unauthenticated, "Missing or invalid API key")
+			return
+		}
+		if !principal.HasScope(scope) {
+			h.writeProblem(w, r, http.StatusForbidden, problem.InsufficientScope, fmt.Sprintf("Caller lacks the %s scope", scope))
+			return
+		}
+
+		if walletID := r.PathValue("walletId"); walletID != "" {
+			if err := h.validate.Var(walletID, "uuid4"); err != nil {
+				h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+				return
+			}
+			if !h.authorizeWallet(w, r, walletID) || !h.limitWallet(w, r, walletID) {
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
+
+// authorizeWallet checks that the principal of the request may access the wallet,
+// writing the error response when it may not
+func (h *Wallet) authorizeWallet(w http.ResponseWriter, r *http.Request, walletID string) bool {
+	err := h.walletService.AuthorizeWallet(r.Context(), auth.FromContext(r.Context()), walletID)
+	if err != nil {
+		if errors.Is(err, services.ErrWalletNotAllowed) {
+			h.writeProblem(w, r, http.StatusForbidden, problem.AccessDenied, "Caller is not allowed to access this wallet")
+			return false
+		}
+		h.writeInternalError(w, r, "Failed to authorize wallet", err)
+		return false
+	}
+
+	return true
+}
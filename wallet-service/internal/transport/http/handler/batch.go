@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"wallet-service/internal/auth"
+	"wallet-service/internal/models"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/services"
+	"wallet-service/internal/validation"
+)
+
+// Maximum number of operations of a batch
+const maxBatchSize = 1000
+
+// @Summary Create a batch of wallet operations
+// @Description Validates up to 1000 operations, creates them with a single insert and queues them with a single Kafka write.
+// @Description Every operation is validated like POST /wallet and gets its own result in request order; idempotencyKey
+// @Description of an operation deduplicates it like the Idempotency-Key header of POST /wallet.
+// @Description With atomic set no operation is created when any of them fails validation, and the response is 422.
+// @Description Atomicity covers validation only: an operation that was created but could not be queued is reported as failed.
+// @Tags operations
+// @Accept json
+// @Produce json
+// @Param batch body models.BatchOperationRequest true "Batch Request"
+// @Success 202 {object} models.BatchOperationResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 422 {object} models.BatchOperationResponse
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/operations:batch [post]
+// @Router /v2/operations:batch [post]
+func (h *Wallet) createOperations(w http.ResponseWriter, r *http.Request) {
+	var req models.BatchOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if len(req.Operations) == 0 {
+		h.writeInvalid(w, r, validation.Field("operations", "required", "Operations must not be empty"))
+		return
+	}
+	if len(req.Operations) > maxBatchSize {
+		h.writeInvalid(w, r, validation.Field("operations", "max", fmt.Sprintf("A batch holds at most %d operations", maxBatchSize)))
+		return
+	}
+
+	results := make([]models.BatchOperationResult, len(req.Operations))
+
+	// Operations that fail validation on their own never reach the service
+	var (
+		valid   []models.WalletOperationRequest
+		indexes []int // request index of each valid operation
+	)
+	allowed := make(map[string]error) // authorization of each wallet of the batch
+	limited := make(map[string]error) // rate limit of each wallet, a batch takes one token per wallet
+	for i, operation := range req.Operations {
+		results[i].Index = i
+		if violations := validation.Operation(h.validate, operation); violations != nil {
+			rejectResult(&results[i], problem.Validation(violations))
+			continue
+		}
+
+		authErr, checked := allowed[operation.WalletID]
+		if !checked {
+			authErr = h.walletService.AuthorizeWallet(r.Context(), auth.FromContext(r.Context()), operation.WalletID)
+			allowed[operation.WalletID] = authErr
+		}
+		if authErr != nil {
+			if errors.Is(authErr, services.ErrWalletNotAllowed) {
+				rejectResult(&results[i], problem.New(http.StatusForbidden, problem.AccessDenied, "Caller is not allowed to access this wallet"))
+			} else {
+				rejectResult(&results[i], problem.Internal("Failed to authorize wallet", authErr))
+			}
+			continue
+		}
+
+		limitErr, checked := limited[operation.WalletID]
+		if !checked {
+			_, limitErr = h.walletService.LimitWallet(r.Context(), operation.WalletID)
+			limited[operation.WalletID] = limitErr
+		}
+		if limitErr != nil {
+			switch {
+			case errors.Is(limitErr, services.ErrRateLimited):
+				rejectResult(&results[i], problem.New(http.StatusTooManyRequests, problem.RateLimited, "Rate limit of the wallet exceeded"))
+			case errors.Is(limitErr, services.ErrRateLimiterUnavailable):
+				rejectResult(&results[i], problem.New(http.StatusServiceUnavailable, problem.ServiceUnavailable, "Rate limiter unavailable"))
+			default:
+				rejectResult(&results[i], problem.Internal("Failed to check rate limit", limitErr))
+			}
+			continue
+		}
+
+		valid = append(valid, operation)
+		indexes = append(indexes, i)
+	}
+
+	rejected := len(valid) < len(req.Operations)
+	if req.Atomic && rejected {
+		valid = nil
+	}
+
+	var created []services.OperationResult
+	if len(valid) > 0 {
+		var err error
+		created, err = h.walletService.CreateOperations(r.Context(), valid, req.Atomic)
+		if err != nil {
+			h.writeInternalError(w, r, "Failed to create operations", err)
+			return
+		}
+	}
+
+	for j, i := range indexes {
+		result := &results[i]
+		if created == nil {
+			rejectResult(result, batchRejected())
+			continue
+		}
+
+		result.OperationID = created[j].OperationID
+		result.Replayed = created[j].Replayed
+
+		switch err := created[j].Err; {
+		case err == nil:
+			result.Status = models.OperationStatusAccepted
+		case errors.Is(err, services.ErrBatchRejected):
+			rejectResult(result, batchRejected())
+			rejected = true
+		case result.OperationID != "":
+			// Created, but Kafka did not take it: the operation is FAILED
+			rejectResult(result, problem.Internal("Failed to queue operation "+result.OperationID, err))
+			result.Status = models.OperationStatusUnqueued
+		default:
+			rejectResult(result, operationProblem(err))
+			rejected = true
+		}
+	}
+
+	response := models.BatchOperationResponse{Results: results}
+	for _, result := range results {
+		if result.Status == models.OperationStatusAccepted {
+			response.Accepted++
+		} else {
+			response.Rejected++
+		}
+	}
+
+	statusCode := http.StatusAccepted
+	if req.Atomic && rejected {
+		statusCode = http.StatusUnprocessableEntity
+	}
+
+	h.writeJSON(w, r, statusCode, response)
+}
+
+// rejectResult marks an operation of a batch as not created with the problem it would get on its own
+func rejectResult(result *models.BatchOperationResult, p *problem.Problem) {
+	result.Status = models.OperationStatusRejected
+	result.Code = p.Status
+	result.ErrorCode = string(p.Code)
+	result.Error = p.Detail
+	result.ErrorID = p.ErrorID
+	if len(p.Errors) > 0 {
+		result.Error = p.Errors.Error()
+	}
+}
+
+// batchRejected is the problem of the valid operations of an atomic batch that was rejected
+func batchRejected() *problem.Problem {
+	return problem.New(http.StatusUnprocessableEntity, problem.BatchRejected, "Not created: another operation of the batch failed")
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"wallet-service/internal/events"
+	"wallet-service/internal/models"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/validation"
+)
+
+// heartbeatInterval keeps idle connections open through proxies
+const heartbeatInterval = 15 * time.Second
+
+// @Summary Stream wallet events
+// @Description Server-Sent Events stream of the changes the operation worker commits for the wallet.
+// @Description Event "operation" carries the new status of an operation of the wallet (transfers appear in both wallets),
+// @Description event "balance" the balance of the wallet after the commit. The data is a models.WalletEvent.
+// @Description Every event has an id; a reconnecting client sends the last one in Last-Event-ID to receive what it missed.
+// @Description The last 1000 events of a wallet are kept for 24 hours.
+// @Tags wallets
+// @Produce text/event-stream
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {object} models.WalletEvent
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/events [get]
+// @Router /v2/wallets/{walletId}/events [get]
+func (h *Wallet) streamEvents(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID != "" && !events.ValidID(lastEventID) {
+		h.writeInvalid(w, r, validation.Field("Last-Event-ID", "event_id", "Invalid Last-Event-ID"))
+		return
+	}
+
+	ctx := r.Context()
+	missed, live, cancel, err := h.walletService.SubscribeWalletEvents(ctx, walletID, lastEventID)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+			return
+		}
+		h.writeInternalError(w, r, "Failed to subscribe to wallet events", err)
+		return
+	}
+	defer cancel()
+
+	// The stream outlives the write timeout of the server
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.writeInternalError(w, r, "Streaming is not supported", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range missed {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+		lastEventID = event.ID
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-live:
+			if !ok {
+				// Dropped for falling behind, the client resumes with Last-Event-ID
+				return
+			}
+			// Skip events already sent from the stream
+			if lastEventID != "" && !events.After(event.ID, lastEventID) {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			lastEventID = event.ID
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes a wallet event in the SSE format
+func writeEvent(w http.ResponseWriter, event models.WalletEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
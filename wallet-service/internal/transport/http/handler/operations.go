@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"wallet-service/internal/auth"
+	"wallet-service/internal/models"
+	"wallet-service/internal/pagination"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/validation"
+)
+
+// @Summary List the operations of a wallet
+// @Description Lists the operations of a wallet, including transfers to it, newest first.
+// @Description status and type are repeatable or comma-separated; amounts are in minor units and inclusive;
+// @Description from is inclusive and to exclusive (RFC 3339). Pass nextCursor of a page as cursor to get the next one.
+// @Tags operations
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param status query []string false "SCHEDULED, PENDING, PROCESSED, FAILED, CANCELLED" collectionFormat(multi)
+// @Param type query []string false "DEPOSIT, WITHDRAW, TRANSFER, HOLD, CAPTURE, RELEASE, REVERSAL" collectionFormat(multi)
+// @Param minAmount query int false "Minimum amount"
+// @Param maxAmount query int false "Maximum amount"
+// @Param from query string false "Created at or after (RFC 3339)"
+// @Param to query string false "Created before (RFC 3339)"
+// @Param limit query int false "Page size, 1-200, defaults to 50"
+// @Param cursor query string false "Cursor of the page"
+// @Success 200 {object} models.OperationListResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/operations [get]
+// @Router /v2/wallets/{walletId}/operations [get]
+func (h *Wallet) listOperations(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+
+	query := r.URL.Query()
+
+	filter, violations := h.operationFilter(query)
+	if violations != nil {
+		h.writeInvalid(w, r, violations)
+		return
+	}
+
+	operations, err := h.walletService.ListWalletOperations(r.Context(), walletID, filter, query.Get("cursor"))
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+			return
+		}
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			h.writeInvalid(w, r, validation.Field("cursor", "cursor", "Invalid cursor"))
+			return
+		}
+		h.writeInternalError(w, r, "Failed to list operations", err)
+		return
+	}
+
+	h.writeList(w, r, operations, operations.Operations, operations.NextCursor)
+}
+
+// operationFilter parses the filters of an operation list and returns the
+// violation of the first invalid one
+func (h *Wallet) operationFilter(query url.Values) (models.OperationFilter, validation.Errors) {
+	var filter models.OperationFilter
+
+	for _, status := range splitValues(query["status"]) {
+		if err := h.validate.Var(status, "oneof=SCHEDULED PENDING PROCESSED FAILED CANCELLED"); err != nil {
+			return filter, validation.Field("status", "oneof", fmt.Sprintf("Invalid status %q", status))
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	for _, operationType := range splitValues(query["type"]) {
+		if err := h.validate.Var(operationType, "oneof=DEPOSIT WITHDRAW TRANSFER HOLD CAPTURE RELEASE REVERSAL"); err != nil {
+			return filter, validation.Field("type", "oneof", fmt.Sprintf("Invalid type %q", operationType))
+		}
+		filter.OperationTypes = append(filter.OperationTypes, operationType)
+	}
+
+	var ok bool
+	if filter.MinAmount, ok = parseAmount(query.Get("minAmount")); !ok {
+		return filter, validation.Field("minAmount", "gte", "minAmount must be a non-negative integer")
+	}
+	if filter.MaxAmount, ok = parseAmount(query.Get("maxAmount")); !ok {
+		return filter, validation.Field("maxAmount", "gte", "maxAmount must be a non-negative integer")
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return filter, validation.Field("minAmount", "ltefield", "minAmount must not exceed maxAmount")
+	}
+
+	if filter.CreatedFrom, ok = parseTime(query.Get("from")); !ok {
+		return filter, validation.Field("from", "rfc3339", "from must be an RFC 3339 time")
+	}
+	if filter.CreatedTo, ok = parseTime(query.Get("to")); !ok {
+		return filter, validation.Field("to", "rfc3339", "to must be an RFC 3339 time")
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return filter, validation.Field("from", "ltfield", "from must be before to")
+	}
+
+	if filter.Limit, ok = parseLimit(query.Get("limit")); !ok {
+		return filter, validation.Field("limit", "range", fmt.Sprintf("Limit must be between 1 and %d", pagination.MaxLimit))
+	}
+
+	return filter, nil
+}
+
+// parseAmount parses an optional non-negative amount of a query
+func parseAmount(value string) (*int64, bool) {
+	if value == "" {
+		return nil, true
+	}
+
+	amount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || amount < 0 {
+		return nil, false
+	}
+
+	return &amount, true
+}
+
+// parseTime parses an optional RFC 3339 time of a query
+func parseTime(value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, false
+	}
+
+	return &t, true
+}
+
+// splitValues returns the values of a repeatable query parameter that may also be comma-separated
+func splitValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// @Summary Search operations by external reference
+// @Description Lists the operations created with an externalRef, newest first, optionally only those of one wallet
+// @Description (including transfers to it). Pass nextCursor of a page as cursor to get the next one.
+// @Tags operations
+// @Accept json
+// @Produce json
+// @Param externalRef query string true "External reference"
+// @Param walletId query string false "Wallet ID (UUIDv4)"
+// @Param limit query int false "Page size, 1-200, defaults to 50"
+// @Param cursor query string false "Cursor of the page"
+// @Success 200 {object} models.OperationListResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/operations [get]
+// @Router /v2/operations [get]
+func (h *Wallet) searchOperations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := models.OperationFilter{
+		ExternalRef: query.Get("externalRef"),
+		WalletID:    query.Get("walletId"),
+	}
+	if err := h.validate.Var(filter.ExternalRef, "required,max=100"); err != nil {
+		h.writeInvalid(w, r, validation.Field("externalRef", "max", "ExternalRef is required and must be at most 100 characters"))
+		return
+	}
+	if err := h.validate.Var(filter.WalletID, "omitempty,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+
+	// A key restricted to some wallets searches one of them at a time
+	if filter.WalletID == "" && auth.FromContext(r.Context()).Restricted() {
+		h.writeProblem(w, r, http.StatusForbidden, problem.AccessDenied, "API key is restricted to its wallets, walletId is required")
+		return
+	}
+	if filter.WalletID != "" && !h.authorizeWallet(w, r, filter.WalletID) {
+		return
+	}
+
+	limit, ok := parseLimit(query.Get("limit"))
+	if !ok {
+		h.writeInvalid(w, r, validation.Field("limit", "range", fmt.Sprintf("Limit must be between 1 and %d", pagination.MaxLimit)))
+		return
+	}
+	filter.Limit = limit
+
+	operations, err := h.walletService.ListOperations(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			h.writeInvalid(w, r, validation.Field("cursor", "cursor", "Invalid cursor"))
+			return
+		}
+		h.writeInternalError(w, r, "Failed to list operations", err)
+		return
+	}
+
+	h.writeList(w, r, operations, operations.Operations, operations.NextCursor)
+}
+
+// parseLimit parses the page size of a list request; an empty value means the default
+func parseLimit(value string) (int, bool) {
+	if value == "" {
+		return 0, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > pagination.MaxLimit {
+		return 0, false
+	}
+
+	return limit, true
+}
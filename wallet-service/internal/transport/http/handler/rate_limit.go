@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"wallet-service/internal/auth"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/ratelimit"
+	"wallet-service/internal/services"
+)
+
+// RateLimit wraps the mux of the handlers inside Authenticate: every authenticated
+// request takes a token from the bucket of its caller. Requests naming a wallet
+// take one from the bucket of the wallet as well, see limitWallet.
+func (h *Wallet) RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := auth.FromContext(r.Context())
+		if principal == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		result, err := h.walletService.LimitClient(r.Context(), principal)
+		if !h.writeRateLimit(w, r, result, err) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitWallet takes a token from the bucket of the wallet, writing the error
+// response when the wallet is over its limit
+func (h *Wallet) limitWallet(w http.ResponseWriter, r *http.Request, walletID string) bool {
+	result, err := h.walletService.LimitWallet(r.Context(), walletID)
+	return h.writeRateLimit(w, r, result, err)
+}
+
+// writeRateLimit reports the result of a bucket in the headers and writes the error
+// response when the request may not go on
+func (h *Wallet) writeRateLimit(w http.ResponseWriter, r *http.Request, result *ratelimit.Result, err error) bool {
+	if result != nil {
+		ratelimit.SetHeaders(w.Header(), *result)
+	}
+
+	if err != nil {
+		if errors.Is(err, services.ErrRateLimited) {
+			h.writeProblem(w, r, http.StatusTooManyRequests, problem.RateLimited, fmt.Sprintf("Rate limit exceeded, retry after %d seconds", int(result.RetryAfter.Seconds())))
+			return false
+		}
+		if errors.Is(err, services.ErrRateLimiterUnavailable) {
+			h.writeProblem(w, r, http.StatusServiceUnavailable, problem.ServiceUnavailable, "Rate limiter unavailable")
+			return false
+		}
+		h.writeInternalError(w, r, "Failed to check rate limit", err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+
+	"wallet-service/internal/auth"
+	"wallet-service/internal/config"
+	"wallet-service/internal/ratelimit"
+	"wallet-service/internal/repositories/redisrepo"
+)
+
+// fakeRedis answers the rate limit script with a full bucket and counts the tokens
+// taken from each bucket; every other command fails
+type fakeRedis struct {
+	listener net.Listener
+	tokens   map[string]*atomic.Int64
+}
+
+func newFakeRedis(t *testing.T, keys ...string) *fakeRedis {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	f := &fakeRedis{listener: listener, tokens: make(map[string]*atomic.Int64)}
+	for _, key := range keys {
+		f.tokens[key] = new(atomic.Int64)
+	}
+	go f.serve()
+	return f
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		// EVALSHA sha numkeys key args... or EVAL script numkeys key args...
+		command := strings.ToUpper(args[0])
+		if (command == "EVALSHA" || command == "EVAL") && len(args) > 3 {
+			if tokens, ok := f.tokens[args[3]]; ok {
+				tokens.Add(1)
+			}
+			fmt.Fprint(conn, "*2\r\n:1\r\n$2\r\n10\r\n")
+			continue
+		}
+		fmt.Fprintf(conn, "-ERR unsupported command %s\r\n", command)
+	}
+}
+
+// readCommand reads a command sent as a RESP array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		if _, err := reader.ReadString('\n'); err != nil { // $<length>
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+	return args, nil
+}
+
+func TestWalletOperationTakesOneWalletToken(t *testing.T) {
+	walletKey := "ratelimit:wallet:" + testWalletID
+	fake := newFakeRedis(t, walletKey)
+
+	client := redis.NewClient(&redis.Options{Addr: fake.listener.Addr().String()})
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{WalletRate: 100, WalletBurst: 100}}
+	mux := newTestMux(t, cfg, redisrepo.NewWalletRepository(client))
+	subject := (&auth.Claims{Subject: "user-1", WalletIDs: []string{testWalletID}}).Principal("admin")
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"v1", "/api/v1/wallet", `{"walletId":"` + testWalletID + `","operationType":"WITHDRAW","amount":100}`},
+		{"v2", "/api/v2/wallets/" + testWalletID + "/operations", `{"operationType":"WITHDRAW","amount":100}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := fake.tokens[walletKey]
+			tokens.Store(0)
+
+			w, _ := serve(mux, subject, http.MethodPost, tt.path, tt.body)
+			if w.Header().Get(ratelimit.HeaderLimit) == "" {
+				t.Errorf("response lacks the %s header", ratelimit.HeaderLimit)
+			}
+			if got := tokens.Load(); got != 1 {
+				t.Errorf("took %d wallet tokens, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"wallet-service/internal/currency"
+	"wallet-service/internal/models"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/recurrence"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/services"
+	"wallet-service/internal/validation"
+)
+
+// @Summary Create a standing order
+// @Description Creates a recurring DEPOSIT, WITHDRAW or TRANSFER. schedule is "@every <duration>" (at least 1m),
+// @Description "@hourly", "@daily", "@weekly", "@monthly", "@yearly" or a cron expression "minute hour day-of-month month day-of-week" in UTC.
+// @Description Every occurrence becomes a regular operation processed by the worker; the order completes after endAt or maxOccurrences.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param order body models.StandingOrderRequest true "Standing Order Request"
+// @Success 201 {object} models.StandingOrderResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/standing-orders [post]
+// @Router /v2/wallets/{walletId}/standing-orders [post]
+func (h *Wallet) createStandingOrder(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+
+	var req models.StandingOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeInvalid(w, r, validation.Fields(err, ""))
+		return
+	}
+
+	if violations := validateStandingOrderFields(walletID, req); violations != nil {
+		h.writeInvalid(w, r, violations)
+		return
+	}
+
+	if req.Currency != "" {
+		if _, err := currency.Lookup(req.Currency); err != nil {
+			h.writeInvalid(w, r, validation.Field("currency", "currency", "Unsupported currency"))
+			return
+		}
+	}
+
+	ctx := r.Context()
+	order, err := h.walletService.CreateStandingOrder(ctx, walletID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrOperationNotAllowed) {
+			h.writeProblem(w, r, http.StatusForbidden, problem.OperationNotAllowed, "Caller is not allowed to create operations of this type")
+			return
+		}
+		if errors.Is(err, recurrence.ErrInvalidRule) {
+			h.writeInvalid(w, r, validation.Field("schedule", "schedule", fmt.Sprintf("Invalid schedule: %v", err)))
+			return
+		}
+		if errors.Is(err, services.ErrStandingOrderNoOccurrences) {
+			h.writeInvalid(w, r, validation.Field("endAt", "occurrences", "Schedule has no occurrences before endAt"))
+			return
+		}
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+			return
+		}
+		if errors.Is(err, services.ErrDestinationWalletNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.DestinationWalletNotFound, "Destination wallet not found")
+			return
+		}
+		if errors.Is(err, services.ErrWalletFrozen) {
+			h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.WalletFrozen, "Wallet is frozen")
+			return
+		}
+		if errors.Is(err, services.ErrWalletClosed) {
+			h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.WalletClosed, "Wallet is closed")
+			return
+		}
+		if errors.Is(err, services.ErrDestinationWalletInactive) {
+			h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.DestinationWalletInactive, "Destination wallet is not active")
+			return
+		}
+		if errors.Is(err, services.ErrCurrencyMismatch) {
+			h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.CurrencyMismatch, "Currency does not match wallet currency")
+			return
+		}
+		h.writeInternalError(w, r, "Failed to create standing order", err)
+		return
+	}
+
+	h.writeCreated(w, r, http.StatusCreated, "/wallets/"+order.WalletID+"/standing-orders/"+order.StandingOrderID, order)
+}
+
+// validateStandingOrderFields checks the fields whose presence depends on the
+// operation type and returns the first violation
+func validateStandingOrderFields(walletID string, req models.StandingOrderRequest) validation.Errors {
+	if req.OperationType == models.OperationTypeTransfer {
+		if req.DestinationWalletID == "" {
+			return validation.Field("destinationWalletId", "required", "DestinationWalletID is required for TRANSFER")
+		}
+		if req.DestinationWalletID == walletID {
+			return validation.Field("destinationWalletId", "nefield", "DestinationWalletID must differ from WalletID")
+		}
+	} else if req.DestinationWalletID != "" {
+		return validation.Field("destinationWalletId", "transfer_only", "DestinationWalletID is only allowed for TRANSFER")
+	}
+
+	if req.StartAt != nil && req.EndAt != nil && !req.EndAt.After(*req.StartAt) {
+		return validation.Field("endAt", "gtfield", "EndAt must be after StartAt")
+	}
+
+	return nil
+}
+
+// @Summary Get a standing order
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param orderId path string true "Standing order ID (UUIDv4)"
+// @Success 200 {object} models.StandingOrderResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/standing-orders/{orderId} [get]
+// @Router /v2/wallets/{walletId}/standing-orders/{orderId} [get]
+func (h *Wallet) getStandingOrder(w http.ResponseWriter, r *http.Request) {
+	walletID, orderID, ok := h.standingOrderPath(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.walletService.GetStandingOrder(r.Context(), walletID, orderID)
+	if err != nil {
+		h.writeStandingOrderError(w, r, err, "Failed to get standing order")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, order)
+}
+
+// @Summary Pause a standing order
+// @Description Stops an ACTIVE standing order from firing until it is resumed.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param orderId path string true "Standing order ID (UUIDv4)"
+// @Success 200 {object} models.StandingOrderResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/standing-orders/{orderId}/pause [post]
+// @Router /v2/wallets/{walletId}/standing-orders/{orderId}/pause [post]
+func (h *Wallet) pauseStandingOrder(w http.ResponseWriter, r *http.Request) {
+	walletID, orderID, ok := h.standingOrderPath(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.walletService.PauseStandingOrder(r.Context(), walletID, orderID)
+	if err != nil {
+		h.writeStandingOrderError(w, r, err, "Failed to pause standing order")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, order)
+}
+
+// @Summary Resume a standing order
+// @Description Reactivates a PAUSED standing order. Occurrences that fell due while it was paused are skipped.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param orderId path string true "Standing order ID (UUIDv4)"
+// @Success 200 {object} models.StandingOrderResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/standing-orders/{orderId}/resume [post]
+// @Router /v2/wallets/{walletId}/standing-orders/{orderId}/resume [post]
+func (h *Wallet) resumeStandingOrder(w http.ResponseWriter, r *http.Request) {
+	walletID, orderID, ok := h.standingOrderPath(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.walletService.ResumeStandingOrder(r.Context(), walletID, orderID)
+	if err != nil {
+		h.writeStandingOrderError(w, r, err, "Failed to resume standing order")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, order)
+}
+
+// @Summary Delete a standing order
+// @Description Stops a standing order for good. The order and its execution history remain readable with status DELETED.
+// @Tags standing-orders
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param orderId path string true "Standing order ID (UUIDv4)"
+// @Success 204
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/standing-orders/{orderId} [delete]
+// @Router /v2/wallets/{walletId}/standing-orders/{orderId} [delete]
+func (h *Wallet) deleteStandingOrder(w http.ResponseWriter, r *http.Request) {
+	walletID, orderID, ok := h.standingOrderPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.walletService.DeleteStandingOrder(r.Context(), walletID, orderID); err != nil {
+		h.writeStandingOrderError(w, r, err, "Failed to delete standing order")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Get the execution history of a standing order
+// @Description Lists the operations created by a standing order, newest first.
+// @Tags standing-orders
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param orderId path string true "Standing order ID (UUIDv4)"
+// @Success 200 {object} models.StandingOrderHistoryResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/standing-orders/{orderId}/operations [get]
+// @Router /v2/wallets/{walletId}/standing-orders/{orderId}/operations [get]
+func (h *Wallet) getStandingOrderHistory(w http.ResponseWriter, r *http.Request) {
+	walletID, orderID, ok := h.standingOrderPath(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := h.walletService.GetStandingOrderHistory(r.Context(), walletID, orderID)
+	if err != nil {
+		h.writeStandingOrderError(w, r, err, "Failed to get standing order history")
+		return
+	}
+
+	h.writeList(w, r, history, history.Executions, "")
+}
+
+// standingOrderPath validates the wallet and standing order IDs of the path
+func (h *Wallet) standingOrderPath(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	walletID := r.PathValue("walletId")
+	orderID := r.PathValue("orderId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return "", "", false
+	}
+	if err := h.validate.Var(orderID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("orderId", "uuid4", "Invalid standing order ID format"))
+		return "", "", false
+	}
+
+	return walletID, orderID, true
+}
+
+// writeStandingOrderError maps the errors of an existing standing order to responses
+func (h *Wallet) writeStandingOrderError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, postgresrepo.ErrStandingOrderNotFound) {
+		h.writeProblem(w, r, http.StatusNotFound, problem.StandingOrderNotFound, "Standing order not found")
+		return
+	}
+	if errors.Is(err, services.ErrStandingOrderNotActive) {
+		h.writeProblem(w, r, http.StatusConflict, problem.StandingOrderNotActive, "Only active standing orders can be paused")
+		return
+	}
+	if errors.Is(err, services.ErrStandingOrderNotPaused) {
+		h.writeProblem(w, r, http.StatusConflict, problem.StandingOrderNotPaused, "Only paused standing orders can be resumed")
+		return
+	}
+	h.writeInternalError(w, r, message, err)
+}
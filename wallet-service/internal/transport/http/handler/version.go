@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"wallet-service/internal/envelope"
+)
+
+// Prefixes of the versions of the REST API. v2 serves the routes of v1 with the
+// bodies in an envelope, and creates operations under their wallet.
+const (
+	apiV1 = "/api/v1"
+	apiV2 = "/api/v2"
+)
+
+// handle registers the handler of a route under both versions
+func (h *Wallet) handle(mux *http.ServeMux, method, path, scope string, next http.HandlerFunc) {
+	mux.HandleFunc(method+" "+apiV1+path, h.require(scope, next))
+	mux.HandleFunc(method+" "+apiV2+path, h.require(scope, next))
+}
+
+// Deprecate wraps the handlers like Authenticate: v1 responses, errors included, carry
+// the Deprecation (RFC 9745) and Sunset (RFC 8594) headers of the configured dates
+func (h *Wallet) Deprecate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, apiV1+"/") {
+			if !h.api.V1Deprecation.IsZero() {
+				w.Header().Set("Deprecation", fmt.Sprintf("@%d", h.api.V1Deprecation.Unix()))
+			}
+			if !h.api.V1Sunset.IsZero() {
+				w.Header().Set("Sunset", h.api.V1Sunset.UTC().Format(http.TimeFormat))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiPrefix returns the prefix of the version of the request
+func apiPrefix(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, apiV2+"/") {
+		return apiV2
+	}
+	return apiV1
+}
+
+// writeJSON writes the body of a response: as it is under v1, in an envelope under v2
+func (h *Wallet) writeJSON(w http.ResponseWriter, r *http.Request, status int, body any) {
+	h.write(w, r, status, body, body, envelope.Links{Self: r.URL.RequestURI()})
+}
+
+// writeCreated writes the body of a response creating the resource at path, relative
+// to the version of the request, and points the Location header at it
+func (h *Wallet) writeCreated(w http.ResponseWriter, r *http.Request, status int, path string, body any) {
+	location := apiPrefix(r) + path
+	w.Header().Set("Location", location)
+	h.write(w, r, status, body, body, envelope.Links{Self: location})
+}
+
+// writeList writes a page of a list: body as it is under v1, its items with the link
+// of the next page under v2
+func (h *Wallet) writeList(w http.ResponseWriter, r *http.Request, body, items any, nextCursor string) {
+	h.write(w, r, http.StatusOK, body, items, envelope.Links{
+		Self: r.URL.RequestURI(),
+		Next: envelope.NextLink(r.URL, nextCursor),
+	})
+}
+
+func (h *Wallet) write(w http.ResponseWriter, r *http.Request, status int, v1, v2 any, links envelope.Links) {
+	var body any = v1
+	if apiPrefix(r) == apiV2 {
+		body = envelope.New(v2, links)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxWait caps how long a request may wait for the result of an operation
+	maxWait = 30 * time.Second
+	// waitWriteSlack is the time left to write the response after the wait
+	waitWriteSlack = 5 * time.Second
+)
+
+// parseWait reads how long the request should wait for the result of an operation
+// from ?wait=5s or, failing that, from the RFC 7240 preference "Prefer: wait=5".
+// Longer waits are capped at maxWait. It returns false when ?wait is malformed.
+func parseWait(w http.ResponseWriter, r *http.Request) (time.Duration, bool) {
+	if value := r.URL.Query().Get("wait"); value != "" {
+		wait, err := time.ParseDuration(value)
+		if err != nil || wait < 0 {
+			return 0, false
+		}
+		return min(wait, maxWait), true
+	}
+
+	for _, preference := range strings.Split(r.Header.Get("Prefer"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(preference), "=")
+		if !strings.EqualFold(name, "wait") {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds <= 0 {
+			// Preferences that cannot be honored are ignored
+			return 0, true
+		}
+		wait := min(time.Duration(seconds)*time.Second, maxWait)
+		w.Header().Set("Preference-Applied", "wait="+strconv.Itoa(int(wait/time.Second)))
+		return wait, true
+	}
+
+	return 0, true
+}
+
+// extendWriteDeadline lets the response be written after waiting beyond the write
+// timeout of the server
+func extendWriteDeadline(w http.ResponseWriter, wait time.Duration) {
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + waitWriteSlack))
+}
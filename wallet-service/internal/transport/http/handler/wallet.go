@@ -4,10 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"wallet-service/internal/auth"
+	"wallet-service/internal/config"
 	"wallet-service/internal/models"
+	"wallet-service/internal/pagination"
+	"wallet-service/internal/problem"
 	"wallet-service/internal/repositories/postgresrepo"
 	"wallet-service/internal/services"
+	"wallet-service/internal/validation"
 
 	_ "wallet-service/docs"
 
@@ -18,18 +25,52 @@ import (
 type Wallet struct {
 	walletService *services.WalletService
 	validate      *validator.Validate
+	api           config.APIConfig
 }
 
-func NewWallet(mux *http.ServeMux, walletService *services.WalletService) *Wallet {
+func NewWallet(mux *http.ServeMux, walletService *services.WalletService, api config.APIConfig) *Wallet {
 	h := &Wallet{
 		walletService: walletService,
-		validate:      validator.New(),
+		validate:      validation.New(),
+		api:           api,
 	}
 
-	mux.HandleFunc("POST /api/v1/wallets", h.createWallet)
-	mux.HandleFunc("GET /api/v1/wallets/{walletId}", h.getWallet)
-	mux.HandleFunc("POST /api/v1/wallet", h.createOperation)
-	mux.HandleFunc("GET /api/v1/wallets/{walletId}/operations/{operationId}", h.getOperation)
+	h.handle(mux, http.MethodPost, "/wallets", auth.ScopeWalletsWrite, h.createWallet)
+	h.handle(mux, http.MethodGet, "/wallets", auth.ScopeWalletsRead, h.listWallets)
+	h.handle(mux, http.MethodGet, "/wallets/{walletId}", auth.ScopeWalletsRead, h.getWallet)
+	h.handle(mux, http.MethodPut, "/wallets/{walletId}/credit-limit", auth.ScopeAdmin, h.setCreditLimit)
+	h.handle(mux, http.MethodGet, "/wallets/{walletId}/limits", auth.ScopeWalletsRead, h.getLimits)
+	h.handle(mux, http.MethodPut, "/wallets/{walletId}/limits", auth.ScopeAdmin, h.setLimits)
+	mux.HandleFunc("POST "+apiV1+"/wallet", h.require(auth.ScopeOperationsWrite, h.createOperation))
+	mux.HandleFunc("POST "+apiV2+"/wallets/{walletId}/operations", h.require(auth.ScopeOperationsWrite, h.createWalletOperation))
+	h.handle(mux, http.MethodPost, "/operations:batch", auth.ScopeOperationsWrite, h.createOperations)
+	h.handle(mux, http.MethodGet, "/operations", auth.ScopeOperationsRead, h.searchOperations)
+	h.handle(mux, http.MethodGet, "/wallets/{walletId}/operations", auth.ScopeOperationsRead, h.listOperations)
+	h.handle(mux, http.MethodGet, "/wallets/{walletId}/events", auth.ScopeWalletsRead, h.streamEvents)
+	h.handle(mux, http.MethodGet, "/wallets/{walletId}/operations/{operationId}", auth.ScopeOperationsRead, h.getOperation)
+	h.handle(mux, http.MethodPost, "/wallets/{walletId}/operations/{operationId}/reversals", auth.ScopeOperationsWrite, h.createReversal)
+	h.handle(mux, http.MethodPost, "/wallets/{walletId}/operations/{operationId}/cancel", auth.ScopeOperationsWrite, h.cancelOperation)
+	h.handle(mux, http.MethodPost, "/wallets/{walletId}/standing-orders", auth.ScopeOperationsWrite, h.createStandingOrder)
+	h.handle(mux, http.MethodGet, "/wallets/{walletId}/standing-orders/{orderId}", auth.ScopeOperationsRead, h.getStandingOrder)
+	h.handle(mux, http.MethodDelete, "/wallets/{walletId}/standing-orders/{orderId}", auth.ScopeOperationsWrite, h.deleteStandingOrder)
+	h.handle(mux, http.MethodPost, "/wallets/{walletId}/standing-orders/{orderId}/pause", auth.ScopeOperationsWrite, h.pauseStandingOrder)
+	h.handle(mux, http.MethodPost, "/wallets/{walletId}/standing-orders/{orderId}/resume", auth.ScopeOperationsWrite, h.resumeStandingOrder)
+	h.handle(mux, http.MethodGet, "/wallets/{walletId}/standing-orders/{orderId}/operations", auth.ScopeOperationsRead, h.getStandingOrderHistory)
+	h.handle(mux, http.MethodPost, "/admin/wallets/{walletId}/freeze", auth.ScopeAdmin, h.freezeWallet)
+	h.handle(mux, http.MethodPost, "/admin/wallets/{walletId}/unfreeze", auth.ScopeAdmin, h.unfreezeWallet)
+	h.handle(mux, http.MethodPost, "/admin/wallets/{walletId}/close", auth.ScopeAdmin, h.closeWallet)
+	h.handle(mux, http.MethodPost, "/webhooks", auth.ScopeWebhooks, h.createWebhook)
+	h.handle(mux, http.MethodGet, "/webhooks", auth.ScopeWebhooks, h.listWebhooks)
+	h.handle(mux, http.MethodGet, "/webhooks/{webhookId}", auth.ScopeWebhooks, h.getWebhook)
+	h.handle(mux, http.MethodDelete, "/webhooks/{webhookId}", auth.ScopeWebhooks, h.deleteWebhook)
+	h.handle(mux, http.MethodGet, "/admin/webhooks/dead-letters", auth.ScopeAdmin, h.listDeadLetters)
+	h.handle(mux, http.MethodPost, "/admin/webhooks/dead-letters/{deliveryId}/replay", auth.ScopeAdmin, h.replayDeadLetter)
+	h.handle(mux, http.MethodPost, "/admin/webhooks/{webhookId}/replay", auth.ScopeAdmin, h.replayDeadLetters)
+	h.handle(mux, http.MethodPost, "/admin/api-keys", auth.ScopeAdmin, h.createAPIKey)
+	h.handle(mux, http.MethodGet, "/admin/api-keys", auth.ScopeAdmin, h.listAPIKeys)
+	h.handle(mux, http.MethodGet, "/admin/api-keys/{apiKeyId}", auth.ScopeAdmin, h.getAPIKey)
+	h.handle(mux, http.MethodPost, "/admin/api-keys/{apiKeyId}/rotate", auth.ScopeAdmin, h.rotateAPIKey)
+	h.handle(mux, http.MethodPost, "/admin/api-keys/{apiKeyId}/revoke", auth.ScopeAdmin, h.revokeAPIKey)
 
 	mux.Handle("/swagger/", httpSwagger.WrapHandler)
 
@@ -43,15 +84,18 @@ func NewWallet(mux *http.ServeMux, walletService *services.WalletService) *Walle
 // @Produce json
 // @Param walletId path string true "Wallet ID (UUIDv4)"
 // @Success 200 {object} models.WalletBalanceResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /wallets/{walletId} [get]
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId} [get]
+// @Router /v2/wallets/{walletId} [get]
 func (h *Wallet) getWallet(w http.ResponseWriter, r *http.Request) {
 	walletID := r.PathValue("walletId")
 
 	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
-		h.writeError(w, http.StatusBadRequest, "Invalid wallet ID format")
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
 		return
 	}
 
@@ -59,133 +103,437 @@ func (h *Wallet) getWallet(w http.ResponseWriter, r *http.Request) {
 	balanceResponse, err := h.walletService.GetWalletBalance(ctx, walletID)
 	if err != nil {
 		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
-			h.writeError(w, http.StatusNotFound, "Wallet not found")
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
 			return
 		}
-		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get wallet balance: %v", err))
+		h.writeInternalError(w, r, "Failed to get wallet balance", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(balanceResponse)
+	h.writeJSON(w, r, http.StatusOK, balanceResponse)
+}
+
+// @Summary Set wallet credit limit
+// @Description Sets how far below zero the wallet balance may go (in minor units, 0 disables overdraft).
+// @Description Withdrawals, transfers and holds are allowed while balance - held - amount >= -creditLimit.
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param limit body models.CreditLimitRequest true "Credit Limit Request"
+// @Success 200 {object} models.WalletBalanceResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/credit-limit [put]
+// @Router /v2/wallets/{walletId}/credit-limit [put]
+func (h *Wallet) setCreditLimit(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+
+	var req models.CreditLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeInvalid(w, r, validation.Fields(err, ""))
+		return
+	}
+
+	ctx := r.Context()
+	balanceResponse, err := h.walletService.SetCreditLimit(ctx, walletID, *req.CreditLimit)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+			return
+		}
+		h.writeInternalError(w, r, "Failed to set credit limit", err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, balanceResponse)
+}
+
+// @Summary Get wallet withdraw limits
+// @Description Returns the daily (rolling 24h) and monthly (rolling 30 days) withdraw limits of a wallet
+// @Description and how much of them is used. Withdrawals, captures and outgoing transfers count against the limits.
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Success 200 {object} models.WalletLimitsResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/limits [get]
+// @Router /v2/wallets/{walletId}/limits [get]
+func (h *Wallet) getLimits(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+
+	ctx := r.Context()
+	limitsResponse, err := h.walletService.GetWithdrawLimits(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+			return
+		}
+		h.writeInternalError(w, r, "Failed to get withdraw limits", err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, limitsResponse)
+}
+
+// @Summary Set wallet withdraw limits
+// @Description Replaces the withdraw limits of a wallet (in minor units).
+// @Description An omitted limit falls back to the global default, 0 disables the limit for this wallet.
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param limits body models.WithdrawLimitsRequest true "Withdraw Limits Request"
+// @Success 200 {object} models.WalletLimitsResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/limits [put]
+// @Router /v2/wallets/{walletId}/limits [put]
+func (h *Wallet) setLimits(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+
+	var req models.WithdrawLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeInvalid(w, r, validation.Fields(err, ""))
+		return
+	}
+
+	ctx := r.Context()
+	limitsResponse, err := h.walletService.SetWithdrawLimits(ctx, walletID, req)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+			return
+		}
+		h.writeInternalError(w, r, "Failed to set withdraw limits", err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, limitsResponse)
 }
 
 // @Summary Create a new wallet
-// @Description Creates a new wallet with an initial balance of 0
+// @Description Creates a new wallet with an initial balance of 0.
+// @Description The request body is optional; without it the wallet is created in USD.
+// @Description ownerId and externalRef identify the wallet in the calling system: creating a wallet again with
+// @Description the same pair returns the existing wallet with 200 instead of creating a duplicate.
 // @Tags wallets
 // @Accept json
 // @Produce json
+// @Param wallet body models.WalletCreateRequest false "Wallet Request"
 // @Success 201 {object} models.WalletCreateResponse
-// @Failure 500 {object} map[string]interface{}
-// @Router /wallets [post]
+// @Success 200 {object} models.WalletCreateResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets [post]
+// @Router /v2/wallets [post]
 func (h *Wallet) createWallet(w http.ResponseWriter, r *http.Request) {
+	var req models.WalletCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if violations := validation.Wallet(h.validate, req); violations != nil {
+		h.writeInvalid(w, r, violations)
+		return
+	}
+
+	if !auth.FromContext(r.Context()).AllowsOwner(req.OwnerID) {
+		h.writeProblem(w, r, http.StatusForbidden, problem.AccessDenied, "Caller is not allowed to create wallets of this owner")
+		return
+	}
+
 	ctx := r.Context()
 
-	wallet, err := h.walletService.CreateWallet(ctx)
+	response, err := h.walletService.CreateWallet(ctx, req)
 	if err != nil {
-		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create wallet: %v", err))
+		if errors.Is(err, services.ErrExternalRefConflict) {
+			h.writeProblem(w, r, http.StatusConflict, problem.ExternalRefConflict, "ExternalRef is already used by a wallet in another currency")
+			return
+		}
+		h.writeInternalError(w, r, "Failed to create wallet", err)
 		return
 	}
 
-	response := models.WalletCreateResponse{
-		WalletID: wallet.WalletID,
-		Balance:  wallet.Balance,
-		Status:   "created",
-		Message:  models.MessageWalletCreated,
+	statusCode := http.StatusCreated
+	if response.Status == "exists" {
+		statusCode = http.StatusOK
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+	h.writeCreated(w, r, statusCode, "/wallets/"+response.WalletID, response)
+}
 
+// @Summary List wallets
+// @Description Lists wallets in creation order, optionally of one owner and with the given labels.
+// @Description label is repeatable and all given labels must match: "key=value" matches the value, "key" only requires the label.
+// @Description Pass nextCursor of a page as cursor to get the next one.
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param ownerId query string false "Owner ID"
+// @Param label query []string false "Label filter, key=value or key" collectionFormat(multi)
+// @Param limit query int false "Page size, 1-200, defaults to 50"
+// @Param cursor query string false "Cursor of the page"
+// @Success 200 {object} models.WalletListResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets [get]
+// @Router /v2/wallets [get]
+func (h *Wallet) listWallets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := models.WalletFilter{OwnerID: query.Get("ownerId")}
+	if err := h.validate.Var(filter.OwnerID, "omitempty,max=100"); err != nil {
+		h.writeInvalid(w, r, validation.Field("ownerId", "max", "Invalid owner ID"))
+		return
+	}
+	if !auth.FromContext(r.Context()).AllowsOwner(filter.OwnerID) {
+		h.writeProblem(w, r, http.StatusForbidden, problem.AccessDenied, "API key is restricted to its owners, ownerId must be one of them")
+		return
+	}
+
+	for _, label := range query["label"] {
+		key, value, hasValue := strings.Cut(label, "=")
+		if key == "" {
+			h.writeInvalid(w, r, validation.Field("label", "label", "Invalid label filter"))
+			return
+		}
+		if !hasValue {
+			filter.LabelKeys = append(filter.LabelKeys, key)
+			continue
+		}
+		if filter.Labels == nil {
+			filter.Labels = make(map[string]string)
+		}
+		filter.Labels[key] = value
+	}
+
+	limit, ok := parseLimit(query.Get("limit"))
+	if !ok {
+		h.writeInvalid(w, r, validation.Field("limit", "range", fmt.Sprintf("Limit must be between 1 and %d", pagination.MaxLimit)))
+		return
+	}
+	filter.Limit = limit
+
+	wallets, err := h.walletService.ListWallets(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			h.writeInvalid(w, r, validation.Field("cursor", "cursor", "Invalid cursor"))
+			return
+		}
+		h.writeInternalError(w, r, "Failed to list wallets", err)
+		return
+	}
+
+	h.writeList(w, r, wallets, wallets.Wallets, wallets.NextCursor)
 }
 
 // @Summary Get operation status
-// @Description Retrieves the status of a specific operation for a wallet
+// @Description Retrieves the status of a specific operation for a wallet.
+// @Description With wait (or "Prefer: wait=<seconds>") the request blocks, up to 30s, until the operation is processed
+// @Description or failed; when the wait elapses first the current status is returned with 202.
 // @Tags operations
 // @Accept json
 // @Produce json
 // @Param walletId path string true "Wallet ID (UUIDv4)"
 // @Param operationId path string true "Operation ID (UUIDv4)"
+// @Param wait query string false "How long to wait for the final status, e.g. 5s"
+// @Param Prefer header string false "wait=<seconds>, used when the wait parameter is absent"
 // @Success 200 {object} models.OperationStatusResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /wallets/{walletId}/operations/{operationId} [get]
+// @Success 202 {object} models.OperationStatusResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/operations/{operationId} [get]
+// @Router /v2/wallets/{walletId}/operations/{operationId} [get]
 func (h *Wallet) getOperation(w http.ResponseWriter, r *http.Request) {
 	walletID := r.PathValue("walletId")
 	operationID := r.PathValue("operationId")
 
 	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
-		h.writeError(w, http.StatusBadRequest, "Invalid wallet ID format")
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
 		return
 	}
 	if err := h.validate.Var(operationID, "required,uuid4"); err != nil {
-		h.writeError(w, http.StatusBadRequest, "Invalid operation ID format")
+		h.writeInvalid(w, r, validation.Field("operationId", "uuid4", "Invalid operation ID format"))
+		return
+	}
+
+	wait, ok := parseWait(w, r)
+	if !ok {
+		h.writeInvalid(w, r, validation.Field("wait", "duration", "Invalid wait duration"))
 		return
 	}
 
 	ctx := r.Context()
-	operationStatus, err := h.walletService.GetOperation(ctx, walletID, operationID)
+	var operationStatus *models.OperationStatusResponse
+	var err error
+	done := true
+	if wait > 0 {
+		extendWriteDeadline(w, wait)
+		operationStatus, done, err = h.walletService.WaitForOperation(ctx, walletID, operationID, wait)
+	} else {
+		operationStatus, err = h.walletService.GetOperation(ctx, walletID, operationID)
+	}
 	if err != nil {
 		if errors.Is(err, postgresrepo.ErrOperationNotFound) {
-			h.writeError(w, http.StatusNotFound, "Operation not found")
+			h.writeProblem(w, r, http.StatusNotFound, problem.OperationNotFound, "Operation not found")
 			return
 		}
 		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
-			h.writeError(w, http.StatusNotFound, "Wallet not found")
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
 			return
 		}
-		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to get operation status: %v", err))
+		h.writeInternalError(w, r, "Failed to get operation status", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(operationStatus)
+	statusCode := http.StatusOK
+	if !done {
+		statusCode = http.StatusAccepted
+	}
+	h.writeJSON(w, r, statusCode, operationStatus)
 }
 
-// @Summary Create a wallet operation (deposit/withdraw)
-// @Description Creates a new deposit or withdraw operation for a wallet
+// @Summary Create a wallet operation (deposit/withdraw/transfer/hold/capture/release)
+// @Description Creates a new deposit, withdraw, transfer, hold, capture or release operation for a wallet.
+// @Description A transfer moves funds to destinationWalletId: both legs are posted or neither is.
+// @Description A hold reserves funds until expiresAt; the reserved funds are excluded from availableBalance.
+// @Description Capture debits part or all of the hold identified by holdId, release frees it
+// @Description (a release without amount frees the whole remaining hold).
+// @Description With executeAt a DEPOSIT, WITHDRAW or TRANSFER is stored as SCHEDULED and queued when it is due.
+// @Description A request repeated with the same Idempotency-Key returns the original operation without creating
+// @Description a new one (marked by the Idempotent-Replayed header); the same key with a different request is rejected with 422.
+// @Description With wait (or "Prefer: wait=<seconds>") the request blocks, up to 30s, until the worker processes or fails
+// @Description the operation and returns 200 with its final status; when the wait elapses first it returns 202 as without it.
 // @Tags operations
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Unique key of the request per wallet, at most 255 characters"
+// @Param wait query string false "How long to wait for the result, e.g. 5s"
+// @Param Prefer header string false "wait=<seconds>, used when the wait parameter is absent"
 // @Param operation body models.WalletOperationRequest true "Operation Request"
+// @Success 200 {object} models.OperationStatusResponse
 // @Success 202 {object} models.OperationCreateResponse
-// @Failure 400 {object} map[string]interface{}
-// @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /wallet [post]
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallet [post]
 func (h *Wallet) createOperation(w http.ResponseWriter, r *http.Request) {
 	var req models.WalletOperationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeError(w, http.StatusBadRequest, "Invalid JSON format")
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
 		return
 	}
 
-	if err := h.validate.Struct(req); err != nil {
-		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("Validation error: %v", err))
+	// Under v2 the operation is created on the wallet of the path, which require
+	// already authorized and rate limited
+	pathWalletID := r.PathValue("walletId")
+	if pathWalletID != "" {
+		if req.WalletID != "" && req.WalletID != pathWalletID {
+			h.writeInvalid(w, r, validation.Field("walletId", "eqfield", "walletId does not match the wallet of the path"))
+			return
+		}
+		req.WalletID = pathWalletID
+	}
+
+	if violations := validation.Operation(h.validate, req); violations != nil {
+		h.writeInvalid(w, r, violations)
 		return
 	}
 
-	if req.OperationType != models.OperationTypeDeposit && req.OperationType != models.OperationTypeWithdraw {
-		h.writeError(w, http.StatusBadRequest, "OperationType must be DEPOSIT or WITHDRAW")
+	if pathWalletID == "" && (!h.authorizeWallet(w, r, req.WalletID) || !h.limitWallet(w, r, req.WalletID)) {
 		return
 	}
 
-	if req.Amount <= 0 {
-		h.writeError(w, http.StatusBadRequest, "Amount must be positive")
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		if err := h.validate.Var(key, "max=255,printascii"); err != nil {
+			h.writeInvalid(w, r, validation.Field("Idempotency-Key", "printascii", "Invalid Idempotency-Key"))
+			return
+		}
+		if req.IdempotencyKey != "" && req.IdempotencyKey != key {
+			h.writeInvalid(w, r, validation.Field("idempotencyKey", "eqfield", "Idempotency-Key header does not match idempotencyKey"))
+			return
+		}
+		req.IdempotencyKey = key
+	}
+
+	wait, ok := parseWait(w, r)
+	if !ok {
+		h.writeInvalid(w, r, validation.Field("wait", "duration", "Invalid wait duration"))
 		return
 	}
 
 	ctx := r.Context()
-	operationID, err := h.walletService.CreateOperation(ctx, req)
+	operationID, replayed, err := h.walletService.CreateOperation(ctx, req)
 	if err != nil {
-		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
-			h.writeError(w, http.StatusNotFound, "Wallet not found")
+		operationProblem(err).Write(w, r)
+		return
+	}
+
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	location := "/wallets/" + req.WalletID + "/operations/" + operationID
+
+	// A scheduled operation is not processed before it is due, so it is not waited for
+	if wait > 0 && req.ExecuteAt == nil {
+		extendWriteDeadline(w, wait)
+		operationStatus, done, err := h.walletService.WaitForOperation(ctx, req.WalletID, operationID, wait)
+		if err != nil {
+			// The operation is queued either way, the client can still poll its status
+			fmt.Printf("Failed to wait for operation %s: %v\n", operationID, err)
+		} else if done {
+			h.writeCreated(w, r, http.StatusOK, location, operationStatus)
 			return
 		}
-		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create operation: %v", err))
-		return
 	}
 
 	response := models.OperationCreateResponse{
@@ -193,20 +541,235 @@ func (h *Wallet) createOperation(w http.ResponseWriter, r *http.Request) {
 		Status:      models.OperationStatusAccepted,
 		Message:     models.MessageOperationQueued,
 	}
+	if req.ExecuteAt != nil {
+		response.Message = models.MessageOperationScheduled
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(response)
+	h.writeCreated(w, r, http.StatusAccepted, location, response)
 }
 
-func (h *Wallet) writeError(w http.ResponseWriter, statusCode int, message string) {
-	errorResponse := map[string]interface{}{
-		"error":   http.StatusText(statusCode),
-		"message": message,
-		"code":    statusCode,
+// @Summary Create an operation of a wallet
+// @Description Creates an operation like POST /v1/wallet on the wallet of the path; walletId may be omitted from the body
+// @Description and must match the path when given. The response points the Location header at the operation.
+// @Tags operations
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param Idempotency-Key header string false "Unique key of the request per wallet, at most 255 characters"
+// @Param wait query string false "How long to wait for the result, e.g. 5s"
+// @Param Prefer header string false "wait=<seconds>, used when the wait parameter is absent"
+// @Param operation body models.WalletOperationRequest true "Operation Request"
+// @Success 200 {object} envelope.Envelope{data=models.OperationStatusResponse}
+// @Success 202 {object} envelope.Envelope{data=models.OperationCreateResponse}
+// @Header 200,202 {string} Location "Path of the operation"
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v2/wallets/{walletId}/operations [post]
+func (h *Wallet) createWalletOperation(w http.ResponseWriter, r *http.Request) {
+	h.createOperation(w, r)
+}
+
+// operationProblem maps an error of operation creation to a problem
+func operationProblem(err error) *problem.Problem {
+	if errors.Is(err, services.ErrOperationNotAllowed) {
+		return problem.New(http.StatusForbidden, problem.OperationNotAllowed, "Caller is not allowed to create operations of this type")
+	}
+	if errors.Is(err, services.ErrIdempotencyKeyReused) {
+		return problem.New(http.StatusUnprocessableEntity, problem.IdempotencyKeyReused, "Idempotency-Key was already used for a different request")
+	}
+	if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+		return problem.New(http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+	}
+	if errors.Is(err, services.ErrDestinationWalletNotFound) {
+		return problem.New(http.StatusNotFound, problem.DestinationWalletNotFound, "Destination wallet not found")
+	}
+	if errors.Is(err, services.ErrWalletFrozen) {
+		return problem.New(http.StatusUnprocessableEntity, problem.WalletFrozen, "Wallet is frozen")
+	}
+	if errors.Is(err, services.ErrWalletClosed) {
+		return problem.New(http.StatusUnprocessableEntity, problem.WalletClosed, "Wallet is closed")
+	}
+	if errors.Is(err, services.ErrDestinationWalletInactive) {
+		return problem.New(http.StatusUnprocessableEntity, problem.DestinationWalletInactive, "Destination wallet is not active")
+	}
+	if errors.Is(err, services.ErrCurrencyMismatch) {
+		return problem.New(http.StatusUnprocessableEntity, problem.CurrencyMismatch, "Currency does not match wallet currency")
+	}
+	if errors.Is(err, postgresrepo.ErrHoldNotFound) {
+		return problem.New(http.StatusNotFound, problem.HoldNotFound, "Hold not found")
 	}
+	if errors.Is(err, services.ErrHoldNotActive) {
+		return problem.New(http.StatusUnprocessableEntity, problem.HoldNotActive, "Hold is not active")
+	}
+	if errors.Is(err, services.ErrAmountExceedsHold) {
+		return problem.New(http.StatusUnprocessableEntity, problem.AmountExceedsHold, "Amount exceeds remaining held amount")
+	}
+	if errors.Is(err, services.ErrInvalidHoldExpiry) {
+		return problem.Validation(validation.Field("expiresAt", "future", "ExpiresAt must be in the future"))
+	}
+	if errors.Is(err, services.ErrInvalidExecuteAt) {
+		return problem.Validation(validation.Field("executeAt", "future", "ExecuteAt must be in the future"))
+	}
+	if errors.Is(err, services.ErrNotSchedulable) {
+		return problem.Validation(validation.Field("executeAt", "schedulable_only", "ExecuteAt is only allowed for DEPOSIT, WITHDRAW and TRANSFER"))
+	}
+	return problem.Internal("Failed to create operation", err)
+}
+
+// @Summary Cancel a scheduled operation
+// @Description Cancels an operation that is still SCHEDULED. Once the scheduler has queued it, it can no longer be cancelled.
+// @Tags operations
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param operationId path string true "Operation ID (UUIDv4)"
+// @Success 200 {object} models.OperationStatusResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/operations/{operationId}/cancel [post]
+// @Router /v2/wallets/{walletId}/operations/{operationId}/cancel [post]
+func (h *Wallet) cancelOperation(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+	operationID := r.PathValue("operationId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+	if err := h.validate.Var(operationID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("operationId", "uuid4", "Invalid operation ID format"))
+		return
+	}
+
+	ctx := r.Context()
+	operationStatus, err := h.walletService.CancelOperation(ctx, walletID, operationID)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrOperationNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.OperationNotFound, "Operation not found")
+			return
+		}
+		if errors.Is(err, services.ErrOperationNotCancellable) {
+			h.writeProblem(w, r, http.StatusConflict, problem.OperationNotCancellable, "Only scheduled operations can be cancelled")
+			return
+		}
+		h.writeInternalError(w, r, "Failed to cancel operation", err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, operationStatus)
+}
+
+// @Summary Reverse an operation
+// @Description Creates a full or partial REVERSAL (refund) of a processed DEPOSIT, WITHDRAW, TRANSFER or CAPTURE.
+// @Description Without amount everything that has not been reversed yet is reversed.
+// @Description A transfer is reversed from its source wallet: funds are returned from the destination wallet.
+// @Tags operations
+// @Accept json
+// @Produce json
+// @Param walletId path string true "Wallet ID (UUIDv4)"
+// @Param operationId path string true "ID of the operation to reverse (UUIDv4)"
+// @Param reversal body models.ReversalRequest false "Reversal Request"
+// @Success 202 {object} models.OperationCreateResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/wallets/{walletId}/operations/{operationId}/reversals [post]
+// @Router /v2/wallets/{walletId}/operations/{operationId}/reversals [post]
+func (h *Wallet) createReversal(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+	operationID := r.PathValue("operationId")
+
+	if err := h.validate.Var(walletID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("walletId", "uuid4", "Invalid wallet ID format"))
+		return
+	}
+	if err := h.validate.Var(operationID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("operationId", "uuid4", "Invalid operation ID format"))
+		return
+	}
+
+	var req models.ReversalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeInvalid(w, r, validation.Fields(err, ""))
+		return
+	}
+
+	ctx := r.Context()
+	reversalID, err := h.walletService.CreateReversal(ctx, walletID, operationID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrOperationNotAllowed) {
+			h.writeProblem(w, r, http.StatusForbidden, problem.OperationNotAllowed, "Caller is not allowed to reverse operations")
+			return
+		}
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+			return
+		}
+		if errors.Is(err, postgresrepo.ErrOperationNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.OperationNotFound, "Operation not found")
+			return
+		}
+		if errors.Is(err, services.ErrWalletFrozen) {
+			h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.WalletFrozen, "Wallet is frozen")
+			return
+		}
+		if errors.Is(err, services.ErrWalletClosed) {
+			h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.WalletClosed, "Wallet is closed")
+			return
+		}
+		if errors.Is(err, services.ErrOperationNotReversible) {
+			h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.OperationNotReversible, "Operation cannot be reversed")
+			return
+		}
+		if errors.Is(err, services.ErrOperationNotProcessed) {
+			h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.OperationNotReversible, "Only processed operations can be reversed")
+			return
+		}
+		if errors.Is(err, services.ErrAmountExceedsReversible) {
+			h.writeProblem(w, r, http.StatusUnprocessableEntity, problem.AmountExceedsReversible, "Amount exceeds reversible amount")
+			return
+		}
+		h.writeInternalError(w, r, "Failed to create reversal", err)
+		return
+	}
+
+	response := models.OperationCreateResponse{
+		OperationID: reversalID,
+		Status:      models.OperationStatusAccepted,
+		Message:     models.MessageOperationQueued,
+	}
+
+	h.writeCreated(w, r, http.StatusAccepted, "/wallets/"+walletID+"/operations/"+reversalID, response)
+}
+
+// writeProblem writes an RFC 7807 problem with the given status and code
+func (h *Wallet) writeProblem(w http.ResponseWriter, r *http.Request, status int, code problem.Code, detail string) {
+	problem.New(status, code, detail).Write(w, r)
+}
+
+// writeInvalid writes the VALIDATION_FAILED problem of the violations of a request
+func (h *Wallet) writeInvalid(w http.ResponseWriter, r *http.Request, violations validation.Errors) {
+	problem.Validation(violations).Write(w, r)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(errorResponse)
+// writeInternalError logs an unexpected error and writes a problem carrying only
+// the ID it was logged with
+func (h *Wallet) writeInternalError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	problem.Internal(message, err).Write(w, r)
 }
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"wallet-service/internal/auth"
+	"wallet-service/internal/config"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/repositories/redisrepo"
+	"wallet-service/internal/services"
+)
+
+const (
+	testWalletID    = "6f1c0b1e-4a5d-4a3b-9a4e-2f1d6c7b8a90"
+	testOperationID = "1c9d0b7a-51f8-4f0e-9d5b-6c8a2e3f4b10"
+)
+
+// newTestMux serves the routes with a service whose database is unreachable, so
+// requests rejected before the service reads a wallet are told from the others
+func newTestMux(t *testing.T, cfg *config.Config, redisRepo *redisrepo.WalletRepository) *http.ServeMux {
+	t.Helper()
+
+	db, err := sqlx.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mux := http.NewServeMux()
+	NewWallet(mux, services.NewWalletService(cfg, postgresrepo.NewWalletRepository(db), redisRepo, nil, nil), cfg.API)
+	return mux
+}
+
+// serve sends a request of the principal and returns the response with its problem,
+// if any
+func serve(mux *http.ServeMux, principal *auth.Principal, method, path, body string) (*httptest.ResponseRecorder, problem.Problem) {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r = r.WithContext(auth.WithPrincipal(r.Context(), principal))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	var p problem.Problem
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "application/problem+json") {
+		json.NewDecoder(w.Body).Decode(&p)
+	}
+	return w, p
+}
+
+func TestSubjectOperationTypes(t *testing.T) {
+	mux := newTestMux(t, &config.Config{}, nil)
+	subject := (&auth.Claims{Subject: "user-1", WalletIDs: []string{testWalletID}}).Principal("admin")
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"v1 deposit", "/api/v1/wallet", `{"walletId":"` + testWalletID + `","operationType":"DEPOSIT","amount":100}`},
+		{"v2 deposit", "/api/v2/wallets/" + testWalletID + "/operations", `{"operationType":"DEPOSIT","amount":100}`},
+		{"v1 hold", "/api/v1/wallet", `{"walletId":"` + testWalletID + `","operationType":"HOLD","amount":100}`},
+		{"v1 reversal", "/api/v1/wallets/" + testWalletID + "/operations/" + testOperationID + "/reversals", `{}`},
+		{"v2 reversal", "/api/v2/wallets/" + testWalletID + "/operations/" + testOperationID + "/reversals", `{}`},
+		{"deposit standing order", "/api/v1/wallets/" + testWalletID + "/standing-orders", `{"operationType":"DEPOSIT","amount":100,"schedule":"@daily"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, p := serve(mux, subject, http.MethodPost, tt.path, tt.body)
+			if w.Code != http.StatusForbidden || p.Code != problem.OperationNotAllowed {
+				t.Errorf("got %d %s, want 403 %s", w.Code, p.Code, problem.OperationNotAllowed)
+			}
+		})
+	}
+}
+
+func TestSubjectBatchDeposit(t *testing.T) {
+	mux := newTestMux(t, &config.Config{}, nil)
+	subject := (&auth.Claims{Subject: "user-1", WalletIDs: []string{testWalletID}}).Principal("admin")
+
+	body := `{"atomic":true,"operations":[{"walletId":"` + testWalletID + `","operationType":"DEPOSIT","amount":100}]}`
+	w, _ := serve(mux, subject, http.MethodPost, "/api/v1/operations:batch", body)
+
+	var response struct {
+		Results []struct {
+			Code      int    `json:"code"`
+			ErrorCode string `json:"errorCode"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Results) != 1 || response.Results[0].Code != http.StatusForbidden ||
+		response.Results[0].ErrorCode != string(problem.OperationNotAllowed) {
+		t.Errorf("results = %+v, want the deposit rejected with 403 %s", response.Results, problem.OperationNotAllowed)
+	}
+}
+
+func TestAdminOperationTypes(t *testing.T) {
+	mux := newTestMux(t, &config.Config{}, nil)
+	admin := (&auth.Claims{Subject: "user-2", Roles: []string{"admin"}}).Principal("admin")
+
+	for _, path := range []string{
+		"/api/v1/wallet",
+		"/api/v1/wallets/" + testWalletID + "/operations/" + testOperationID + "/reversals",
+	} {
+		body := `{"walletId":"` + testWalletID + `","operationType":"DEPOSIT","amount":100}`
+		if strings.HasSuffix(path, "/reversals") {
+			body = `{}`
+		}
+
+		// The admin gets past the check, to the wallet lookup that fails
+		w, p := serve(mux, admin, http.MethodPost, path, body)
+		if w.Code == http.StatusForbidden || p.Code != problem.InternalError {
+			t.Errorf("%s: got %d %s, want the check bypassed", path, w.Code, p.Code)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"wallet-service/internal/models"
+	"wallet-service/internal/pagination"
+	"wallet-service/internal/problem"
+	"wallet-service/internal/repositories/postgresrepo"
+	"wallet-service/internal/services"
+	"wallet-service/internal/validation"
+)
+
+// @Summary Create a webhook
+// @Description Subscribes url to events of walletId, or of every wallet when walletId is omitted.
+// @Description Event types: operation.processed, operation.failed and balance.updated. Deliveries are queued in the
+// @Description transaction of the worker that commits the change and POSTed with a models.WebhookPayload body and the headers
+// @Description Webhook-Id, Webhook-Timestamp and Webhook-Signature: "v1=" + hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the secret.
+// @Description A delivery that does not get a 2xx response is retried with exponential backoff and then dead-lettered.
+// @Description The secret is generated when omitted and only returned in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body models.WebhookRequest true "Webhook Request"
+// @Success 201 {object} models.WebhookResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/webhooks [post]
+// @Router /v2/webhooks [post]
+func (h *Wallet) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var req models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeProblem(w, r, http.StatusBadRequest, problem.MalformedRequest, "Invalid JSON format")
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeInvalid(w, r, validation.Fields(err, ""))
+		return
+	}
+
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		h.writeInvalid(w, r, validation.Field("url", "url", "URL must be an absolute http or https URL"))
+		return
+	}
+
+	webhook, err := h.walletService.CreateWebhook(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, postgresrepo.ErrWalletNotFound) {
+			h.writeProblem(w, r, http.StatusNotFound, problem.WalletNotFound, "Wallet not found")
+			return
+		}
+		h.writeInternalError(w, r, "Failed to create webhook", err)
+		return
+	}
+
+	h.writeCreated(w, r, http.StatusCreated, "/webhooks/"+webhook.WebhookID, webhook)
+}
+
+// @Summary List webhooks
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.WebhookListResponse
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/webhooks [get]
+// @Router /v2/webhooks [get]
+func (h *Wallet) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.walletService.ListWebhooks(r.Context())
+	if err != nil {
+		h.writeInternalError(w, r, "Failed to list webhooks", err)
+		return
+	}
+
+	h.writeList(w, r, webhooks, webhooks.Webhooks, "")
+}
+
+// @Summary Get a webhook
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhookId path string true "Webhook ID (UUIDv4)"
+// @Success 200 {object} models.WebhookResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/webhooks/{webhookId} [get]
+// @Router /v2/webhooks/{webhookId} [get]
+func (h *Wallet) getWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.walletService.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		h.writeWebhookError(w, r, err, "Failed to get webhook")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, webhook)
+}
+
+// @Summary Delete a webhook
+// @Description Stops the deliveries of a webhook; its pending and dead deliveries are dropped.
+// @Tags webhooks
+// @Param webhookId path string true "Webhook ID (UUIDv4)"
+// @Success 204
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/webhooks/{webhookId} [delete]
+// @Router /v2/webhooks/{webhookId} [delete]
+func (h *Wallet) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.walletService.DeleteWebhook(r.Context(), webhookID); err != nil {
+		h.writeWebhookError(w, r, err, "Failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary List dead webhook deliveries
+// @Description Lists the deliveries that used all their attempts, newest first, optionally of one webhook.
+// @Description Pass nextCursor of a page as cursor to get the next one.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param webhookId query string false "Webhook ID (UUIDv4)"
+// @Param limit query int false "Page size, 1-200, defaults to 50"
+// @Param cursor query string false "Cursor of the page"
+// @Success 200 {object} models.WebhookDeliveryListResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/webhooks/dead-letters [get]
+// @Router /v2/admin/webhooks/dead-letters [get]
+func (h *Wallet) listDeadLetters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := models.WebhookDeliveryFilter{WebhookID: query.Get("webhookId")}
+	if err := h.validate.Var(filter.WebhookID, "omitempty,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("webhookId", "uuid4", "Invalid webhook ID format"))
+		return
+	}
+
+	limit, ok := parseLimit(query.Get("limit"))
+	if !ok {
+		h.writeInvalid(w, r, validation.Field("limit", "range", fmt.Sprintf("Limit must be between 1 and %d", pagination.MaxLimit)))
+		return
+	}
+	filter.Limit = limit
+
+	deliveries, err := h.walletService.ListDeadLetters(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			h.writeInvalid(w, r, validation.Field("cursor", "cursor", "Invalid cursor"))
+			return
+		}
+		h.writeInternalError(w, r, "Failed to list dead webhook deliveries", err)
+		return
+	}
+
+	h.writeList(w, r, deliveries, deliveries.Deliveries, deliveries.NextCursor)
+}
+
+// @Summary Replay a dead webhook delivery
+// @Description Queues a dead delivery again with a fresh set of attempts.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} models.WebhookReplayResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/webhooks/dead-letters/{deliveryId}/replay [post]
+// @Router /v2/admin/webhooks/dead-letters/{deliveryId}/replay [post]
+func (h *Wallet) replayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := strconv.ParseInt(r.PathValue("deliveryId"), 10, 64)
+	if err != nil || deliveryID <= 0 {
+		h.writeInvalid(w, r, validation.Field("deliveryId", "uuid4", "Invalid delivery ID format"))
+		return
+	}
+
+	replay, err := h.walletService.ReplayDeadLetter(r.Context(), deliveryID)
+	if err != nil {
+		h.writeWebhookError(w, r, err, "Failed to replay webhook delivery")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusAccepted, replay)
+}
+
+// @Summary Replay the dead deliveries of a webhook
+// @Description Queues all dead deliveries of a webhook again with a fresh set of attempts.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param webhookId path string true "Webhook ID (UUIDv4)"
+// @Success 202 {object} models.WebhookReplayResponse
+// @Failure 400 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v1/admin/webhooks/{webhookId}/replay [post]
+// @Router /v2/admin/webhooks/{webhookId}/replay [post]
+func (h *Wallet) replayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := h.webhookPath(w, r)
+	if !ok {
+		return
+	}
+
+	replay, err := h.walletService.ReplayDeadLetters(r.Context(), webhookID)
+	if err != nil {
+		h.writeWebhookError(w, r, err, "Failed to replay webhook deliveries")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusAccepted, replay)
+}
+
+// webhookPath validates the webhook ID of the path
+func (h *Wallet) webhookPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	webhookID := r.PathValue("webhookId")
+
+	if err := h.validate.Var(webhookID, "required,uuid4"); err != nil {
+		h.writeInvalid(w, r, validation.Field("webhookId", "uuid4", "Invalid webhook ID format"))
+		return "", false
+	}
+
+	return webhookID, true
+}
+
+// writeWebhookError maps the errors of an existing webhook or delivery to responses
+func (h *Wallet) writeWebhookError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, postgresrepo.ErrWebhookNotFound) {
+		h.writeProblem(w, r, http.StatusNotFound, problem.WebhookNotFound, "Webhook not found")
+		return
+	}
+	if errors.Is(err, postgresrepo.ErrDeliveryNotFound) {
+		h.writeProblem(w, r, http.StatusNotFound, problem.WebhookDeliveryNotFound, "Webhook delivery not found")
+		return
+	}
+	if errors.Is(err, services.ErrDeliveryNotDead) {
+		h.writeProblem(w, r, http.StatusConflict, problem.DeliveryNotDead, "Only dead deliveries can be replayed")
+		return
+	}
+	h.writeInternalError(w, r, message, err)
+}
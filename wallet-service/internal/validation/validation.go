@@ -0,0 +1,216 @@
+// Package validation checks API requests the same way whichever transport
+// received them. Every check returns the violations of a request, nil when it
+// is valid: all struct tag violations, or else the first of the other checks.
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"wallet-service/internal/currency"
+	"wallet-service/internal/models"
+
+	"github.com/go-playground/validator"
+)
+
+// FieldError is a field of a request breaking a rule
+type FieldError struct {
+	Field   string `json:"field"`           // JSON name, with the path of nested fields
+	Rule    string `json:"rule"`            // validate tag or name of the check
+	Param   string `json:"param,omitempty"` // parameter of the rule, e.g. the maximum of max
+	Message string `json:"message"`
+}
+
+// Errors are the violations of a request
+type Errors []FieldError
+
+// Error returns the messages of the violations
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// New returns a validator reporting fields by their JSON names
+func New() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	return validate
+}
+
+// Field returns the violation of a single field, e.g. a path or query parameter
+func Field(field, rule, message string) Errors {
+	return Errors{{Field: field, Rule: rule, Message: message}}
+}
+
+// Fields converts the error of validate.Struct or validate.Var to violations.
+// Var does not know the name of its value, so it is given as field.
+func Fields(err error, field string) Errors {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return Field(field, "invalid", err.Error())
+	}
+
+	violations := make(Errors, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		name := field
+		// The namespace starts with the struct name, the fields follow it
+		if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+			name = path
+		}
+		violations = append(violations, FieldError{
+			Field:   name,
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message(fe, name),
+		})
+	}
+
+	return violations
+}
+
+// message describes a violation of a validate tag
+func message(fe validator.FieldError, field string) string {
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "uuid4":
+		return field + " must be a UUIDv4"
+	case "oneof":
+		return field + " must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "min":
+		return fmt.Sprintf("%s must have at least %s%s", field, fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("%s must have at most %s%s", field, fe.Param(), unit)
+	case "len":
+		return fmt.Sprintf("%s must have exactly %s%s", field, fe.Param(), unit)
+	case "url":
+		return field + " must be a URL"
+	case "printascii":
+		return field + " must be printable ASCII"
+	}
+
+	return fmt.Sprintf("%s fails the %s rule", field, fe.Tag())
+}
+
+// Wallet checks a wallet creation request
+func Wallet(validate *validator.Validate, req models.WalletCreateRequest) Errors {
+	if err := validate.Struct(req); err != nil {
+		return Fields(err, "")
+	}
+
+	if req.Currency != "" {
+		if _, err := currency.Lookup(req.Currency); err != nil {
+			return Field("currency", "currency", "Unsupported currency")
+		}
+	}
+
+	if req.ExternalRef != "" && req.OwnerID == "" {
+		return Field("externalRef", "required_with_owner", "ExternalRef requires OwnerID")
+	}
+	for key := range req.Labels {
+		if strings.Contains(key, "=") {
+			return Field("labels", "label_key", "Label keys must not contain '='")
+		}
+	}
+
+	return nil
+}
+
+// Maximum size of the compacted metadata of an operation
+const maxMetadataSize = 4096
+
+// Operation checks an operation request on its own
+func Operation(validate *validator.Validate, req models.WalletOperationRequest) Errors {
+	if err := validate.Struct(req); err != nil {
+		return Fields(err, "")
+	}
+
+	if violations := operationFields(req); violations != nil {
+		return violations
+	}
+
+	if req.Currency != "" {
+		if _, err := currency.Lookup(req.Currency); err != nil {
+			return Field("currency", "currency", "Unsupported currency")
+		}
+	}
+
+	if len(req.Metadata) > 0 {
+		var metadata bytes.Buffer
+		if err := json.Compact(&metadata, req.Metadata); err != nil || metadata.Bytes()[0] != '{' {
+			return Field("metadata", "object", "Metadata must be a JSON object")
+		}
+		if metadata.Len() > maxMetadataSize {
+			return Field("metadata", "max", fmt.Sprintf("Metadata must be at most %d bytes", maxMetadataSize))
+		}
+	}
+
+	return nil
+}
+
+// operationFields checks the fields whose presence depends on the
+// operation type and returns the first violation
+func operationFields(req models.WalletOperationRequest) Errors {
+	if req.DestinationWalletID != "" && req.OperationType != models.OperationTypeTransfer {
+		return Field("destinationWalletId", "transfer_only", "DestinationWalletID is only allowed for TRANSFER")
+	}
+	if req.HoldID != "" && req.OperationType != models.OperationTypeCapture && req.OperationType != models.OperationTypeRelease {
+		return Field("holdId", "capture_release_only", "HoldID is only allowed for CAPTURE and RELEASE")
+	}
+	if req.ExpiresAt != nil && req.OperationType != models.OperationTypeHold {
+		return Field("expiresAt", "hold_only", "ExpiresAt is only allowed for HOLD")
+	}
+	if req.ExecuteAt != nil && req.OperationType != models.OperationTypeDeposit &&
+		req.OperationType != models.OperationTypeWithdraw && req.OperationType != models.OperationTypeTransfer {
+		return Field("executeAt", "schedulable_only", "ExecuteAt is only allowed for DEPOSIT, WITHDRAW and TRANSFER")
+	}
+
+	switch req.OperationType {
+	case models.OperationTypeDeposit, models.OperationTypeWithdraw, models.OperationTypeHold:
+	case models.OperationTypeTransfer:
+		if req.DestinationWalletID == "" {
+			return Field("destinationWalletId", "required", "DestinationWalletID is required for TRANSFER")
+		}
+		if req.DestinationWalletID == req.WalletID {
+			return Field("destinationWalletId", "nefield", "DestinationWalletID must differ from WalletID")
+		}
+	case models.OperationTypeCapture, models.OperationTypeRelease:
+		if req.HoldID == "" {
+			return Field("holdId", "required", "HoldID is required for CAPTURE and RELEASE")
+		}
+	default:
+		return Field("operationType", "oneof", "OperationType must be DEPOSIT, WITHDRAW, TRANSFER, HOLD, CAPTURE or RELEASE")
+	}
+
+	// RELEASE without amount releases the whole remaining hold
+	if req.Amount <= 0 && !(req.OperationType == models.OperationTypeRelease && req.Amount == 0) {
+		return Field("amount", "gt", "Amount must be positive")
+	}
+
+	return nil
+}
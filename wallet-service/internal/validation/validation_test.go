@@ -0,0 +1,107 @@
+package validation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"wallet-service/internal/models"
+)
+
+const (
+	walletID      = "6f1c0b1e-4a5d-4a3b-9a4e-2f1d6c7b8a90"
+	destinationID = "0b8c2d3e-1f2a-4b5c-8d6e-7f8091a2b3c4"
+)
+
+func TestOperation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.WalletOperationRequest
+		want string
+	}{
+		{
+			name: "valid deposit",
+			req:  models.WalletOperationRequest{WalletID: walletID, OperationType: models.OperationTypeDeposit, Amount: 100},
+		},
+		{
+			name: "transfer without destination",
+			req:  models.WalletOperationRequest{WalletID: walletID, OperationType: models.OperationTypeTransfer, Amount: 100},
+			want: "DestinationWalletID is required for TRANSFER",
+		},
+		{
+			name: "destination on a deposit",
+			req:  models.WalletOperationRequest{WalletID: walletID, DestinationWalletID: destinationID, OperationType: models.OperationTypeDeposit, Amount: 100},
+			want: "DestinationWalletID is only allowed for TRANSFER",
+		},
+		{
+			name: "release without amount",
+			req:  models.WalletOperationRequest{WalletID: walletID, HoldID: destinationID, OperationType: models.OperationTypeRelease},
+		},
+		{
+			name: "unsupported currency",
+			req:  models.WalletOperationRequest{WalletID: walletID, OperationType: models.OperationTypeDeposit, Amount: 100, Currency: "XXX"},
+			want: "Unsupported currency",
+		},
+		{
+			name: "metadata is not an object",
+			req:  models.WalletOperationRequest{WalletID: walletID, OperationType: models.OperationTypeDeposit, Amount: 100, Metadata: json.RawMessage(`[1]`)},
+			want: "Metadata must be a JSON object",
+		},
+	}
+
+	validate := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Operation(validate, tt.req)
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("got %v, want no violations", got)
+				}
+				return
+			}
+			if got.Error() != tt.want {
+				t.Fatalf("got %q, want %q", got.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWallet(t *testing.T) {
+	validate := New()
+
+	if got := Wallet(validate, models.WalletCreateRequest{Currency: "EUR", OwnerID: "owner", ExternalRef: "ref"}); got != nil {
+		t.Fatalf("valid wallet: got %v", got)
+	}
+	if got := Wallet(validate, models.WalletCreateRequest{ExternalRef: "ref"}); got.Error() != "ExternalRef requires OwnerID" || got[0].Field != "externalRef" {
+		t.Fatalf("external ref without owner: got %v", got)
+	}
+	if got := Wallet(validate, models.WalletCreateRequest{Labels: map[string]string{"a=b": "c"}}); !strings.Contains(got.Error(), "'='") {
+		t.Fatalf("label key with '=': got %v", got)
+	}
+}
+
+func TestFields(t *testing.T) {
+	validate := New()
+
+	got := Operation(validate, models.WalletOperationRequest{WalletID: "not-a-uuid", OperationType: "BURN", Currency: "EURO"})
+	want := Errors{
+		{Field: "walletId", Rule: "uuid4", Message: "walletId must be a UUIDv4"},
+		{Field: "operationType", Rule: "oneof", Param: "DEPOSIT WITHDRAW TRANSFER HOLD CAPTURE RELEASE",
+			Message: "operationType must be one of DEPOSIT, WITHDRAW, TRANSFER, HOLD, CAPTURE, RELEASE"},
+		{Field: "currency", Rule: "len", Param: "3", Message: "currency must have exactly 3 characters"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("violation %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Var does not know the name of the value
+	err := validate.Var("x", "required,uuid4")
+	if got := Fields(err, "operationId"); len(got) != 1 || got[0].Field != "operationId" || got[0].Rule != "uuid4" {
+		t.Errorf("Fields(Var) = %v", got)
+	}
+}
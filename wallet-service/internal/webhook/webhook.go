@@ -0,0 +1,100 @@
+// Package webhook signs and sends webhook deliveries.
+//
+// Every delivery is a POST of a JSON body with the headers
+//
+//	Webhook-Id:        ID of the delivery, the same on every attempt
+//	Webhook-Timestamp: Unix time of the attempt in seconds
+//	Webhook-Signature: v1=<hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the secret>
+//
+// Receivers recompute the signature and should reject timestamps too far from now.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	signatureVersion = "v1"
+	// maxResponseSize is how much of a response body is read before the connection is reused
+	maxResponseSize = 64 << 10
+)
+
+// Sign returns the Webhook-Signature of body sent at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret generates a random signing secret
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Backoff returns the delay before the next attempt after attempts failed ones:
+// base doubled after every attempt, capped at ceiling
+func Backoff(attempts int, base, ceiling time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < ceiling; i++ {
+		delay *= 2
+	}
+
+	return min(delay, ceiling)
+}
+
+type Client struct {
+	http *http.Client
+}
+
+func NewClient(timeout time.Duration) *Client {
+	return &Client{
+		http: &http.Client{Timeout: timeout},
+	}
+}
+
+// Send posts a signed delivery and returns the status code of the response.
+// Any status other than 2xx is an error; the code is 0 when there was no response.
+func (c *Client) Send(ctx context.Context, url, secret, deliveryID string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "legendary-wallet-webhooks")
+	req.Header.Set("Webhook-Id", deliveryID)
+	req.Header.Set("Webhook-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("Webhook-Signature", Sign(secret, now, body))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook receiver responded with %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestClient_Send(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"type":"balance.updated"}`)
+
+	var received http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+
+		got, _ := io.ReadAll(r.Body)
+		seconds, err := strconv.ParseInt(r.Header.Get("Webhook-Timestamp"), 10, 64)
+		if err != nil {
+			t.Errorf("timestamp: %v", err)
+		}
+		// The receiver verifies the signature the way a subscriber would
+		if want := Sign(secret, time.Unix(seconds, 0), got); r.Header.Get("Webhook-Signature") != want {
+			t.Errorf("signature: got %q, want %q", r.Header.Get("Webhook-Signature"), want)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	status, err := NewClient(time.Second).Send(context.Background(), receiver.URL, secret, "42", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != http.StatusNoContent {
+		t.Fatalf("status: got %d, want %d", status, http.StatusNoContent)
+	}
+	if received.Get("Webhook-Id") != "42" || received.Get("Content-Type") != "application/json" {
+		t.Fatalf("headers: got %v", received)
+	}
+}
+
+func TestClient_SendFailure(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	status, err := NewClient(time.Second).Send(context.Background(), receiver.URL, "secret", "1", []byte(`{}`))
+	if err == nil {
+		t.Fatal("expected an error for a 503")
+	}
+	if status != http.StatusServiceUnavailable {
+		t.Fatalf("status: got %d, want %d", status, http.StatusServiceUnavailable)
+	}
+
+	// A closed receiver yields no status code
+	receiver.Close()
+	if status, err := NewClient(time.Second).Send(context.Background(), receiver.URL, "secret", "1", []byte(`{}`)); err == nil || status != 0 {
+		t.Fatalf("closed receiver: got %d, %v", status, err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"a":1}`)
+
+	signature := Sign("secret", timestamp, body)
+	if signature[:3] != "v1=" || len(signature) != 3+64 {
+		t.Fatalf("signature format: got %q", signature)
+	}
+	if Sign("secret", timestamp, body) != signature {
+		t.Fatal("signature is not deterministic")
+	}
+	if Sign("other", timestamp, body) == signature || Sign("secret", timestamp.Add(time.Second), body) == signature {
+		t.Fatal("signature does not depend on the secret and the timestamp")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{10, time.Minute},
+		{100, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts, time.Second, time.Minute); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}